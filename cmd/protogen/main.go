@@ -0,0 +1,141 @@
+// Command protogen - generating Go code of gRPC API from api/proto without protoc.
+// Proto files are compiled by protocompile and passed to protoc plugins, which are Go packages
+// run with "go run", so their versions are the ones pinned in go.mod. No compiler version is
+// reported to plugins, so headers of generated files say "protoc (unknown)".
+//
+//	protogen -I api/proto -out pkg/api -plugin google.golang.org/protobuf/cmd/protoc-gen-go=paths=source_relative file.proto
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// plugins - values of repeated -plugin flag.
+type plugins []string
+
+func (p *plugins) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *plugins) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
+
+func main() {
+	var (
+		include string
+		out     string
+		run     plugins
+	)
+	flag.StringVar(&include, "I", ".", "directory proto files and their imports are resolved in")
+	flag.StringVar(&out, "out", ".", "directory generated files are written to")
+	flag.Var(&run, "plugin", "Go package of protoc plugin with optional parameter: package[=parameter], repeatable")
+	flag.Parse()
+
+	if flag.NArg() == 0 || len(run) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	files, err := compile(include, flag.Args())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, plugin := range run {
+		pkg, parameter, _ := strings.Cut(plugin, "=")
+		request := &pluginpb.CodeGeneratorRequest{
+			FileToGenerate: flag.Args(),
+			Parameter:      proto.String(parameter),
+			ProtoFile:      files,
+		}
+		if err = generate(pkg, request, out); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// compile - descriptors of proto files and of everything they import, imports go first as protoc passes them.
+func compile(include string, names []string) ([]*descriptorpb.FileDescriptorProto, error) {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			ImportPaths: []string{include},
+		}),
+		// comments of proto files are copied into generated code
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+	compiled, err := compiler.Compile(context.Background(), names...)
+	if err != nil {
+		return nil, fmt.Errorf("compile: %w", err)
+	}
+
+	var files []*descriptorpb.FileDescriptorProto
+	seen := make(map[string]bool)
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+		files = append(files, protodesc.ToFileDescriptorProto(fd))
+	}
+	for _, fd := range compiled {
+		add(fd)
+	}
+	return files, nil
+}
+
+// generate - running plugin of Go package pkg on request and writing files it produces into out.
+func generate(pkg string, request *pluginpb.CodeGeneratorRequest, out string) error {
+	in, err := proto.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("go", "run", pkg)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stderr = os.Stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("plugin %s: %w", pkg, err)
+	}
+
+	var response pluginpb.CodeGeneratorResponse
+	if err = proto.Unmarshal(output, &response); err != nil {
+		return fmt.Errorf("plugin %s: %w", pkg, err)
+	}
+	if response.Error != nil {
+		return fmt.Errorf("plugin %s: %w", pkg, errors.New(response.GetError()))
+	}
+
+	for _, file := range response.File {
+		path := filepath.Join(out, file.GetName())
+		if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err = os.WriteFile(path, []byte(file.GetContent()), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build tools
+
+package main
+
+// plugins run by protogen, imported so go.mod pins their versions
+import (
+	_ "google.golang.org/grpc/cmd/protoc-gen-go-grpc"
+	_ "google.golang.org/protobuf/cmd/protoc-gen-go"
+)
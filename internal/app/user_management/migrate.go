@@ -0,0 +1,108 @@
+package user_management
+
+import (
+	"context"
+	"fmt"
+	"github.com/sonikq/gravitum_test_task/internal/config"
+	"github.com/sonikq/gravitum_test_task/internal/repository/postgres"
+	"github.com/sonikq/gravitum_test_task/pkg/logger"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+// runMigrate - running migrate subcommand against the configured DB.
+// Migrations run without CtxTimeOut since waiting for the advisory lock may take a while.
+func runMigrate(conf config.Config, lg *logger.Logger, args []string) {
+	if len(args) != 1 {
+		log.Fatal(usage)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
+	defer stop()
+
+	repo, err := postgres.NewStorage(ctx, conf.MigrationDSN(), "", 1, false, false)
+	if err != nil {
+		lg.Fatal().Err(err).Msg("failed to connect to database")
+	}
+	defer repo.Close()
+
+	migrator, err := repo.Migrator()
+	if err != nil {
+		lg.Fatal().Err(err).Msg("failed to initialize migrator")
+	}
+	defer migrator.Close()
+
+	switch args[0] {
+	case "up":
+		results, err := migrator.Up(ctx)
+		for _, result := range results {
+			fmt.Println(result)
+		}
+		if err != nil {
+			lg.Fatal().Err(err).Msg("failed to apply migrations")
+		}
+		if len(results) == 0 {
+			fmt.Println("no migrations to apply")
+		}
+
+	case "down":
+		result, err := migrator.Down(ctx)
+		if err != nil {
+			lg.Fatal().Err(err).Msg("failed to roll back migration")
+		}
+		fmt.Println(result)
+
+	case "redo":
+		results, err := migrator.Redo(ctx)
+		for _, result := range results {
+			fmt.Println(result)
+		}
+		if err != nil {
+			lg.Fatal().Err(err).Msg("failed to redo migration")
+		}
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			lg.Fatal().Err(err).Msg("failed to get migrations status")
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tSTATE\tAPPLIED AT\tSOURCE")
+		for _, status := range statuses {
+			appliedAt := "-"
+			if !status.AppliedAt.IsZero() {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Source.Version, status.State,
+				appliedAt, filepath.Base(status.Source.Path))
+		}
+		_ = w.Flush()
+
+	case "version":
+		version, err := migrator.Version(ctx)
+		if err != nil {
+			lg.Fatal().Err(err).Msg("failed to get schema version")
+		}
+		fmt.Println(version)
+
+	default:
+		log.Fatal(usage)
+	}
+}
+
+// runCreateMigration - creating blank migration in the source tree, it is embedded on the next build.
+func runCreateMigration(lg *logger.Logger, args []string) {
+	if len(args) != 1 {
+		log.Fatal(usage)
+	}
+
+	if err := postgres.CreateMigration(postgres.MigrationSourceDir, args[0]); err != nil {
+		lg.Fatal().Err(err).Msg("failed to create migration")
+	}
+}
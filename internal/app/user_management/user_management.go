@@ -3,64 +3,314 @@ package user_management
 import (
 	"context"
 	"errors"
+	"flag"
+	"fmt"
 	"github.com/sonikq/gravitum_test_task/internal/config"
 	"github.com/sonikq/gravitum_test_task/internal/handler"
+	grpchandler "github.com/sonikq/gravitum_test_task/internal/handler/grpc/user_management"
 	"github.com/sonikq/gravitum_test_task/internal/repository"
+	grpcserv "github.com/sonikq/gravitum_test_task/internal/server/grpc"
 	httpserv "github.com/sonikq/gravitum_test_task/internal/server/http"
+	"github.com/sonikq/gravitum_test_task/internal/server/middleware"
 	"github.com/sonikq/gravitum_test_task/internal/service"
+	pb "github.com/sonikq/gravitum_test_task/pkg/api/user_management/v1"
+	"github.com/sonikq/gravitum_test_task/pkg/blob"
+	"github.com/sonikq/gravitum_test_task/pkg/emailpolicy"
+	"github.com/sonikq/gravitum_test_task/pkg/health"
+	"github.com/sonikq/gravitum_test_task/pkg/lifecycle"
 	"github.com/sonikq/gravitum_test_task/pkg/logger"
+	"github.com/sonikq/gravitum_test_task/pkg/tenant"
+	"github.com/sonikq/gravitum_test_task/pkg/validator"
+	"google.golang.org/grpc"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
+const (
+	componentRepository = "repository"
+	componentHTTP       = "http"
+	componentGRPC       = "grpc"
+)
+
+const usage = `usage: user_management [flags] <command> [args]
+
+commands:
+  serve                  run http server (default)
+  migrate up             apply all pending migrations
+  migrate down           roll back the last applied migration
+  migrate redo           roll back the last migration and apply it again
+  migrate status         print state of every migration
+  migrate version        print current schema version
+  create <name>          create a new blank sql migration
+  config print           print effective config with secrets redacted`
+
 func Run() {
 	conf, err := config.Load()
 	if err != nil {
-		log.Fatal("failed to initialize config")
+		log.Fatalf("failed to initialize config:\n%v", err)
+	}
+
+	lg, err := logger.NewLogger(conf.ServiceName, loggerOption(conf))
+	if err != nil {
+		log.Fatalf("failed to initialize logger: %v", err)
 	}
+	defer lg.Close()
 
-	lg := logger.NewLogger(conf.ServiceName)
+	args := flag.Args()
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), conf.CtxTimeOut)
-	defer cancel()
+	switch args[0] {
+	case "serve":
+		serve(conf, lg)
+	case "migrate":
+		runMigrate(conf, lg, args[1:])
+	case "create":
+		runCreateMigration(lg, args[1:])
+	case "config":
+		if len(args) != 2 || args[1] != "print" {
+			log.Fatal(usage)
+		}
+		if err = conf.Print(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatal(usage)
+	}
+}
 
-	repo, err := repository.New(ctx, conf)
+func loggerOption(conf config.Config) logger.Option {
+	return logger.Option{
+		Level:  conf.LogLevel,
+		Format: conf.LogFormat,
+		File: logger.FileOption{
+			Path:       conf.LogFilePath,
+			MaxSizeMB:  conf.LogFileMaxSizeMB,
+			MaxAge:     conf.LogFileMaxAge,
+			MaxBackups: conf.LogFileMaxBackups,
+		},
+		SampleInfo: uint32(conf.LogSampleInfo),
+		Caller:     conf.LogCaller,
+		Stack:      conf.LogStack,
+	}
+}
+
+func serve(conf config.Config, lg *logger.Logger) {
+	store := config.NewStore(conf, config.Load)
+	store.Subscribe(func(cfg config.Config) {
+		if err := logger.SetLevel(cfg.LogLevel); err != nil {
+			lg.Error().Err(err).Msg("failed to apply log level")
+		}
+	})
+	emailPolicy := emailpolicy.New(tenant.FromContext)
+	applyEmailPolicy := func(cfg config.Config) {
+		rules, err := cfg.EmailPolicy()
+		if err == nil {
+			err = emailPolicy.SetRules(rules)
+		}
+		if err != nil {
+			lg.Error().Err(err).Msg("failed to apply email policy, current one is kept")
+		}
+	}
+	store.Subscribe(applyEmailPolicy)
+	emailValidator := validator.NewEmailValidator()
+	store.Subscribe(func(cfg config.Config) {
+		// deny domains are checked on config load
+		denyList, _ := cfg.EmailDenyList()
+		checkers := []validator.DomainChecker{denyList, emailPolicy}
+		if cfg.EmailCheckMX {
+			checkers = append(checkers, validator.MXChecker{Resolver: net.DefaultResolver})
+		}
+		emailValidator.SetCheckers(checkers...)
+	})
+	reloadConfig := func() ([]config.Change, error) {
+		changes, err := reload(store, lg)
+		if err == nil {
+			// deny file may change while settings stay the same
+			applyEmailPolicy(store.Current())
+		}
+		return changes, err
+	}
+
+	blobs, err := blob.NewFileStore(conf.AvatarDir)
 	if err != nil {
-		lg.Fatal().Err(err).Msg("failed to initialize repository")
+		lg.Fatal().Err(err).Msg("failed to initialize avatar storage")
 	}
-	defer repo.Close()
 
-	serviceManager := service.New(repo)
-	router := handler.NewRouter(handler.Option{
-		Conf:    conf,
-		Logger:  lg,
-		Service: serviceManager,
+	healthRegistry := health.NewRegistry()
+	manager := lifecycle.New(conf.ShutdownTimeout)
+
+	var (
+		repo           repository.IRepository
+		serviceManager *service.Service
+	)
+	manager.Add(lifecycle.Component{
+		Name: componentRepository,
+		Start: func(ctx context.Context) error {
+			// startup is bounded by deadline and interrupted by termination signals
+			ctx, cancel := context.WithTimeout(ctx, conf.StartupTimeout)
+			defer cancel()
+
+			var err error
+			if repo, err = repository.New(ctx, conf, lg); err != nil {
+				return err
+			}
+			healthRegistry.Register("postgres", conf.HealthCheckTimeout, repo.Ping)
+			healthRegistry.Register("migrations", conf.HealthCheckTimeout, repo.CheckMigrations)
+			serviceManager = service.New(repo, emailValidator, blobs)
+			lg.Info().Msg("repository initialized")
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			// pool is closed after http server, so in-flight requests can finish their queries
+			repo.Close()
+			return nil
+		},
 	})
 
-	server := httpserv.NewServer(conf.RunAddress, router)
+	// gRPC server is stopped after http one, so it serves during the drain delay too
+	if conf.GRPCAddress != "" {
+		var grpcServer *grpcserv.Server
+		manager.Add(lifecycle.Component{
+			Name:      componentGRPC,
+			DependsOn: []string{componentRepository},
+			Start: func(ctx context.Context) error {
+				userHandler := grpchandler.New(&grpchandler.HandlerConfig{
+					Config:  store,
+					Logger:  lg,
+					Service: serviceManager,
+				})
+				tenantResolver := middleware.NewTenant(tenant.Resolver{})
+				store.Subscribe(func(cfg config.Config) {
+					tenantResolver.SetResolver(cfg.TenantResolver())
+				})
+				grpcServer = grpcserv.NewServer(conf.GRPCAddress, func(s *grpc.Server) {
+					pb.RegisterUserServiceServer(s, userHandler)
+				}, grpc.UnaryInterceptor(tenantResolver.UnaryInterceptor()))
 
+				go func() {
+					if err := grpcServer.Run(); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+						manager.Fail(fmt.Errorf("grpc server: %w", err))
+					}
+				}()
+
+				lg.Info().Msg("gRPC server listening on " + conf.GRPCAddress)
+				return nil
+			},
+			Stop: func(ctx context.Context) error {
+				return grpcServer.Shutdown(ctx)
+			},
+		})
+	}
+
+	var server *httpserv.Server
+	manager.Add(lifecycle.Component{
+		Name:      componentHTTP,
+		DependsOn: []string{componentRepository},
+		Start: func(ctx context.Context) error {
+			router := handler.NewRouter(handler.Option{
+				Conf:         store,
+				Logger:       lg,
+				Service:      serviceManager,
+				ReloadConfig: reloadConfig,
+				Health:       healthRegistry,
+			})
+			server = httpserv.NewServer(conf.RunAddress, router)
+
+			go func() {
+				if err := server.Run(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					manager.Fail(fmt.Errorf("http server: %w", err))
+				}
+			}()
+
+			lg.Info().Msg("Server listening on " + conf.RunAddress)
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			// readiness fails first, so load balancers stop sending traffic before the server is closed
+			healthRegistry.SetShuttingDown()
+			lg.Info().Str("delay", conf.ShutdownDrainDelay.String()).Msg("draining traffic before shutdown")
+			select {
+			case <-time.After(conf.ShutdownDrainDelay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			return server.Shutdown(ctx)
+		},
+		StopTimeout: conf.ShutdownDrainDelay + conf.ShutdownTimeout,
+	})
+
+	// first signal starts graceful shutdown, second one abandons components which have not stopped yet
+	runCtx, cancelRun := context.WithCancel(context.Background())
+	defer cancelRun()
+	stopCtx, forceStop := context.WithCancel(context.Background())
+	defer forceStop()
+
+	quit := make(chan os.Signal, 2)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
 	go func() {
-		err = server.Run()
-		if err != nil && !errors.Is(err, http.ErrServerClosed) {
-			lg.Info().Err(err).Msg("failed to run http server")
-		}
+		sig := <-quit
+		lg.Info().Str("signal", sig.String()).Msg("shutting down, repeat the signal to force exit")
+		cancelRun()
+
+		sig = <-quit
+		lg.Warn().Str("signal", sig.String()).Msg("forcing exit")
+		forceStop()
 	}()
 
-	lg.Info().Msg("Server listening on " + conf.RunAddress)
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			_, _ = reloadConfig()
+		}
+	}()
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
-	<-quit
+	if err := manager.Start(runCtx); err != nil {
+		lg.Fatal().Err(err).Msg("failed to start service")
+	}
 
-	ctx, cancel = context.WithTimeout(context.Background(), conf.CtxTimeOut)
-	defer cancel()
+	failure := manager.Wait(runCtx)
+	if failure != nil {
+		lg.Error().Err(failure).Msg("component failed, shutting down")
+	}
 
-	if err = server.Shutdown(ctx); err != nil {
-		lg.Error().Err(err).Msg("error in shutting down server")
+	if err := manager.Stop(stopCtx); err != nil {
+		lg.Fatal().Err(err).Msg("error in shutting down service")
+	}
+	if failure != nil {
+		lg.Fatal().Err(failure).Msg("service stopped after failure")
 	}
 
 	lg.Info().Msg("server stopped successfully")
 }
+
+// reload - reloading config and logging what changed.
+func reload(store *config.Store, lg *logger.Logger) ([]config.Change, error) {
+	changes, err := store.Reload()
+	if err != nil {
+		lg.Error().Err(err).Msg("config reload rejected, current config is kept")
+		return nil, err
+	}
+
+	for _, change := range changes {
+		event := lg.Info()
+		if !change.Reloadable {
+			event = lg.Warn()
+		}
+		event.Str("key", change.Key).
+			Str("old", change.Old).
+			Str("new", change.New).
+			Bool("reloadable", change.Reloadable).
+			Msg("config changed")
+	}
+	lg.Info().Int("changes", len(changes)).Msg("config reloaded")
+
+	return changes, nil
+}
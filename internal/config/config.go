@@ -1,103 +1,666 @@
 package config
 
 import (
+	"errors"
 	"flag"
+	"fmt"
 	"github.com/joho/godotenv"
-	"github.com/spf13/cast"
-	"log"
+	"github.com/sonikq/gravitum_test_task/pkg/emailpolicy"
+	"github.com/sonikq/gravitum_test_task/pkg/logger"
+	"github.com/sonikq/gravitum_test_task/pkg/tenant"
+	"github.com/sonikq/gravitum_test_task/pkg/validator"
+	"net"
 	"os"
-	"strconv"
+	"slices"
+	"strings"
 	"time"
 )
 
 type Config struct {
-	RunAddress string
+	RunAddress  string
+	GRPCAddress string
 
 	DatabaseDSN   string
 	DBPoolWorkers int
+	AutoMigrate   bool
+	// DatabaseMigrationDSN - DSN migrations are applied through, DatabaseDSN if empty.
+	DatabaseMigrationDSN string
+	DBRowSecurity        bool
 
 	CtxTimeOut  time.Duration
-	LogLevel    string
 	ServiceName string
+
+	LogLevel          string
+	LogFormat         string
+	LogFilePath       string
+	LogFileMaxSizeMB  int
+	LogFileMaxAge     time.Duration
+	LogFileMaxBackups int
+	LogSampleInfo     int
+	LogCaller         bool
+	LogStack          bool
+
+	RateLimitRPS   int
+	RateLimitBurst int
+
+	AdminToken       string
+	FeaturesDisabled string
+
+	OpenAPIValidation string
+
+	APIV1DeprecatedAt string
+	APIV1Sunset       string
+
+	CacheControl string
+
+	BatchGetMaxIDs int
+
+	EmailCheckMX      bool
+	EmailDenyDomains  string
+	EmailDenyFile     string
+	EmailAllowDomains string
+
+	AvatarDir       string
+	AvatarMaxBytes  int
+	AvatarMaxPixels int
+
+	TenantHeader   string
+	TenantClaim    string
+	TenantJWTKey   string
+	TenantRequired bool
+
+	HealthCheckTimeout time.Duration
+	ShutdownDrainDelay time.Duration
+	ShutdownTimeout    time.Duration
+
+	StartupTimeout         time.Duration
+	StartupRetryInitial    time.Duration
+	StartupRetryMax        time.Duration
+	StartupRetryMultiplier float64
+	StartupRetryJitter     float64
 }
 
 const (
 	defaultRunAddress    = "localhost:3000"
+	defaultGRPCAddress   = "localhost:3001"
 	defaultLogLevel      = "info"
 	defaultServiceName   = "user-management"
 	defaultDatabaseDSN   = ""
 	defaultDBPoolWorkers = 50
+	defaultAutoMigrate   = true
+	defaultDBRowSecurity = false
 	defaultMode          = "debug"
+	defaultEnvFile       = "internal/config/.env"
 	defaultCtxTimeOut    = 5 * time.Second
+
+	defaultLogFormat         = "json"
+	defaultLogFileMaxSizeMB  = 100
+	defaultLogFileMaxAge     = 24 * time.Hour
+	defaultLogFileMaxBackups = 7
+	defaultLogCaller         = true
+
+	defaultRateLimitRPS   = 0
+	defaultRateLimitBurst = 100
+
+	defaultAdminToken       = ""
+	defaultFeaturesDisabled = ""
+
+	defaultOpenAPIValidation = ValidationOff
+
+	defaultAPIV1DeprecatedAt = ""
+	defaultAPIV1Sunset       = ""
+
+	defaultCacheControl = "/users/:id=private, no-cache; /users/:id/avatar=private, no-cache; /users/:id/export=no-store; " +
+		"/openapi.json=public, max-age=300"
+
+	defaultBatchGetMaxIDs = 100
+
+	defaultEmailCheckMX      = false
+	defaultEmailDenyDomains  = ""
+	defaultEmailDenyFile     = ""
+	defaultEmailAllowDomains = ""
+
+	defaultAvatarDir       = "data/avatars"
+	defaultAvatarMaxBytes  = 5 << 20
+	defaultAvatarMaxPixels = 50_000_000
+
+	defaultTenantHeader   = "X-Tenant-ID"
+	defaultTenantClaim    = "tenant_id"
+	defaultTenantJWTKey   = ""
+	defaultTenantRequired = false
+
+	defaultHealthCheckTimeout = time.Second
+	defaultShutdownDrainDelay = 3 * time.Second
+	defaultShutdownTimeout    = 10 * time.Second
+
+	defaultStartupTimeout         = 30 * time.Second
+	defaultStartupRetryInitial    = 500 * time.Millisecond
+	defaultStartupRetryMax        = 5 * time.Second
+	defaultStartupRetryMultiplier = 2
+	defaultStartupRetryJitter     = 0.2
+)
+
+const (
+	modeDebug   = "debug"
+	modeRelease = "release"
+)
+
+// Modes of OpenAPI validation of OPENAPI_VALIDATION.
+const (
+	ValidationOff    = "off"
+	ValidationLog    = "log"
+	ValidationStrict = "strict"
+)
+
+// Feature toggles, names of features FEATURES_DISABLED may turn off.
+const (
+	// FeatureGraphQL - POST /graphql.
+	FeatureGraphQL = "graphql"
+	// FeatureAvatars - upload, download and removal of avatars in REST.
+	FeatureAvatars = "avatars"
+	// FeatureSwagger - Swagger UI, /openapi.json stays available.
+	FeatureSwagger = "swagger"
 )
 
-var (
-	runAddress = flag.String("a", defaultRunAddress,
+// Features - every feature which can be turned off.
+var Features = []string{FeatureGraphQL, FeatureAvatars, FeatureSwagger}
+
+const (
+	flagRunAddress  = "a"
+	flagDatabaseDSN = "d"
+	flagMode        = "mode"
+	flagEnvFile     = "env-file"
+	flagConfigPath  = "config"
+)
+
+// fields - every setting with the env key it is read from, file keys are lowercase env keys.
+// Reloadable settings are applied to running components on reload, others need restart.
+var fields = []field{
+	{env: "RUN_ADDRESS", flag: flagRunAddress, value: func(c *Config) any { return &c.RunAddress }},
+	{env: "GRPC_ADDRESS", value: func(c *Config) any { return &c.GRPCAddress }},
+	{env: "DATABASE_DSN", flag: flagDatabaseDSN, secret: true, value: func(c *Config) any { return &c.DatabaseDSN }},
+	{env: "DB_POOL_WORKERS", value: func(c *Config) any { return &c.DBPoolWorkers }},
+	{env: "AUTO_MIGRATE", value: func(c *Config) any { return &c.AutoMigrate }},
+	{env: "DATABASE_MIGRATION_DSN", secret: true, value: func(c *Config) any { return &c.DatabaseMigrationDSN }},
+	{env: "DB_ROW_SECURITY", value: func(c *Config) any { return &c.DBRowSecurity }},
+	{env: "CTX_TIMEOUT", reloadable: true, value: func(c *Config) any { return &c.CtxTimeOut }},
+	{env: "LOG_LEVEL", reloadable: true, value: func(c *Config) any { return &c.LogLevel }},
+	{env: "SERVICE_NAME", value: func(c *Config) any { return &c.ServiceName }},
+	{env: "LOG_FORMAT", value: func(c *Config) any { return &c.LogFormat }},
+	{env: "LOG_FILE_PATH", value: func(c *Config) any { return &c.LogFilePath }},
+	{env: "LOG_FILE_MAX_SIZE_MB", value: func(c *Config) any { return &c.LogFileMaxSizeMB }},
+	{env: "LOG_FILE_MAX_AGE", value: func(c *Config) any { return &c.LogFileMaxAge }},
+	{env: "LOG_FILE_MAX_BACKUPS", value: func(c *Config) any { return &c.LogFileMaxBackups }},
+	{env: "LOG_SAMPLE_INFO", value: func(c *Config) any { return &c.LogSampleInfo }},
+	{env: "LOG_CALLER", value: func(c *Config) any { return &c.LogCaller }},
+	{env: "LOG_STACK", value: func(c *Config) any { return &c.LogStack }},
+	{env: "RATE_LIMIT_RPS", reloadable: true, value: func(c *Config) any { return &c.RateLimitRPS }},
+	{env: "RATE_LIMIT_BURST", reloadable: true, value: func(c *Config) any { return &c.RateLimitBurst }},
+	{env: "ADMIN_TOKEN", secret: true, reloadable: true, value: func(c *Config) any { return &c.AdminToken }},
+	{env: "FEATURES_DISABLED", reloadable: true, value: func(c *Config) any { return &c.FeaturesDisabled }},
+	{env: "OPENAPI_VALIDATION", value: func(c *Config) any { return &c.OpenAPIValidation }},
+	{env: "API_V1_DEPRECATED_AT", reloadable: true, value: func(c *Config) any { return &c.APIV1DeprecatedAt }},
+	{env: "API_V1_SUNSET", reloadable: true, value: func(c *Config) any { return &c.APIV1Sunset }},
+	{env: "CACHE_CONTROL", reloadable: true, value: func(c *Config) any { return &c.CacheControl }},
+	{env: "BATCH_GET_MAX_IDS", reloadable: true, value: func(c *Config) any { return &c.BatchGetMaxIDs }},
+	{env: "EMAIL_CHECK_MX", reloadable: true, value: func(c *Config) any { return &c.EmailCheckMX }},
+	{env: "EMAIL_DENY_DOMAINS", reloadable: true, value: func(c *Config) any { return &c.EmailDenyDomains }},
+	{env: "EMAIL_DENY_FILE", reloadable: true, value: func(c *Config) any { return &c.EmailDenyFile }},
+	{env: "EMAIL_ALLOW_DOMAINS", reloadable: true, value: func(c *Config) any { return &c.EmailAllowDomains }},
+	{env: "AVATAR_DIR", value: func(c *Config) any { return &c.AvatarDir }},
+	{env: "AVATAR_MAX_BYTES", reloadable: true, value: func(c *Config) any { return &c.AvatarMaxBytes }},
+	{env: "AVATAR_MAX_PIXELS", reloadable: true, value: func(c *Config) any { return &c.AvatarMaxPixels }},
+	{env: "TENANT_HEADER", reloadable: true, value: func(c *Config) any { return &c.TenantHeader }},
+	{env: "TENANT_CLAIM", reloadable: true, value: func(c *Config) any { return &c.TenantClaim }},
+	{env: "TENANT_JWT_KEY", secret: true, reloadable: true, value: func(c *Config) any { return &c.TenantJWTKey }},
+	{env: "TENANT_REQUIRED", reloadable: true, value: func(c *Config) any { return &c.TenantRequired }},
+	{env: "HEALTH_CHECK_TIMEOUT", value: func(c *Config) any { return &c.HealthCheckTimeout }},
+	{env: "SHUTDOWN_DRAIN_DELAY", value: func(c *Config) any { return &c.ShutdownDrainDelay }},
+	{env: "SHUTDOWN_TIMEOUT", value: func(c *Config) any { return &c.ShutdownTimeout }},
+	{env: "STARTUP_TIMEOUT", value: func(c *Config) any { return &c.StartupTimeout }},
+	{env: "STARTUP_RETRY_INITIAL", value: func(c *Config) any { return &c.StartupRetryInitial }},
+	{env: "STARTUP_RETRY_MAX", value: func(c *Config) any { return &c.StartupRetryMax }},
+	{env: "STARTUP_RETRY_MULTIPLIER", value: func(c *Config) any { return &c.StartupRetryMultiplier }},
+	{env: "STARTUP_RETRY_JITTER", value: func(c *Config) any { return &c.StartupRetryJitter }},
+}
+
+func init() {
+	registerFlags(flag.CommandLine)
+}
+
+func registerFlags(fs *flag.FlagSet) {
+	fs.String(flagRunAddress, defaultRunAddress,
 		"run address defines on what port and host the server will be started")
-	databaseDSN = flag.String("d", defaultDatabaseDSN,
+	fs.String(flagDatabaseDSN, defaultDatabaseDSN,
 		"defines the database connection address")
-	modePtr     = flag.String("mode", defaultMode, "mode defines which env file to use for service")
-	flagsParsed = false
-)
+	fs.String(flagMode, defaultMode, "mode defines whether env file is loaded: debug or release")
+	fs.String(flagEnvFile, defaultEnvFile, "env file loaded in debug mode")
+	fs.String(flagConfigPath, "", "path to YAML or TOML config file")
+}
 
+// Default - config with default values only.
+func Default() Config {
+	return Config{
+		RunAddress:    defaultRunAddress,
+		GRPCAddress:   defaultGRPCAddress,
+		DatabaseDSN:   defaultDatabaseDSN,
+		DBPoolWorkers: defaultDBPoolWorkers,
+		AutoMigrate:   defaultAutoMigrate,
+		DBRowSecurity: defaultDBRowSecurity,
+		CtxTimeOut:    defaultCtxTimeOut,
+		LogLevel:      defaultLogLevel,
+		ServiceName:   defaultServiceName,
+
+		LogFormat:         defaultLogFormat,
+		LogFileMaxSizeMB:  defaultLogFileMaxSizeMB,
+		LogFileMaxAge:     defaultLogFileMaxAge,
+		LogFileMaxBackups: defaultLogFileMaxBackups,
+		LogCaller:         defaultLogCaller,
+
+		RateLimitRPS:   defaultRateLimitRPS,
+		RateLimitBurst: defaultRateLimitBurst,
+
+		AdminToken:       defaultAdminToken,
+		FeaturesDisabled: defaultFeaturesDisabled,
+
+		OpenAPIValidation: defaultOpenAPIValidation,
+
+		APIV1DeprecatedAt: defaultAPIV1DeprecatedAt,
+		APIV1Sunset:       defaultAPIV1Sunset,
+
+		CacheControl: defaultCacheControl,
+
+		BatchGetMaxIDs: defaultBatchGetMaxIDs,
+
+		EmailCheckMX:      defaultEmailCheckMX,
+		EmailDenyDomains:  defaultEmailDenyDomains,
+		EmailDenyFile:     defaultEmailDenyFile,
+		EmailAllowDomains: defaultEmailAllowDomains,
+
+		AvatarDir:       defaultAvatarDir,
+		AvatarMaxBytes:  defaultAvatarMaxBytes,
+		AvatarMaxPixels: defaultAvatarMaxPixels,
+
+		TenantHeader:   defaultTenantHeader,
+		TenantClaim:    defaultTenantClaim,
+		TenantJWTKey:   defaultTenantJWTKey,
+		TenantRequired: defaultTenantRequired,
+
+		HealthCheckTimeout: defaultHealthCheckTimeout,
+		ShutdownDrainDelay: defaultShutdownDrainDelay,
+		ShutdownTimeout:    defaultShutdownTimeout,
+
+		StartupTimeout:         defaultStartupTimeout,
+		StartupRetryInitial:    defaultStartupRetryInitial,
+		StartupRetryMax:        defaultStartupRetryMax,
+		StartupRetryMultiplier: defaultStartupRetryMultiplier,
+		StartupRetryJitter:     defaultStartupRetryJitter,
+	}
+}
+
+// Load - building config from layers: defaults, config file, env file (debug mode),
+// environment and explicitly set command line flags, each layer overrides the previous one.
+// All parsing and validation problems are returned at once.
 func Load() (Config, error) {
-	if !flagsParsed && !flag.Parsed() {
+	if !flag.Parsed() {
 		flag.Parse()
-		flagsParsed = true
+	}
+	return load(flag.CommandLine, os.LookupEnv)
+}
+
+func load(fs *flag.FlagSet, lookupEnv func(string) (string, bool)) (Config, error) {
+	var errs []error
+
+	explicit := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = f.Value.String()
+	})
+	flagValue := func(name string) string {
+		return fs.Lookup(name).Value.String()
+	}
+
+	layers := make([]layer, 0, 4)
+
+	if path := flagValue(flagConfigPath); path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		layers = append(layers, layer{name: path, lookup: mapLookup(values)})
 	}
 
-	switch *modePtr {
-	case "debug":
-		if err := godotenv.Load("internal/config/.env"); err != nil {
-			return Config{}, err
+	switch mode := flagValue(flagMode); mode {
+	case modeDebug:
+		values, err := godotenv.Read(flagValue(flagEnvFile))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("env file: %w", err))
 		}
-	case "release":
+		layers = append(layers, layer{name: flagValue(flagEnvFile), lookup: mapLookup(values)})
+	case modeRelease:
+	default:
+		errs = append(errs, fmt.Errorf("mode: invalid value %q, available is: debug/release", mode))
+	}
+
+	layers = append(layers,
+		layer{name: "environment", lookup: lookupEnv},
+		layer{name: "flags", lookup: func(key string) (string, bool) {
+			for _, f := range fields {
+				if f.env == key && f.flag != "" {
+					value, ok := explicit[f.flag]
+					return value, ok
+				}
+			}
+			return "", false
+		}},
+	)
+
+	cfg := Default()
+	for _, l := range layers {
+		for _, f := range fields {
+			raw, ok := l.lookup(f.env)
+			if !ok {
+				continue
+			}
+			if err := f.set(&cfg, raw); err != nil {
+				errs = append(errs, fmt.Errorf("%s (from %s): %w", f.key(), l.name, err))
+			}
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return cfg, errors.Join(errs...)
+}
+
+// Validate - checking values of config, all found problems are joined into one error.
+func (c Config) Validate() error {
+	var errs []error
+
+	if _, _, err := net.SplitHostPort(c.RunAddress); err != nil {
+		errs = append(errs, fmt.Errorf("run_address: %w", err))
+	}
+
+	// empty grpc address disables gRPC server
+	if c.GRPCAddress != "" {
+		if _, _, err := net.SplitHostPort(c.GRPCAddress); err != nil {
+			errs = append(errs, fmt.Errorf("grpc_address: %w", err))
+		} else if c.GRPCAddress == c.RunAddress {
+			errs = append(errs, errors.New("grpc_address: must differ from run_address"))
+		}
+	}
+
+	if c.DBPoolWorkers < 1 {
+		errs = append(errs, fmt.Errorf("db_pool_workers: must be positive, got %d", c.DBPoolWorkers))
+	}
+
+	if c.CtxTimeOut <= 0 {
+		errs = append(errs, fmt.Errorf("ctx_timeout: must be positive, got %s", c.CtxTimeOut))
+	}
+
+	if _, err := logger.ParseLevel(c.LogLevel); err != nil || c.LogLevel == "" {
+		errs = append(errs, fmt.Errorf("log_level: invalid value %q", c.LogLevel))
+	}
+
+	if c.LogFormat != logger.FormatJSON && c.LogFormat != logger.FormatConsole {
+		errs = append(errs, fmt.Errorf("log_format: invalid value %q, available is: json/console", c.LogFormat))
+	}
+
+	if c.LogFileMaxSizeMB < 1 {
+		errs = append(errs, fmt.Errorf("log_file_max_size_mb: must be positive, got %d", c.LogFileMaxSizeMB))
+	}
+
+	if c.LogFileMaxAge < 0 {
+		errs = append(errs, fmt.Errorf("log_file_max_age: must not be negative, got %s", c.LogFileMaxAge))
+	}
 
+	if c.LogFileMaxBackups < 0 {
+		errs = append(errs, fmt.Errorf("log_file_max_backups: must not be negative, got %d", c.LogFileMaxBackups))
+	}
+
+	if c.LogSampleInfo < 0 {
+		errs = append(errs, fmt.Errorf("log_sample_info: must not be negative, got %d", c.LogSampleInfo))
+	}
+
+	if strings.TrimSpace(c.ServiceName) == "" {
+		errs = append(errs, errors.New("service_name: must not be empty"))
+	}
+
+	if c.RateLimitRPS < 0 {
+		errs = append(errs, fmt.Errorf("rate_limit_rps: must not be negative, got %d", c.RateLimitRPS))
+	}
+
+	if c.RateLimitRPS > 0 && c.RateLimitBurst < 1 {
+		errs = append(errs, fmt.Errorf("rate_limit_burst: must be positive, got %d", c.RateLimitBurst))
+	}
+
+	if _, err := c.DisabledFeatures(); err != nil {
+		errs = append(errs, fmt.Errorf("features_disabled: %w", err))
+	}
+
+	switch c.OpenAPIValidation {
+	case ValidationOff, ValidationLog, ValidationStrict:
 	default:
-		log.Fatal("invalid mode: " + *modePtr)
+		errs = append(errs, fmt.Errorf("openapi_validation: invalid value %q, available is: off/log/strict",
+			c.OpenAPIValidation))
 	}
 
-	cfg := new(Config)
+	deprecatedAt, err := ParseDate(c.APIV1DeprecatedAt)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("api_v1_deprecated_at: %w", err))
+	}
+	sunset, err := ParseDate(c.APIV1Sunset)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("api_v1_sunset: %w", err))
+	} else if !sunset.IsZero() && c.APIV1DeprecatedAt == "" {
+		// Sunset is sent only along with Deprecation
+		errs = append(errs, errors.New("api_v1_sunset: requires api_v1_deprecated_at"))
+	} else if !sunset.IsZero() && !deprecatedAt.IsZero() && !sunset.After(deprecatedAt) {
+		errs = append(errs, fmt.Errorf("api_v1_sunset: must be after api_v1_deprecated_at, got %s", c.APIV1Sunset))
+	}
 
-	cfg.RunAddress = getEnvString(*runAddress, "RUN_ADDRESS")
+	if _, err = ParseCacheControl(c.CacheControl); err != nil {
+		errs = append(errs, fmt.Errorf("cache_control: %w", err))
+	}
 
-	cfg.DatabaseDSN = getEnvString(*databaseDSN, "DATABASE_DSN")
-	cfg.DBPoolWorkers = getEnvInt(defaultDBPoolWorkers, "DB_POOL_WORKERS")
+	if c.BatchGetMaxIDs < 1 {
+		errs = append(errs, fmt.Errorf("batch_get_max_ids: must be positive, got %d", c.BatchGetMaxIDs))
+	}
 
-	cfg.CtxTimeOut = getEnvDuration(defaultCtxTimeOut, "CTX_TIMEOUT")
-	cfg.LogLevel = getEnvString(defaultLogLevel, "LOG_LEVEL")
-	cfg.ServiceName = getEnvString(defaultServiceName, "SERVICE_NAME")
+	if _, err = c.EmailDenyList(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err = c.EmailPolicy(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if strings.TrimSpace(c.AvatarDir) == "" {
+		errs = append(errs, errors.New("avatar_dir: must not be empty"))
+	}
+	if c.AvatarMaxBytes < 1 {
+		errs = append(errs, fmt.Errorf("avatar_max_bytes: must be positive, got %d", c.AvatarMaxBytes))
+	}
+	if c.AvatarMaxPixels < 1 {
+		errs = append(errs, fmt.Errorf("avatar_max_pixels: must be positive, got %d", c.AvatarMaxPixels))
+	}
+
+	if c.TenantJWTKey != "" && strings.TrimSpace(c.TenantClaim) == "" {
+		errs = append(errs, errors.New("tenant_claim: must not be empty when tenant_jwt_key is set"))
+	}
+
+	if c.TenantRequired && c.TenantHeader == "" && c.TenantJWTKey == "" {
+		errs = append(errs, errors.New("tenant_required: tenant_header or tenant_jwt_key must be set"))
+	}
+
+	if c.HealthCheckTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("health_check_timeout: must be positive, got %s", c.HealthCheckTimeout))
+	}
+
+	if c.ShutdownDrainDelay < 0 {
+		errs = append(errs, fmt.Errorf("shutdown_drain_delay: must not be negative, got %s", c.ShutdownDrainDelay))
+	}
+
+	if c.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("shutdown_timeout: must be positive, got %s", c.ShutdownTimeout))
+	}
 
-	return *cfg, nil
+	if c.StartupTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("startup_timeout: must be positive, got %s", c.StartupTimeout))
+	}
+
+	if c.StartupRetryInitial <= 0 {
+		errs = append(errs, fmt.Errorf("startup_retry_initial: must be positive, got %s", c.StartupRetryInitial))
+	}
+
+	if c.StartupRetryMax < c.StartupRetryInitial {
+		errs = append(errs, fmt.Errorf("startup_retry_max: must not be less than startup_retry_initial, got %s",
+			c.StartupRetryMax))
+	}
+
+	if c.StartupRetryMultiplier < 1 {
+		errs = append(errs, fmt.Errorf("startup_retry_multiplier: must be at least 1, got %g", c.StartupRetryMultiplier))
+	}
+
+	if c.StartupRetryJitter < 0 || c.StartupRetryJitter > 1 {
+		errs = append(errs, fmt.Errorf("startup_retry_jitter: must be between 0 and 1, got %g", c.StartupRetryJitter))
+	}
+
+	return errors.Join(errs...)
+}
+
+// ParseDate - parsing date in YYYY-MM-DD form as midnight UTC, empty value is zero time.
+func ParseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", value)
+	}
+	return date, nil
+}
+
+// ParseCacheControl - parsing Cache-Control values of routes in "route=value; route=value" form,
+// e.g. "/users/:id=private, no-cache". Routes are gin paths without version prefix.
+func ParseCacheControl(value string) (map[string]string, error) {
+	policies := make(map[string]string)
+	for _, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		route, policy, ok := strings.Cut(entry, "=")
+		route, policy = strings.TrimSpace(route), strings.TrimSpace(policy)
+		if !ok || !strings.HasPrefix(route, "/") || policy == "" {
+			return nil, fmt.Errorf("invalid entry %q, expected /route=value", strings.TrimSpace(entry))
+		}
+		if _, ok = policies[route]; ok {
+			return nil, fmt.Errorf("route %s is set twice", route)
+		}
+		policies[route] = policy
+	}
+	return policies, nil
 }
 
-func getEnvString(flagValue string, envKey string) string {
-	envValue, exists := os.LookupEnv(envKey)
-	if exists {
-		return envValue
+// MigrationDSN - DSN migrations are applied through: DATABASE_MIGRATION_DSN, or DATABASE_DSN if it is not set.
+func (c Config) MigrationDSN() string {
+	if c.DatabaseMigrationDSN != "" {
+		return c.DatabaseMigrationDSN
 	}
-	return flagValue
+	return c.DatabaseDSN
 }
 
-func getEnvDuration(flagValue time.Duration, envKey string) time.Duration {
-	envValue, exists := os.LookupEnv(envKey)
-	if exists {
-		return time.Millisecond * time.Duration(cast.ToInt(envValue))
+// DisabledFeatures - set of features turned off by FEATURES_DISABLED, unknown names are rejected.
+func (c Config) DisabledFeatures() (map[string]bool, error) {
+	disabled := make(map[string]bool)
+	for _, name := range ParseList(strings.ToLower(c.FeaturesDisabled)) {
+		if !slices.Contains(Features, name) {
+			return nil, fmt.Errorf("unknown feature %q, available is: %s", name, strings.Join(Features, "/"))
+		}
+		disabled[name] = true
 	}
-	return flagValue
+	return disabled, nil
 }
 
-func getEnvInt(flagValue int, envKey string) int {
-	envValue, exists := os.LookupEnv(envKey)
-	if exists {
-		intVal, err := strconv.Atoi(envValue)
+// EmailPolicy - rules of email domains from EMAIL_DENY_FILE and EMAIL_ALLOW_DOMAINS.
+// Deny file is read on every call, so its changes are picked up without changes of config.
+// EMAIL_DENY_DOMAINS is not a part of the policy, see EmailDenyList.
+func (c Config) EmailPolicy() (emailpolicy.Rules, error) {
+	var rules emailpolicy.Rules
+	if c.EmailDenyFile != "" {
+		patterns, err := emailpolicy.ReadPatterns(c.EmailDenyFile)
 		if err != nil {
-			log.Printf("cant convert env-key: %s to int", envValue)
-			return 1
+			return rules, fmt.Errorf("email_deny_file: %w", err)
+		}
+		rules.Deny = patterns
+		if err = rules.Validate(); err != nil {
+			return rules, fmt.Errorf("email_deny_file: %w", err)
 		}
+	}
 
-		return intVal
+	allow, err := ParseTenantLists(c.EmailAllowDomains)
+	if err != nil {
+		return rules, fmt.Errorf("email_allow_domains: %w", err)
 	}
+	rules.Allow = allow
+	if err = (emailpolicy.Rules{Allow: allow}).Validate(); err != nil {
+		return rules, fmt.Errorf("email_allow_domains: %w", err)
+	}
+	return rules, nil
+}
+
+// EmailDenyList - domains of EMAIL_DENY_DOMAINS, rejected together with their subdomains as invalid emails.
+func (c Config) EmailDenyList() (validator.DenyList, error) {
+	denyList, err := validator.NewDenyList(ParseList(c.EmailDenyDomains)...)
+	if err != nil {
+		return denyList, fmt.Errorf("email_deny_domains: %w", err)
+	}
+	return denyList, nil
+}
+
+// TenantResolver - rules of resolving tenant of requests from TENANT_* settings.
+func (c Config) TenantResolver() tenant.Resolver {
+	return tenant.Resolver{
+		Header:   strings.TrimSpace(c.TenantHeader),
+		Claim:    strings.TrimSpace(c.TenantClaim),
+		Key:      []byte(c.TenantJWTKey),
+		Required: c.TenantRequired,
+	}
+}
 
-	return flagValue
+// ParseTenantLists - parsing lists of tenants in "tenant=item, item; tenant=item" form.
+func ParseTenantLists(value string) (map[string][]string, error) {
+	lists := make(map[string][]string)
+	for _, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		name, list, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		items := ParseList(list)
+		if !ok || name == "" || len(items) == 0 {
+			return nil, fmt.Errorf("invalid entry %q, expected tenant=item, item", strings.TrimSpace(entry))
+		}
+		if !tenant.ValidID(name) {
+			return nil, fmt.Errorf("invalid tenant id %q", name)
+		}
+		if _, ok = lists[name]; ok {
+			return nil, fmt.Errorf("tenant %s is set twice", name)
+		}
+		lists[name] = items
+	}
+	return lists, nil
+}
+
+// ParseList - non-empty items of comma separated list without surrounding spaces.
+func ParseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
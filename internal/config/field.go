@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const redacted = "xxxxx"
+
+type field struct {
+	env        string
+	flag       string
+	secret     bool
+	reloadable bool
+	value      func(c *Config) any
+}
+
+// key - name of the setting in config file.
+func (f field) key() string {
+	return strings.ToLower(f.env)
+}
+
+func (f field) set(c *Config, raw string) error {
+	switch ptr := f.value(c).(type) {
+	case *string:
+		*ptr = raw
+	case *int:
+		v, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		*ptr = v
+	case *bool:
+		v, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		*ptr = v
+	case *float64:
+		v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		*ptr = v
+	case *time.Duration:
+		v, err := parseDuration(raw)
+		if err != nil {
+			return err
+		}
+		*ptr = v
+	default:
+		return fmt.Errorf("unsupported type %T", ptr)
+	}
+	return nil
+}
+
+// raw - value in the form accepted by set.
+func (f field) raw(c *Config) string {
+	switch ptr := f.value(c).(type) {
+	case *string:
+		return *ptr
+	case *int:
+		return strconv.Itoa(*ptr)
+	case *bool:
+		return strconv.FormatBool(*ptr)
+	case *float64:
+		return strconv.FormatFloat(*ptr, 'g', -1, 64)
+	case *time.Duration:
+		return ptr.String()
+	}
+	return ""
+}
+
+// format - value safe to be printed or logged.
+func (f field) format(c *Config) string {
+	if f.secret {
+		return redact(f.raw(c))
+	}
+	return f.raw(c)
+}
+
+// parseDuration - accepting go durations like "5s" as well as plain integers in milliseconds.
+func parseDuration(raw string) (time.Duration, error) {
+	raw = strings.TrimSpace(raw)
+	if ms, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q, use milliseconds or units like 5s", raw)
+	}
+	return d, nil
+}
+
+var passwordParam = regexp.MustCompile(`(password\s*=\s*)('[^']*'|\S+)`)
+
+// redact - hiding password in url or key=value connection strings.
+func redact(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	if u, err := url.Parse(value); err == nil && u.Scheme != "" {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+		}
+		query := u.Query()
+		if query.Has("password") {
+			query.Set("password", redacted)
+			u.RawQuery = query.Encode()
+		}
+		return u.String()
+	}
+
+	if passwordParam.MatchString(value) {
+		return passwordParam.ReplaceAllString(value, "${1}"+redacted)
+	}
+	return redacted
+}
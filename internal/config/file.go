@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// layer - one source of settings, values are looked up by env key.
+type layer struct {
+	name   string
+	lookup func(key string) (string, bool)
+}
+
+func mapLookup(values map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+}
+
+// readFile - reading YAML or TOML config file into values keyed by env key.
+// Nested tables are flattened with "_", so log: {level: debug} is the same as log_level: debug.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+
+	raw := make(map[string]any)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("config file: unsupported extension %q, use .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	flatten("", raw, values)
+
+	known := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		known[f.env] = true
+	}
+
+	var unknown []string
+	for key := range values {
+		if !known[key] {
+			unknown = append(unknown, strings.ToLower(key))
+		}
+	}
+	if len(unknown) != 0 {
+		sort.Strings(unknown)
+		return values, fmt.Errorf("config file %s: unknown keys: %s", path, strings.Join(unknown, ", "))
+	}
+
+	return values, nil
+}
+
+func flatten(prefix string, raw map[string]any, values map[string]string) {
+	for key, value := range raw {
+		key = strings.ToUpper(prefix + key)
+		switch v := value.(type) {
+		case map[string]any:
+			flatten(key+"_", v, values)
+		case nil:
+			values[key] = ""
+		case string:
+			values[key] = v
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+// Print - writing effective config in YAML form with secrets redacted.
+func (c Config) Print(w io.Writer) error {
+	for _, f := range fields {
+		if _, err := fmt.Fprintf(w, "%s: %s\n", f.key(), strconv.Quote(f.format(&c))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Change - difference of one setting between two configs, secrets are redacted.
+type Change struct {
+	Key        string `json:"key"`
+	Old        string `json:"old"`
+	New        string `json:"new"`
+	Reloadable bool   `json:"reloadable"`
+}
+
+// Store - holds current config and applies reloads to subscribed components.
+type Store struct {
+	mu          sync.Mutex
+	current     atomic.Pointer[Config]
+	load        func() (Config, error)
+	subscribers []func(Config)
+}
+
+// NewStore - creating store with initial config, load is used to read config again on reload.
+func NewStore(cfg Config, load func() (Config, error)) *Store {
+	s := &Store{load: load}
+	s.current.Store(&cfg)
+	return s
+}
+
+// Current - getting snapshot of current config.
+func (s *Store) Current() Config {
+	return *s.current.Load()
+}
+
+// Subscribe - registering fn to be called with the new config after every applied reload.
+// fn is also called right away with the current config, so components start consistent.
+func (s *Store) Subscribe(fn func(Config)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subscribers = append(s.subscribers, fn)
+	fn(s.Current())
+}
+
+// Reload - reading config again and applying reloadable settings.
+// Invalid config is rejected and the current one is kept. Changes of settings
+// which are not reloadable are reported but take effect only after restart.
+func (s *Store) Reload() ([]Change, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	loaded, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	next := s.Current()
+	changes := Diff(next, loaded)
+
+	var applied bool
+	for _, change := range changes {
+		applied = applied || change.Reloadable
+	}
+	if !applied {
+		return changes, nil
+	}
+
+	for _, f := range fields {
+		if f.reloadable {
+			if err = f.set(&next, f.raw(&loaded)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	s.current.Store(&next)
+	for _, fn := range s.subscribers {
+		fn(next)
+	}
+
+	return changes, nil
+}
+
+// Diff - listing settings which differ between old and new config.
+func Diff(old, new Config) []Change {
+	var changes []Change
+	for _, f := range fields {
+		if f.raw(&old) == f.raw(&new) {
+			continue
+		}
+		changes = append(changes, Change{
+			Key:        f.key(),
+			Old:        f.format(&old),
+			New:        f.format(&new),
+			Reloadable: f.reloadable,
+		})
+	}
+	return changes
+}
@@ -0,0 +1,23 @@
+package admin
+
+import (
+	"github.com/sonikq/gravitum_test_task/internal/config"
+	"github.com/sonikq/gravitum_test_task/pkg/logger"
+)
+
+type Handler struct {
+	logger       *logger.Logger
+	reloadConfig func() ([]config.Change, error)
+}
+
+type HandlerConfig struct {
+	Logger       *logger.Logger
+	ReloadConfig func() ([]config.Change, error)
+}
+
+func New(cfg *HandlerConfig) *Handler {
+	return &Handler{
+		logger:       cfg.Logger,
+		reloadConfig: cfg.ReloadConfig,
+	}
+}
@@ -0,0 +1,32 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/gravitum_test_task/internal/config"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"net/http"
+	"strings"
+)
+
+// ReloadConfig - reading config again and applying reloadable settings, invalid config is rejected.
+func (h *Handler) ReloadConfig(ctx *gin.Context) {
+	const source = "handler.ReloadConfig"
+
+	changes, err := h.reloadConfig()
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			models.ErrMsgKey: "invalid config, current one is kept",
+			"errors":         strings.Split(err.Error(), "\n"),
+		})
+		h.logger.Error().
+			Err(err).
+			Str("source", source).
+			Msg("failed to reload config")
+		return
+	}
+
+	if changes == nil {
+		changes = []config.Change{}
+	}
+	ctx.JSON(http.StatusOK, gin.H{"changes": changes})
+}
@@ -0,0 +1,74 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"net/http"
+)
+
+// errorCodes - codes put into error extensions, status is the one REST API responds with.
+var errorCodes = []struct {
+	err    error
+	code   string
+	status int
+}{
+	{models.ErrUserDoesNotExist, "USER_DOES_NOT_EXIST", http.StatusNoContent},
+	{models.ErrUserIsGone, "USER_IS_GONE", http.StatusGone},
+	{models.ErrDeleteDeletedUser, "USER_IS_ALREADY_DELETED", http.StatusConflict},
+	{models.ErrRestoreActiveUser, "USER_IS_NOT_DELETED", http.StatusConflict},
+	{models.ErrUsernameIsAlreadyTaken, "USERNAME_IS_ALREADY_TAKEN", http.StatusConflict},
+	{models.ErrEmailIsAlreadyTaken, "EMAIL_IS_ALREADY_TAKEN", http.StatusConflict},
+	{models.ErrEmailDomainNotAllowed, models.ErrCodeEmailDomainNotAllowed, http.StatusUnprocessableEntity},
+	{models.ErrInvalidEmail, "INVALID_EMAIL", http.StatusBadRequest},
+	{models.ErrInvalidUsername, "INVALID_USERNAME", http.StatusBadRequest},
+	{models.ErrInvalidName, "INVALID_NAME", http.StatusBadRequest},
+	{models.ErrInvalidGender, "INVALID_GENDER", http.StatusBadRequest},
+	{models.ErrInvalidAttributes, "INVALID_ATTRIBUTES", http.StatusBadRequest},
+	{models.ErrInvalidAge, "INVALID_AGE", http.StatusBadRequest},
+	{errInvalidArgument, "INVALID_ARGUMENT", http.StatusBadRequest},
+	{context.DeadlineExceeded, "TIMEOUT", http.StatusGatewayTimeout},
+}
+
+var errInvalidArgument = errors.New("invalid argument")
+
+// resolverError - error with extensions {"code": ..., "status": ...} in GraphQL response.
+type resolverError struct {
+	message string
+	code    string
+	status  int
+}
+
+func (e *resolverError) Error() string {
+	return e.message
+}
+
+func (e *resolverError) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":   e.code,
+		"status": e.status,
+	}
+}
+
+// toResolverError - converting service error, internal details are not exposed to clients.
+func toResolverError(err error) error {
+	for _, ec := range errorCodes {
+		if errors.Is(err, ec.err) {
+			return &resolverError{message: err.Error(), code: ec.code, status: ec.status}
+		}
+	}
+	return &resolverError{
+		message: "internal server error, something went wrong",
+		code:    "INTERNAL",
+		status:  http.StatusInternalServerError,
+	}
+}
+
+// fail - logging error and converting it for the response.
+func (h *Handler) fail(err error, source, logMsg string) error {
+	h.logger.Error().
+		Err(err).
+		Str("source", source).
+		Msg(logMsg)
+	return toResolverError(err)
+}
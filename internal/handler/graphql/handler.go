@@ -0,0 +1,72 @@
+package graphql
+
+import (
+	_ "embed"
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/sonikq/gravitum_test_task/internal/config"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"github.com/sonikq/gravitum_test_task/internal/service"
+	"github.com/sonikq/gravitum_test_task/pkg/logger"
+	"net/http"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 1000
+	maxDepth        = 10
+)
+
+type Handler struct {
+	config  *config.Store
+	logger  *logger.Logger
+	service *service.Service
+	schema  *graphql.Schema
+}
+
+type HandlerConfig struct {
+	Config  *config.Store
+	Logger  *logger.Logger
+	Service *service.Service
+}
+
+func New(cfg *HandlerConfig) *Handler {
+	h := &Handler{
+		config:  cfg.Config,
+		logger:  cfg.Logger,
+		service: cfg.Service,
+	}
+	h.schema = graphql.MustParseSchema(schemaSDL, &resolver{h: h}, graphql.MaxDepth(maxDepth))
+	return h
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Query - executing GraphQL request, errors of resolvers are returned in the body with status 200.
+func (h *Handler) Query(ctx *gin.Context) {
+	const source = "handler.GraphQL"
+
+	var req request
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&req); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{models.ErrMsgKey: "Error in parsing request body"})
+		h.logger.Error().
+			Err(err).
+			Str("source", source).
+			Msg("failed to unmarshal request body")
+		return
+	}
+
+	// loader lives for one request, so users are batched and cached only within it
+	c := withUserLoader(ctx.Request.Context(), h.newUserLoader())
+
+	resp := h.schema.Exec(c, req.Query, req.OperationName, req.Variables)
+	ctx.JSON(http.StatusOK, resp)
+}
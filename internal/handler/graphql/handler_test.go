@@ -0,0 +1,221 @@
+package graphql
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/sonikq/gravitum_test_task/internal/config"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"github.com/sonikq/gravitum_test_task/internal/service"
+	"github.com/sonikq/gravitum_test_task/internal/service/servicetest"
+	"github.com/sonikq/gravitum_test_task/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type response struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+// execute sends query to the handler and decodes response
+func execute(t *testing.T, svc *servicetest.MockService, query string, variables map[string]any) response {
+	t.Helper()
+
+	lg, err := logger.NewLogger("test", logger.Option{Level: "disabled"})
+	require.NoError(t, err)
+
+	h := New(&HandlerConfig{
+		Config:  config.NewStore(config.Default(), config.Load),
+		Logger:  lg,
+		Service: &service.Service{IUserManagementService: svc},
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/graphql", h.Query)
+
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp response
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return resp
+}
+
+// TestQuery_UserBatched tests that lookups of one request are batched into one service call
+func TestQuery_UserBatched(t *testing.T) {
+	svc := new(servicetest.MockService)
+	endDate := time.Now()
+	svc.On("GetUsers", mock.MatchedBy(func(ids []int64) bool { return len(ids) == 3 })).
+		Return(map[int64]*models.UserInfo{
+			1: {ID: 1, Username: "first"},
+			2: {ID: 2, Username: "second", EndDate: &endDate},
+		}, nil).Once()
+
+	resp := execute(t, svc, `{
+		a: user(id: 1) { username }
+		b: user(id: 2) { username }
+		c: user(id: 3) { username }
+		d: user(id: 1) { id }
+	}`, nil)
+
+	assert.JSONEq(t, `{"username": "first"}`, string(resp.Data["a"]))
+	assert.JSONEq(t, `{"id": "1"}`, string(resp.Data["d"]))
+	assert.Equal(t, "null", string(resp.Data["b"]))
+
+	codes := make(map[string]any)
+	for _, e := range resp.Errors {
+		codes[e.Extensions["code"].(string)] = e.Extensions["status"]
+	}
+	assert.Equal(t, map[string]any{"USER_IS_GONE": float64(410), "USER_DOES_NOT_EXIST": float64(204)}, codes)
+	svc.AssertExpectations(t)
+}
+
+// TestQuery_UsersPagination tests cursor pagination and filters
+func TestQuery_UsersPagination(t *testing.T) {
+	svc := new(servicetest.MockService)
+	svc.On("ListUsers", models.UserFilter{Limit: 3, Gender: "F", MinAge: 18}).
+		Return([]models.UserInfo{{ID: 1}, {ID: 4}, {ID: 7}}, nil).Once()
+	svc.On("ListUsers", models.UserFilter{AfterID: 4, Limit: 3}).
+		Return([]models.UserInfo{{ID: 7}}, nil).Once()
+
+	query := `query($after: String, $filter: UserFilter) {
+		users(first: 2, after: $after, filter: $filter) {
+			edges { node { id } }
+			pageInfo { endCursor hasNextPage }
+		}
+	}`
+
+	var page struct {
+		Edges []struct {
+			Node struct{ ID string }
+		}
+		PageInfo struct {
+			EndCursor   string
+			HasNextPage bool
+		}
+	}
+
+	resp := execute(t, svc, query, map[string]any{"filter": map[string]any{"gender": "F", "minAge": 18}})
+	require.Empty(t, resp.Errors)
+	require.NoError(t, json.Unmarshal(resp.Data["users"], &page))
+	assert.Len(t, page.Edges, 2)
+	assert.True(t, page.PageInfo.HasNextPage)
+
+	resp = execute(t, svc, query, map[string]any{"after": page.PageInfo.EndCursor})
+	require.Empty(t, resp.Errors)
+	require.NoError(t, json.Unmarshal(resp.Data["users"], &page))
+	assert.Len(t, page.Edges, 1)
+	assert.False(t, page.PageInfo.HasNextPage)
+
+	resp = execute(t, svc, query, map[string]any{"after": "garbage"})
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "INVALID_ARGUMENT", resp.Errors[0].Extensions["code"])
+
+	svc.AssertExpectations(t)
+}
+
+// TestAttributes tests JSON attributes in filters, inputs and results
+func TestAttributes(t *testing.T) {
+	svc := new(servicetest.MockService)
+	svc.On("ListUsers", models.UserFilter{Limit: 51, Attributes: map[string]any{"office": map[string]any{"floor": int32(3)}}}).
+		Return([]models.UserInfo{{ID: 1, Attributes: map[string]any{"office": map[string]any{"floor": float64(3)}}}}, nil).Once()
+
+	resp := execute(t, svc, `{ users(filter: {attributes: {office: {floor: 3}}}) { edges { node { attributes } } } }`, nil)
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"edges": [{"node": {"attributes": {"office": {"floor": 3}}}}]}`, string(resp.Data["users"]))
+
+	resp = execute(t, svc, `{ users(filter: {attributes: "floor"}) { edges { node { id } } } }`, nil)
+	require.Len(t, resp.Errors, 1)
+
+	input := map[string]any{
+		"username": "jdoe", "firstName": "John", "lastName": "Doe",
+		"email": "john@example.com", "gender": "M", "age": 30,
+	}
+	expected := models.UserInfo{ID: 5, Username: "jdoe", FirstName: "John", LastName: "Doe", Email: "john@example.com", Gender: "M", Age: 30}
+
+	// kept attributes are read after update
+	stored := expected
+	stored.Attributes = map[string]any{"locale": "ru"}
+	svc.On("UpdateUser", expected).Return(nil).Once()
+	svc.On("GetUser", int64(5)).Return(&stored, nil).Once()
+	resp = execute(t, svc, `mutation($input: UserInput!) { updateUser(id: 5, input: $input) { attributes } }`,
+		map[string]any{"input": input})
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"attributes": {"locale": "ru"}}`, string(resp.Data["updateUser"]))
+
+	input["attributes"] = map[string]any{"locale": "en"}
+	expected.Attributes = map[string]any{"locale": "en"}
+	svc.On("UpdateUser", expected).Return(nil).Once()
+	svc.On("GetUser", int64(5)).Return(&expected, nil).Once()
+	resp = execute(t, svc, `mutation($input: UserInput!) { updateUser(id: 5, input: $input) { attributes } }`,
+		map[string]any{"input": input})
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"attributes": {"locale": "en"}}`, string(resp.Data["updateUser"]))
+
+	svc.AssertExpectations(t)
+}
+
+// TestMutation tests mutations and their error codes
+func TestMutation(t *testing.T) {
+	svc := new(servicetest.MockService)
+	input := map[string]any{
+		"username": "jdoe", "firstName": "John", "lastName": "Doe",
+		"email": "john@example.com", "gender": "M", "age": 30,
+	}
+	expected := models.UserInfo{Username: "jdoe", FirstName: "John", LastName: "Doe", Email: "john@example.com", Gender: "M", Age: 30}
+
+	// stored user is answered, not the input
+	created := models.UserInfo{Username: "Alice ", FirstName: "Alice", LastName: "Doe", Email: "Alice@Example.com ", Gender: "F", Age: 30}
+	stored := models.UserInfo{ID: 6, Username: "alice", FirstName: "Alice", LastName: "Doe", Email: "alice@example.com", Gender: "F", Age: 30}
+	svc.On("CreateUser", created).Return("6", nil).Once()
+	svc.On("GetUser", int64(6)).Return(&stored, nil).Once()
+	resp := execute(t, svc, `mutation($input: UserInput!) { createUser(input: $input) { id username email } }`,
+		map[string]any{"input": map[string]any{
+			"username": "Alice ", "firstName": "Alice", "lastName": "Doe",
+			"email": "Alice@Example.com ", "gender": "F", "age": 30,
+		}})
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"id": "6", "username": "alice", "email": "alice@example.com"}`, string(resp.Data["createUser"]))
+
+	svc.On("CreateUser", expected).Return("", models.ErrUsernameIsAlreadyTaken).Once()
+	resp = execute(t, svc, `mutation($input: UserInput!) { createUser(input: $input) { id } }`,
+		map[string]any{"input": input})
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "USERNAME_IS_ALREADY_TAKEN", resp.Errors[0].Extensions["code"])
+	assert.Equal(t, float64(http.StatusConflict), resp.Errors[0].Extensions["status"])
+
+	svc.On("CreateUser", expected).Return("", fmt.Errorf("%w: denied", models.ErrEmailDomainNotAllowed)).Once()
+	resp = execute(t, svc, `mutation($input: UserInput!) { createUser(input: $input) { id } }`,
+		map[string]any{"input": input})
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "EMAIL_DOMAIN_NOT_ALLOWED", resp.Errors[0].Extensions["code"])
+	assert.Equal(t, float64(http.StatusUnprocessableEntity), resp.Errors[0].Extensions["status"])
+
+	svc.On("DeleteUser", int64(5)).Return(nil).Once()
+	resp = execute(t, svc, `mutation { deleteUser(id: 5) }`, nil)
+	require.Empty(t, resp.Errors)
+	assert.Equal(t, "true", string(resp.Data["deleteUser"]))
+
+	svc.On("RestoreUser", int64(5)).Return(models.ErrRestoreActiveUser).Once()
+	resp = execute(t, svc, `mutation { restoreUser(id: 5) { id } }`, nil)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "USER_IS_NOT_DELETED", resp.Errors[0].Extensions["code"])
+
+	svc.AssertExpectations(t)
+}
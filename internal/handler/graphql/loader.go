@@ -0,0 +1,58 @@
+package graphql
+
+import (
+	"context"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"github.com/sonikq/gravitum_test_task/pkg/dataloader"
+)
+
+type userLoaderKey struct{}
+
+type userLoader = dataloader.Loader[int64, *models.UserInfo]
+
+func withUserLoader(ctx context.Context, loader *userLoader) context.Context {
+	return context.WithValue(ctx, userLoaderKey{}, loader)
+}
+
+// loadUser - getting active user through the request loader, so lookups of one request go in one query.
+func (h *Handler) loadUser(ctx context.Context, id int64) (*models.UserInfo, error) {
+	loader, ok := ctx.Value(userLoaderKey{}).(*userLoader)
+	if !ok {
+		c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+		defer cancel()
+		return h.service.GetUser(c, id)
+	}
+	return loader.Load(ctx, id)
+}
+
+func (h *Handler) newUserLoader() *userLoader {
+	return dataloader.New(func(ctx context.Context, ids []int64) ([]*models.UserInfo, []error) {
+		c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+		defer cancel()
+
+		users := make([]*models.UserInfo, len(ids))
+		errs := make([]error, len(ids))
+
+		found, err := h.service.GetUsers(c, ids)
+		if err != nil {
+			for i := range errs {
+				errs[i] = err
+			}
+			return users, errs
+		}
+
+		// the same rules as service.GetUser
+		for i, id := range ids {
+			userInfo, ok := found[id]
+			switch {
+			case !ok:
+				errs[i] = models.ErrUserDoesNotExist
+			case userInfo.EndDate != nil:
+				errs[i] = models.ErrUserIsGone
+			default:
+				users[i] = userInfo
+			}
+		}
+		return users, errs
+	}, dataloader.Option{})
+}
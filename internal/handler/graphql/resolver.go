@@ -0,0 +1,367 @@
+package graphql
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const cursorPrefix = "user:"
+
+// resolver - root of Query and Mutation types.
+type resolver struct {
+	h *Handler
+}
+
+type userInput struct {
+	Username   string
+	FirstName  string
+	MiddleName *string
+	LastName   string
+	Email      string
+	Gender     string
+	Age        int32
+	Attributes *jsonObject
+}
+
+type userFilterInput struct {
+	Gender         *string
+	MinAge         *int32
+	MaxAge         *int32
+	UsernamePrefix *string
+	Attributes     *jsonObject
+}
+
+func (r *resolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*userResolver, error) {
+	const source = "graphql.User"
+
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, toResolverError(err)
+	}
+
+	userInfo, err := r.h.loadUser(ctx, id)
+	if err != nil {
+		return nil, r.h.fail(err, source, "failed to get user")
+	}
+	return &userResolver{user: userInfo}, nil
+}
+
+func (r *resolver) Users(ctx context.Context, args struct {
+	Filter *userFilterInput
+	First  *int32
+	After  *string
+}) (*userConnectionResolver, error) {
+	const source = "graphql.Users"
+
+	filter, err := toUserFilter(args.Filter)
+	if err != nil {
+		return nil, toResolverError(err)
+	}
+
+	limit := defaultPageSize
+	if args.First != nil {
+		limit = int(*args.First)
+	}
+	if limit < 0 || limit > maxPageSize {
+		return nil, toResolverError(fmt.Errorf("%w: first must be between 0 and %d", errInvalidArgument, maxPageSize))
+	}
+
+	if args.After != nil {
+		if filter.AfterID, err = decodeCursor(*args.After); err != nil {
+			return nil, toResolverError(err)
+		}
+	}
+
+	// one more user tells whether there is a next page
+	filter.Limit = limit + 1
+
+	c, cancel := context.WithTimeout(ctx, r.h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	users, err := r.h.service.ListUsers(c, filter)
+	if err != nil {
+		return nil, r.h.fail(err, source, "failed to list users")
+	}
+
+	conn := &userConnectionResolver{hasNextPage: len(users) > limit}
+	if conn.hasNextPage {
+		users = users[:limit]
+	}
+	conn.users = users
+	return conn, nil
+}
+
+func (r *resolver) CreateUser(ctx context.Context, args struct{ Input userInput }) (*userResolver, error) {
+	const source = "graphql.CreateUser"
+
+	request, err := toUserInfo(args.Input)
+	if err != nil {
+		return nil, r.h.fail(err, source, "failed to map request")
+	}
+
+	c, cancel := context.WithTimeout(ctx, r.h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	id, err := r.h.service.CreateUser(c, request)
+	if err != nil {
+		return nil, r.h.fail(err, source, "failed to create user")
+	}
+
+	userID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, r.h.fail(err, source, "failed to parse created user id")
+	}
+
+	// stored user is answered, it has normalized username and email and timestamps
+	userInfo, err := r.h.service.GetUser(c, userID)
+	if err != nil {
+		return nil, r.h.fail(err, source, "failed to get created user")
+	}
+	return &userResolver{user: userInfo}, nil
+}
+
+func (r *resolver) UpdateUser(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input userInput
+}) (*userResolver, error) {
+	const source = "graphql.UpdateUser"
+
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, toResolverError(err)
+	}
+
+	request, err := toUserInfo(args.Input)
+	if err != nil {
+		return nil, r.h.fail(err, source, "failed to map request")
+	}
+	request.ID = id
+
+	c, cancel := context.WithTimeout(ctx, r.h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	if err = r.h.service.UpdateUser(c, request); err != nil {
+		return nil, r.h.fail(err, source, "failed to update user")
+	}
+
+	// stored user is answered, it has normalized username and email, timestamps and kept attributes
+	userInfo, err := r.h.service.GetUser(c, id)
+	if err != nil {
+		return nil, r.h.fail(err, source, "failed to get updated user")
+	}
+	return &userResolver{user: userInfo}, nil
+}
+
+func (r *resolver) DeleteUser(ctx context.Context, args struct{ ID graphql.ID }) (bool, error) {
+	const source = "graphql.DeleteUser"
+
+	id, err := parseID(args.ID)
+	if err != nil {
+		return false, toResolverError(err)
+	}
+
+	c, cancel := context.WithTimeout(ctx, r.h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	if err = r.h.service.DeleteUser(c, id); err != nil {
+		return false, r.h.fail(err, source, "failed to delete user")
+	}
+	return true, nil
+}
+
+func (r *resolver) RestoreUser(ctx context.Context, args struct{ ID graphql.ID }) (*userResolver, error) {
+	const source = "graphql.RestoreUser"
+
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, toResolverError(err)
+	}
+
+	c, cancel := context.WithTimeout(ctx, r.h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	if err = r.h.service.RestoreUser(c, id); err != nil {
+		return nil, r.h.fail(err, source, "failed to restore user")
+	}
+
+	// loader may hold the user as deleted, so it is read again
+	userInfo, err := r.h.service.GetUser(c, id)
+	if err != nil {
+		return nil, r.h.fail(err, source, "failed to get restored user")
+	}
+	return &userResolver{user: userInfo}, nil
+}
+
+type userResolver struct {
+	user *models.UserInfo
+}
+
+func (r *userResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatInt(r.user.ID, 10))
+}
+
+func (r *userResolver) Username() string {
+	return r.user.Username
+}
+
+func (r *userResolver) FirstName() string {
+	return r.user.FirstName
+}
+
+func (r *userResolver) MiddleName() string {
+	return r.user.MiddleName
+}
+
+func (r *userResolver) LastName() string {
+	return r.user.LastName
+}
+
+func (r *userResolver) Email() string {
+	return r.user.Email
+}
+
+func (r *userResolver) Gender() string {
+	return r.user.Gender
+}
+
+func (r *userResolver) Age() int32 {
+	return int32(r.user.Age)
+}
+
+func (r *userResolver) Attributes() jsonObject {
+	if r.user.Attributes == nil {
+		return jsonObject{}
+	}
+	return r.user.Attributes
+}
+
+type userConnectionResolver struct {
+	users       []models.UserInfo
+	hasNextPage bool
+}
+
+func (r *userConnectionResolver) Edges() []*userEdgeResolver {
+	edges := make([]*userEdgeResolver, 0, len(r.users))
+	for i := range r.users {
+		edges = append(edges, &userEdgeResolver{user: &r.users[i]})
+	}
+	return edges
+}
+
+func (r *userConnectionResolver) PageInfo() *pageInfoResolver {
+	info := &pageInfoResolver{hasNextPage: r.hasNextPage}
+	if len(r.users) > 0 {
+		cursor := encodeCursor(r.users[len(r.users)-1].ID)
+		info.endCursor = &cursor
+	}
+	return info
+}
+
+type userEdgeResolver struct {
+	user *models.UserInfo
+}
+
+func (r *userEdgeResolver) Cursor() string {
+	return encodeCursor(r.user.ID)
+}
+
+func (r *userEdgeResolver) Node() *userResolver {
+	return &userResolver{user: r.user}
+}
+
+type pageInfoResolver struct {
+	endCursor   *string
+	hasNextPage bool
+}
+
+func (r *pageInfoResolver) EndCursor() *string {
+	return r.endCursor
+}
+
+func (r *pageInfoResolver) HasNextPage() bool {
+	return r.hasNextPage
+}
+
+func parseID(id graphql.ID) (int64, error) {
+	userID, err := strconv.ParseInt(string(id), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid id %q", errInvalidArgument, id)
+	}
+	return userID, nil
+}
+
+// encodeCursor - opaque cursor, clients must not rely on its format.
+func encodeCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.FormatInt(id, 10)))
+}
+
+func decodeCursor(cursor string) (int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return 0, fmt.Errorf("%w: invalid cursor", errInvalidArgument)
+	}
+
+	id, err := strconv.ParseInt(strings.TrimPrefix(string(raw), cursorPrefix), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid cursor", errInvalidArgument)
+	}
+	return id, nil
+}
+
+func toUserInfo(input userInput) (models.UserInfo, error) {
+	if input.Age < 0 || input.Age > math.MaxUint8 {
+		return models.UserInfo{}, models.ErrInvalidAge
+	}
+
+	userInfo := models.UserInfo{
+		Username:  input.Username,
+		FirstName: input.FirstName,
+		LastName:  input.LastName,
+		Email:     input.Email,
+		Gender:    input.Gender,
+		Age:       uint8(input.Age),
+	}
+	if input.MiddleName != nil {
+		userInfo.MiddleName = *input.MiddleName
+	}
+	if input.Attributes != nil {
+		userInfo.Attributes = *input.Attributes
+	}
+	return userInfo, nil
+}
+
+func toUserFilter(input *userFilterInput) (models.UserFilter, error) {
+	var filter models.UserFilter
+	if input == nil {
+		return filter, nil
+	}
+
+	if input.Gender != nil {
+		filter.Gender = *input.Gender
+	}
+	if input.UsernamePrefix != nil {
+		filter.UsernamePrefix = *input.UsernamePrefix
+	}
+	if input.Attributes != nil {
+		filter.Attributes = *input.Attributes
+	}
+	for _, age := range []struct {
+		value  *int32
+		target *uint8
+	}{{input.MinAge, &filter.MinAge}, {input.MaxAge, &filter.MaxAge}} {
+		if age.value == nil {
+			continue
+		}
+		if *age.value < 0 || *age.value > math.MaxUint8 {
+			return filter, models.ErrInvalidAge
+		}
+		*age.target = uint8(*age.value)
+	}
+	return filter, nil
+}
@@ -0,0 +1,26 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// jsonObject - JSON scalar, e.g. attributes of user, only objects are accepted.
+type jsonObject map[string]any
+
+func (jsonObject) ImplementsGraphQLType(name string) bool {
+	return name == "JSON"
+}
+
+func (o *jsonObject) UnmarshalGraphQL(input any) error {
+	value, ok := input.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%w: JSON object is expected, got %T", errInvalidArgument, input)
+	}
+	*o = value
+	return nil
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any(o))
+}
@@ -0,0 +1,37 @@
+package user_management
+
+import (
+	"context"
+	pb "github.com/sonikq/gravitum_test_task/pkg/api/user_management/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strconv"
+)
+
+func (h *Handler) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	const source = "grpc.CreateUser"
+
+	if req.GetUser() == nil {
+		return nil, status.Error(codes.InvalidArgument, "user is required")
+	}
+
+	request, err := toUserInfo(req.GetUser())
+	if err != nil {
+		return nil, h.fail(err, source, "failed to map request")
+	}
+	request.ID = 0
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	id, err := h.service.CreateUser(c, request)
+	if err != nil {
+		return nil, h.fail(err, source, "failed to create user")
+	}
+
+	userID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, h.fail(err, source, "failed to parse created user id")
+	}
+	return &pb.CreateUserResponse{Id: userID}, nil
+}
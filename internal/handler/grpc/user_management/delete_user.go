@@ -0,0 +1,18 @@
+package user_management
+
+import (
+	"context"
+	pb "github.com/sonikq/gravitum_test_task/pkg/api/user_management/v1"
+)
+
+func (h *Handler) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
+	const source = "grpc.DeleteUser"
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	if err := h.service.DeleteUser(c, req.GetId()); err != nil {
+		return nil, h.fail(err, source, "failed to delete user")
+	}
+	return &pb.DeleteUserResponse{}, nil
+}
@@ -0,0 +1,51 @@
+package user_management
+
+import (
+	"context"
+	"errors"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusCodes - gRPC codes of sentinel errors, the same cases as HTTP statuses of REST handlers.
+var statusCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{models.ErrUserDoesNotExist, codes.NotFound},
+	{models.ErrUserIsGone, codes.NotFound},
+	{models.ErrDeleteDeletedUser, codes.FailedPrecondition},
+	{models.ErrRestoreActiveUser, codes.FailedPrecondition},
+	{models.ErrUsernameIsAlreadyTaken, codes.AlreadyExists},
+	{models.ErrEmailIsAlreadyTaken, codes.AlreadyExists},
+	{models.ErrEmailDomainNotAllowed, codes.PermissionDenied},
+	{models.ErrInvalidEmail, codes.InvalidArgument},
+	{models.ErrInvalidUsername, codes.InvalidArgument},
+	{models.ErrInvalidName, codes.InvalidArgument},
+	{models.ErrInvalidGender, codes.InvalidArgument},
+	{models.ErrInvalidAttributes, codes.InvalidArgument},
+	{models.ErrInvalidAge, codes.InvalidArgument},
+	{models.ErrPendingMigrations, codes.Unavailable},
+	{context.DeadlineExceeded, codes.DeadlineExceeded},
+	{context.Canceled, codes.Canceled},
+}
+
+// toStatus - converting service error to gRPC status, internal details are not exposed to clients.
+func toStatus(err error) error {
+	for _, sc := range statusCodes {
+		if errors.Is(err, sc.err) {
+			return status.Error(sc.code, sc.err.Error())
+		}
+	}
+	return status.Error(codes.Internal, "internal server error, something went wrong")
+}
+
+// fail - logging error and converting it to gRPC status.
+func (h *Handler) fail(err error, source, logMsg string) error {
+	h.logger.Error().
+		Err(err).
+		Str("source", source).
+		Msg(logMsg)
+	return toStatus(err)
+}
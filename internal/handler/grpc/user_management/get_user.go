@@ -0,0 +1,19 @@
+package user_management
+
+import (
+	"context"
+	pb "github.com/sonikq/gravitum_test_task/pkg/api/user_management/v1"
+)
+
+func (h *Handler) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
+	const source = "grpc.GetUser"
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	userInfo, err := h.service.GetUser(c, req.GetId())
+	if err != nil {
+		return nil, h.fail(err, source, "failed to get user")
+	}
+	return &pb.GetUserResponse{User: toProtoUser(userInfo)}, nil
+}
@@ -0,0 +1,35 @@
+package user_management
+
+import (
+	"github.com/sonikq/gravitum_test_task/internal/config"
+	"github.com/sonikq/gravitum_test_task/internal/service"
+	pb "github.com/sonikq/gravitum_test_task/pkg/api/user_management/v1"
+	"github.com/sonikq/gravitum_test_task/pkg/logger"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 1000
+)
+
+type Handler struct {
+	pb.UnimplementedUserServiceServer
+
+	config  *config.Store
+	logger  *logger.Logger
+	service *service.Service
+}
+
+type HandlerConfig struct {
+	Config  *config.Store
+	Logger  *logger.Logger
+	Service *service.Service
+}
+
+func New(cfg *HandlerConfig) *Handler {
+	return &Handler{
+		config:  cfg.Config,
+		logger:  cfg.Logger,
+		service: cfg.Service,
+	}
+}
@@ -0,0 +1,166 @@
+package user_management
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/sonikq/gravitum_test_task/internal/config"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"github.com/sonikq/gravitum_test_task/internal/service"
+	"github.com/sonikq/gravitum_test_task/internal/service/servicetest"
+	pb "github.com/sonikq/gravitum_test_task/pkg/api/user_management/v1"
+	"github.com/sonikq/gravitum_test_task/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func newTestHandler(t *testing.T) (*Handler, *servicetest.MockService) {
+	t.Helper()
+
+	lg, err := logger.NewLogger("test", logger.Option{Level: "disabled"})
+	require.NoError(t, err)
+
+	svc := new(servicetest.MockService)
+	return New(&HandlerConfig{
+		Config:  config.NewStore(config.Default(), config.Load),
+		Logger:  lg,
+		Service: &service.Service{IUserManagementService: svc},
+	}), svc
+}
+
+// TestToStatus tests mapping of sentinel errors to gRPC codes
+func TestToStatus(t *testing.T) {
+	testCases := []struct {
+		err  error
+		code codes.Code
+	}{
+		{models.ErrUserDoesNotExist, codes.NotFound},
+		{models.ErrUserIsGone, codes.NotFound},
+		{models.ErrDeleteDeletedUser, codes.FailedPrecondition},
+		{models.ErrRestoreActiveUser, codes.FailedPrecondition},
+		{fmt.Errorf("wrapped: %w", models.ErrUsernameIsAlreadyTaken), codes.AlreadyExists},
+		{models.ErrEmailIsAlreadyTaken, codes.AlreadyExists},
+		{fmt.Errorf("%w: denied", models.ErrEmailDomainNotAllowed), codes.PermissionDenied},
+		{models.ErrInvalidAge, codes.InvalidArgument},
+		{models.ErrInvalidUsername, codes.InvalidArgument},
+		{context.DeadlineExceeded, codes.DeadlineExceeded},
+		{fmt.Errorf("connection refused"), codes.Internal},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			assert.Equal(t, tc.code, status.Code(toStatus(tc.err)))
+		})
+	}
+}
+
+// TestHandler_CreateUser tests conversion of request and errors
+func TestHandler_CreateUser(t *testing.T) {
+	h, svc := newTestHandler(t)
+	ctx := context.Background()
+
+	user := &pb.User{Id: 7, Username: "jdoe", FirstName: "John", LastName: "Doe", Email: "john@example.com", Gender: "M", Age: 30}
+	expected := models.UserInfo{Username: "jdoe", FirstName: "John", LastName: "Doe", Email: "john@example.com", Gender: "M", Age: 30}
+
+	svc.On("CreateUser", expected).Return("42", nil).Once()
+	resp, err := h.CreateUser(ctx, &pb.CreateUserRequest{User: user})
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), resp.GetId())
+
+	svc.On("CreateUser", expected).Return("", models.ErrUsernameIsAlreadyTaken).Once()
+	_, err = h.CreateUser(ctx, &pb.CreateUserRequest{User: user})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	_, err = h.CreateUser(ctx, &pb.CreateUserRequest{User: &pb.User{Age: 300}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = h.CreateUser(ctx, &pb.CreateUserRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	svc.AssertExpectations(t)
+}
+
+// TestHandler_GetUser tests not found mapping
+func TestHandler_GetUser(t *testing.T) {
+	h, svc := newTestHandler(t)
+
+	svc.On("GetUser", int64(1)).Return(&models.UserInfo{ID: 1, Username: "jdoe", Age: 30}, nil).Once()
+	resp, err := h.GetUser(context.Background(), &pb.GetUserRequest{Id: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "jdoe", resp.GetUser().GetUsername())
+	assert.Equal(t, uint32(30), resp.GetUser().GetAge())
+
+	svc.On("GetUser", int64(2)).Return(nil, models.ErrUserIsGone).Once()
+	_, err = h.GetUser(context.Background(), &pb.GetUserRequest{Id: 2})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	svc.AssertExpectations(t)
+}
+
+// TestHandler_ListUsers tests paging by page token
+func TestHandler_ListUsers(t *testing.T) {
+	h, svc := newTestHandler(t)
+	ctx := context.Background()
+
+	svc.On("ListUsers", models.UserFilter{Limit: 2}).Return([]models.UserInfo{{ID: 1}, {ID: 3}}, nil).Once()
+	resp, err := h.ListUsers(ctx, &pb.ListUsersRequest{PageSize: 2})
+	require.NoError(t, err)
+	assert.Len(t, resp.GetUsers(), 2)
+	assert.Equal(t, "3", resp.GetNextPageToken())
+
+	svc.On("ListUsers", models.UserFilter{AfterID: 3, Limit: 2}).Return([]models.UserInfo{{ID: 4}}, nil).Once()
+	resp, err = h.ListUsers(ctx, &pb.ListUsersRequest{PageSize: 2, PageToken: resp.GetNextPageToken()})
+	require.NoError(t, err)
+	assert.Len(t, resp.GetUsers(), 1)
+	assert.Empty(t, resp.GetNextPageToken(), "last page has no next token")
+
+	svc.On("ListUsers", models.UserFilter{Limit: defaultPageSize}).Return([]models.UserInfo{}, nil).Once()
+	_, err = h.ListUsers(ctx, &pb.ListUsersRequest{})
+	require.NoError(t, err)
+
+	_, err = h.ListUsers(ctx, &pb.ListUsersRequest{PageToken: "abc"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	svc.AssertExpectations(t)
+}
+
+// TestHandler_Attributes tests conversion of attributes in both directions
+func TestHandler_Attributes(t *testing.T) {
+	h, svc := newTestHandler(t)
+	ctx := context.Background()
+
+	attrs, err := structpb.NewStruct(map[string]any{"locale": "ru", "office": map[string]any{"floor": 3}})
+	require.NoError(t, err)
+
+	expected := models.UserInfo{ID: 1, Username: "jdoe", Attributes: map[string]any{"locale": "ru", "office": map[string]any{"floor": float64(3)}}}
+	svc.On("UpdateUser", expected).Return(nil).Once()
+	_, err = h.UpdateUser(ctx, &pb.UpdateUserRequest{User: &pb.User{Id: 1, Username: "jdoe", Attributes: attrs}})
+	require.NoError(t, err)
+
+	svc.On("UpdateUser", models.UserInfo{ID: 1, Username: "jdoe"}).Return(nil).Once()
+	_, err = h.UpdateUser(ctx, &pb.UpdateUserRequest{User: &pb.User{Id: 1, Username: "jdoe"}})
+	require.NoError(t, err, "not set attributes are kept")
+
+	svc.On("UpdateUser", expected).Return(models.ErrInvalidAttributes).Once()
+	_, err = h.UpdateUser(ctx, &pb.UpdateUserRequest{User: &pb.User{Id: 1, Username: "jdoe", Attributes: attrs}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	svc.On("GetUser", int64(1)).Return(&expected, nil).Once()
+	resp, err := h.GetUser(ctx, &pb.GetUserRequest{Id: 1})
+	require.NoError(t, err)
+	assert.Equal(t, expected.Attributes, resp.GetUser().GetAttributes().AsMap())
+
+	filter := models.UserFilter{Limit: defaultPageSize, Attributes: map[string]any{"locale": "ru"}}
+	svc.On("ListUsers", filter).Return([]models.UserInfo{{ID: 1}}, nil).Once()
+	locale, err := structpb.NewStruct(map[string]any{"locale": "ru"})
+	require.NoError(t, err)
+	lresp, err := h.ListUsers(ctx, &pb.ListUsersRequest{Attributes: locale})
+	require.NoError(t, err)
+	assert.Empty(t, lresp.GetUsers()[0].GetAttributes().AsMap())
+
+	svc.AssertExpectations(t)
+}
@@ -0,0 +1,54 @@
+package user_management
+
+import (
+	"context"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	pb "github.com/sonikq/gravitum_test_task/pkg/api/user_management/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strconv"
+)
+
+// ListUsers - page token is the id of the last user of the previous page.
+func (h *Handler) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	const source = "grpc.ListUsers"
+
+	pageSize := int(req.GetPageSize())
+	switch {
+	case pageSize < 0:
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+
+	var afterID int64
+	if token := req.GetPageToken(); token != "" {
+		var err error
+		if afterID, err = strconv.ParseInt(token, 10, 64); err != nil || afterID < 0 {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+	}
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	users, err := h.service.ListUsers(c, models.UserFilter{
+		AfterID:    afterID,
+		Limit:      pageSize,
+		Attributes: toAttributes(req.GetAttributes()),
+	})
+	if err != nil {
+		return nil, h.fail(err, source, "failed to list users")
+	}
+
+	resp := &pb.ListUsersResponse{Users: make([]*pb.User, 0, len(users))}
+	for i := range users {
+		resp.Users = append(resp.Users, toProtoUser(&users[i]))
+	}
+	if len(users) == pageSize {
+		resp.NextPageToken = strconv.FormatInt(users[len(users)-1].ID, 10)
+	}
+	return resp, nil
+}
@@ -0,0 +1,57 @@
+package user_management
+
+import (
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	pb "github.com/sonikq/gravitum_test_task/pkg/api/user_management/v1"
+	"google.golang.org/protobuf/types/known/structpb"
+	"math"
+)
+
+func toUserInfo(user *pb.User) (models.UserInfo, error) {
+	if user.GetAge() > math.MaxUint8 {
+		return models.UserInfo{}, models.ErrInvalidAge
+	}
+
+	return models.UserInfo{
+		ID:         user.GetId(),
+		Username:   user.GetUsername(),
+		FirstName:  user.GetFirstName(),
+		MiddleName: user.GetMiddleName(),
+		LastName:   user.GetLastName(),
+		Email:      user.GetEmail(),
+		Gender:     user.GetGender(),
+		Age:        uint8(user.GetAge()),
+		Attributes: toAttributes(user.GetAttributes()),
+	}, nil
+}
+
+// toAttributes - not set attributes stay nil, so update keeps current ones.
+func toAttributes(attrs *structpb.Struct) map[string]any {
+	if attrs == nil {
+		return nil
+	}
+	return attrs.AsMap()
+}
+
+func toProtoUser(userInfo *models.UserInfo) *pb.User {
+	return &pb.User{
+		Id:         userInfo.ID,
+		Username:   userInfo.Username,
+		FirstName:  userInfo.FirstName,
+		MiddleName: userInfo.MiddleName,
+		LastName:   userInfo.LastName,
+		Email:      userInfo.Email,
+		Gender:     userInfo.Gender,
+		Age:        uint32(userInfo.Age),
+		Attributes: toProtoAttributes(userInfo.Attributes),
+	}
+}
+
+// toProtoAttributes - stored attributes are JSON values, so conversion can't fail for them.
+func toProtoAttributes(attrs map[string]any) *structpb.Struct {
+	result, err := structpb.NewStruct(attrs)
+	if err != nil {
+		return &structpb.Struct{}
+	}
+	return result
+}
@@ -0,0 +1,29 @@
+package user_management
+
+import (
+	"context"
+	pb "github.com/sonikq/gravitum_test_task/pkg/api/user_management/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (h *Handler) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
+	const source = "grpc.UpdateUser"
+
+	if req.GetUser() == nil {
+		return nil, status.Error(codes.InvalidArgument, "user is required")
+	}
+
+	request, err := toUserInfo(req.GetUser())
+	if err != nil {
+		return nil, h.fail(err, source, "failed to map request")
+	}
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	if err = h.service.UpdateUser(c, request); err != nil {
+		return nil, h.fail(err, source, "failed to update user")
+	}
+	return &pb.UpdateUserResponse{}, nil
+}
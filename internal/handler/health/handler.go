@@ -0,0 +1,23 @@
+package health
+
+import (
+	"github.com/sonikq/gravitum_test_task/pkg/health"
+	"github.com/sonikq/gravitum_test_task/pkg/logger"
+)
+
+type Handler struct {
+	logger   *logger.Logger
+	registry *health.Registry
+}
+
+type HandlerConfig struct {
+	Logger   *logger.Logger
+	Registry *health.Registry
+}
+
+func New(cfg *HandlerConfig) *Handler {
+	return &Handler{
+		logger:   cfg.Logger,
+		registry: cfg.Registry,
+	}
+}
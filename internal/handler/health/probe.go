@@ -0,0 +1,37 @@
+package health
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/gravitum_test_task/pkg/health"
+	"net/http"
+)
+
+// Live - liveness probe, answers while the process is able to serve http.
+func (h *Handler) Live(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// Ready - readiness probe with status of every registered dependency check.
+func (h *Handler) Ready(ctx *gin.Context) {
+	const source = "handler.Ready"
+
+	report := h.registry.Run(ctx)
+	if !report.OK() {
+		ctx.AbortWithStatusJSON(http.StatusServiceUnavailable, report)
+		// errors of checks are not in the response, only in the log
+		failed := make(map[string]string)
+		for _, check := range report.Checks {
+			if check.Status != health.StatusOK {
+				failed[check.Name] = check.Error
+			}
+		}
+		h.logger.Warn().
+			Str("status", report.Status).
+			Interface("failed", failed).
+			Str("source", source).
+			Msg("service is not ready")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
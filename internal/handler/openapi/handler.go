@@ -0,0 +1,52 @@
+package openapi
+
+import (
+	_ "embed"
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	"net/http"
+	"strings"
+)
+
+const contentTypeHTML = "text/html; charset=utf-8"
+
+// swaggerIndex - Swagger UI page pointed at /openapi.json instead of the bundled example.
+//
+//go:embed index.html
+var swaggerIndex []byte
+
+type Handler struct {
+	document []byte
+	assets   http.Handler
+}
+
+// New - handler of the API document and Swagger UI mounted at prefix, e.g. /swagger.
+func New(prefix string) *Handler {
+	document, err := Spec().MarshalJSON()
+	if err != nil {
+		panic("openapi: failed to marshal spec: " + err.Error())
+	}
+
+	return &Handler{
+		document: document,
+		assets:   http.StripPrefix(prefix, http.FileServer(swaggerFiles.HTTP)),
+	}
+}
+
+// Document - OpenAPI document in JSON.
+func (h *Handler) Document(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, contentTypeJSON, h.document)
+}
+
+// SwaggerUI - Swagger UI page and its static assets, route must have *any parameter.
+func (h *Handler) SwaggerUI(ctx *gin.Context) {
+	switch path := ctx.Param("any"); {
+	case path == "" || path == "/" || path == "/index.html":
+		ctx.Data(http.StatusOK, contentTypeHTML, swaggerIndex)
+	case strings.HasSuffix(path, "/"):
+		// directory listings of the assets are not served
+		ctx.Status(http.StatusNotFound)
+	default:
+		h.assets.ServeHTTP(ctx.Writer, ctx.Request)
+	}
+}
@@ -0,0 +1,935 @@
+package openapi
+
+import (
+	"context"
+	"fmt"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3gen"
+	"github.com/sonikq/gravitum_test_task/internal/config"
+	"github.com/sonikq/gravitum_test_task/internal/handler/user_management"
+	"github.com/sonikq/gravitum_test_task/internal/handler/user_management/dto"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"github.com/sonikq/gravitum_test_task/pkg/health"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	contentTypeJSON      = "application/json"
+	contentTypeTextPlain = "text/plain"
+)
+
+const (
+	schemaCreateUserV1 = "CreateUserV1"
+	schemaUpdateUserV1 = "UpdateUserV1"
+	schemaUserV1       = "UserV1"
+	schemaCreateUserV2 = "CreateUserV2"
+	schemaUpdateUserV2 = "UpdateUserV2"
+	schemaUserV2       = "UserV2"
+	schemaCreatedV2    = "CreatedV2"
+	schemaBatchGet     = "BatchGetUsersRequest"
+	schemaBatchGetV1   = "BatchGetUsersV1"
+	schemaBatchGetV2   = "BatchGetUsersV2"
+	schemaSearchV1     = "SearchUsersV1"
+	schemaSearchV2     = "SearchUsersV2"
+	schemaUsername     = "UsernameAvailability"
+	schemaAttrSchemas  = "AttributeSchemas"
+	schemaGroupRequest = "GroupRequest"
+	schemaGroup        = "Group"
+	schemaGroups       = "Groups"
+	schemaMembership   = "MembershipRequest"
+	schemaMembers      = "GroupMembers"
+	schemaUserGroups   = "UserGroups"
+	schemaAvatar       = "AvatarUploaded"
+	schemaError        = "Error"
+	schemaMessage      = "Message"
+	schemaHealthReport = "HealthReport"
+	schemaChange       = "ConfigChange"
+)
+
+var (
+	specOnce sync.Once
+	spec     *openapi3.T
+)
+
+// Spec - OpenAPI document of every route of handler.NewRouter except Swagger UI assets.
+// Schemas of request and response bodies are generated from the Go types handlers use.
+// The document is built once and must not be modified.
+func Spec() *openapi3.T {
+	specOnce.Do(func() {
+		var err error
+		if spec, err = buildSpec(); err != nil {
+			panic(fmt.Sprintf("openapi: failed to build spec: %v", err))
+		}
+	})
+	return spec
+}
+
+func buildSpec() (*openapi3.T, error) {
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title:       "User management API",
+			Description: "Users CRUD, health probes and service administration.",
+			Version:     "1.0.0",
+		},
+		Paths:      openapi3.NewPaths(),
+		Components: &openapi3.Components{Schemas: make(openapi3.Schemas)},
+	}
+
+	generated := []struct {
+		name  string
+		value any
+	}{
+		{schemaCreateUserV1, dto.CreateUserV1{}},
+		{schemaUpdateUserV1, dto.UpdateUserV1{}},
+		{schemaUserV1, dto.UserV1{}},
+		{schemaCreateUserV2, dto.CreateUserV2{}},
+		{schemaUpdateUserV2, dto.UpdateUserV2{}},
+		{schemaUserV2, dto.UserV2{}},
+		{schemaCreatedV2, dto.CreatedV2{}},
+		{schemaBatchGet, dto.BatchGetUsersRequest{}},
+		{schemaBatchGetV1, dto.BatchGetUsersV1{}},
+		{schemaBatchGetV2, dto.BatchGetUsersV2{}},
+		{schemaSearchV1, dto.SearchUsersV1{}},
+		{schemaSearchV2, dto.SearchUsersV2{}},
+		{schemaUsername, dto.UsernameAvailability{}},
+		{schemaAttrSchemas, dto.AttributeSchemas{}},
+		{schemaGroupRequest, dto.GroupRequest{}},
+		{schemaGroup, dto.Group{}},
+		{schemaGroups, dto.Groups{}},
+		{schemaMembership, dto.MembershipRequest{}},
+		{schemaMembers, dto.GroupMembers{}},
+		{schemaUserGroups, dto.UserGroups{}},
+		{schemaAvatar, dto.AvatarUploaded{}},
+		{schemaHealthReport, health.Report{}},
+		{schemaChange, config.Change{}},
+	}
+	for _, g := range generated {
+		ref, err := openapi3gen.NewSchemaRefForValue(g.value, nil)
+		if err != nil {
+			return nil, fmt.Errorf("schema %s: %w", g.name, err)
+		}
+		doc.Components.Schemas[g.name] = ref
+	}
+	// generator shares one schema between fields of the same type, so enum gets its own copy
+	for _, name := range []string{
+		schemaCreateUserV1, schemaUpdateUserV1, schemaUserV1,
+		schemaCreateUserV2, schemaUpdateUserV2, schemaUserV2,
+	} {
+		doc.Components.Schemas[name].Value.Properties["gender"] = openapi3.NewSchemaRef("",
+			openapi3.NewStringSchema().WithEnum("F", "M", "O"))
+	}
+
+	roles := make([]any, 0, len(models.GroupRoles))
+	for _, role := range models.GroupRoles {
+		roles = append(roles, string(role))
+	}
+	doc.Components.Schemas[schemaMembership].Value.Properties["role"] = openapi3.NewSchemaRef("",
+		openapi3.NewStringSchema().WithEnum(roles...))
+	doc.Components.Schemas[schemaMembers].Value.Properties["members"].Value.Items.Value.Properties["role"] =
+		openapi3.NewSchemaRef("", openapi3.NewStringSchema().WithEnum(roles...))
+	doc.Components.Schemas[schemaUserGroups].Value.Properties["groups"].Value.Items.Value.Properties["role"] =
+		openapi3.NewSchemaRef("", openapi3.NewStringSchema().WithEnum(roles...))
+
+	// raw JSON is generated as array of bytes, attributes are free-form objects
+	doc.Components.Schemas[schemaAttrSchemas].Value.Properties["schemas"].Value.Items.Value.Properties["schema"] =
+		openapi3.NewSchemaRef("", attributeSchemaDocument())
+	for _, name := range []string{schemaCreateUserV2, schemaUpdateUserV2, schemaUserV2} {
+		attributes := openapi3.NewObjectSchema().WithAnyAdditionalProperties()
+		attributes.Description = "values of attributes registered in /attribute-schemas"
+		doc.Components.Schemas[name].Value.Properties["attributes"] = openapi3.NewSchemaRef("", attributes)
+	}
+
+	doc.Components.Schemas[schemaError] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+		WithProperty(models.ErrMsgKey, openapi3.NewStringSchema()).
+		WithProperty(models.ErrCodeKey, openapi3.NewStringSchema()).
+		WithRequired([]string{models.ErrMsgKey}))
+	doc.Components.Schemas[schemaMessage] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+		WithProperty("message", openapi3.NewStringSchema()).
+		WithRequired([]string{"message"}))
+
+	for _, op := range operations() {
+		doc.AddOperation(op.path, op.method, op.build())
+	}
+
+	// loading serialized document resolves references, validators need their targets
+	raw, err := doc.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	if doc, err = openapi3.NewLoader().LoadFromData(raw); err != nil {
+		return nil, err
+	}
+
+	return doc, doc.Validate(context.Background())
+}
+
+// operation - description of one route.
+type operation struct {
+	method  string
+	path    string
+	id      string
+	summary string
+	tag     string
+	userID  bool
+	tenant  bool
+	// feature - toggle of FEATURES_DISABLED the operation depends on
+	feature     string
+	parameters  openapi3.Parameters
+	deprecated  bool
+	requestBody *openapi3.RequestBody
+	responses   []response
+}
+
+// response - body is described either by contentType and schema or by content of several media types.
+type response struct {
+	status      int
+	description string
+	contentType string
+	schema      *openapi3.SchemaRef
+	content     openapi3.Content
+	headers     openapi3.Headers
+}
+
+func (op operation) build() *openapi3.Operation {
+	o := openapi3.NewOperation()
+	o.OperationID = op.id
+	o.Summary = op.summary
+	o.Tags = []string{op.tag}
+	o.Deprecated = op.deprecated
+	o.RequestBody = nil
+	if op.requestBody != nil {
+		o.RequestBody = &openapi3.RequestBodyRef{Value: op.requestBody}
+	}
+
+	if op.userID {
+		o.AddParameter(openapi3.NewPathParameter("id").
+			WithDescription("user id").
+			WithSchema(openapi3.NewInt64Schema()))
+	}
+	for _, p := range op.parameters {
+		o.AddParameter(p.Value)
+	}
+
+	responses := op.responses
+	if op.tenant {
+		o.AddParameter(openapi3.NewHeaderParameter("X-Tenant-ID").
+			WithDescription("tenant of the request, the header is set by TENANT_HEADER, " +
+				"with TENANT_JWT_KEY tenant is taken from bearer token and the header may only repeat it").
+			WithSchema(openapi3.NewStringSchema().WithPattern("^[a-z0-9][a-z0-9_-]{0,62}$")))
+		responses = append(responses, tenantErrors(responses)...)
+	}
+	if op.feature != "" && !slices.ContainsFunc(responses, func(r response) bool { return r.status == http.StatusNotFound }) {
+		responses = append(responses, errorResponse(http.StatusNotFound, "feature "+op.feature+" is disabled"))
+	}
+
+	for _, r := range responses {
+		resp := openapi3.NewResponse().WithDescription(r.description)
+		switch {
+		case r.content != nil:
+			resp.WithContent(r.content)
+		case r.schema != nil:
+			resp.WithContent(openapi3.NewContentWithSchemaRef(r.schema, []string{r.contentType}))
+		}
+		resp.Headers = make(openapi3.Headers)
+		for name, h := range r.headers {
+			resp.Headers[name] = h
+		}
+		if op.deprecated {
+			for name, h := range deprecationHeaders() {
+				resp.Headers[name] = h
+			}
+		}
+		o.AddResponse(r.status, resp)
+	}
+	return o
+}
+
+// tenantErrors - responses of tenant middleware, 400 is added unless the operation describes its own.
+func tenantErrors(responses []response) []response {
+	errs := []response{
+		errorResponse(http.StatusUnauthorized, "bearer token is missing while TENANT_JWT_KEY is set, invalid or has no tenant claim"),
+		errorResponse(http.StatusForbidden, "tenant header differs from tenant of token"),
+	}
+	for _, r := range responses {
+		if r.status == http.StatusBadRequest {
+			return errs
+		}
+	}
+	return append(errs, errorResponse(http.StatusBadRequest, "tenant is missing or invalid"))
+}
+
+func ref(name string) *openapi3.SchemaRef {
+	return openapi3.NewSchemaRef("#/components/schemas/"+name, nil)
+}
+
+func jsonResponse(status int, description, schema string) response {
+	return response{status: status, description: description, contentType: contentTypeJSON, schema: ref(schema)}
+}
+
+func errorResponse(status int, description string) response {
+	return jsonResponse(status, description, schemaError)
+}
+
+func operations() []operation {
+	tooManyRequests := errorResponse(http.StatusTooManyRequests, "rate limit is exceeded")
+
+	ops := []operation{
+		{
+			method: http.MethodGet, path: "/healthcheck", id: "healthcheck", tag: "health",
+			summary:   "Legacy liveness check",
+			responses: []response{jsonResponse(http.StatusOK, "service is alive", schemaMessage)},
+		},
+		{
+			method: http.MethodGet, path: "/livez", id: "live", tag: "health",
+			summary: "Liveness probe",
+			responses: []response{{
+				status: http.StatusOK, description: "process serves http", contentType: contentTypeJSON,
+				schema: openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+					WithProperty("status", openapi3.NewStringSchema())),
+			}},
+		},
+		{
+			method: http.MethodGet, path: "/readyz", id: "ready", tag: "health",
+			summary: "Readiness probe with status of every dependency",
+			responses: []response{
+				jsonResponse(http.StatusOK, "service is ready", schemaHealthReport),
+				jsonResponse(http.StatusServiceUnavailable, "a check failed or service is shutting down", schemaHealthReport),
+			},
+		},
+		{
+			method: http.MethodPost, path: "/admin/config/reload", id: "reloadConfig", tag: "admin",
+			summary: "Reload config and apply reloadable settings, requires bearer token ADMIN_TOKEN",
+			responses: []response{
+				{
+					status: http.StatusOK, description: "config is reloaded", contentType: contentTypeJSON,
+					schema: openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+						WithPropertyRef("changes", openapi3.NewSchemaRef("", openapi3.NewArraySchema().
+							WithItems(openapi3.NewSchema()))).
+						WithRequired([]string{"changes"})),
+				},
+				{
+					status: http.StatusUnprocessableEntity, description: "config is invalid, current one is kept",
+					contentType: contentTypeJSON,
+					schema: openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+						WithProperty(models.ErrMsgKey, openapi3.NewStringSchema()).
+						WithProperty("errors", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema()))),
+				},
+				errorResponse(http.StatusUnauthorized, "bearer token is missing or differs from ADMIN_TOKEN"),
+				errorResponse(http.StatusForbidden, "admin api is disabled, ADMIN_TOKEN is not set"),
+			},
+		},
+		{
+			method: http.MethodPost, path: "/graphql", id: "graphql", tag: "graphql", tenant: true,
+			feature: config.FeatureGraphQL,
+			summary: "Execute GraphQL query or mutation, see internal/handler/graphql/schema.graphql",
+			requestBody: openapi3.NewRequestBody().WithRequired(true).WithJSONSchema(openapi3.NewObjectSchema().
+				WithProperty("query", openapi3.NewStringSchema()).
+				WithProperty("operationName", openapi3.NewStringSchema()).
+				WithProperty("variables", openapi3.NewObjectSchema().WithAnyAdditionalProperties()).
+				WithRequired([]string{"query"})),
+			responses: []response{
+				{
+					status: http.StatusOK, description: "GraphQL response, errors carry extensions.code and extensions.status",
+					contentType: contentTypeJSON,
+					schema: openapi3.NewSchemaRef("", openapi3.NewObjectSchema().
+						WithProperty("data", openapi3.NewObjectSchema().WithNullable().WithAnyAdditionalProperties()).
+						WithProperty("errors", openapi3.NewArraySchema().WithItems(openapi3.NewObjectSchema()))),
+				},
+				errorResponse(http.StatusBadRequest, "request body is not valid JSON or tenant is missing or invalid"),
+				tooManyRequests,
+			},
+		},
+		{
+			method: http.MethodGet, path: "/openapi.json", id: "openapi", tag: "docs",
+			summary: "This document",
+			responses: []response{{
+				status: http.StatusOK, description: "OpenAPI document", contentType: contentTypeJSON,
+				schema: openapi3.NewSchemaRef("", openapi3.NewObjectSchema()),
+			}},
+		},
+	}
+
+	var scoped []operation
+	for _, group := range user_management.Groups {
+		scoped = append(scoped, userOperations(group)...)
+	}
+	scoped = append(scoped, attributeSchemaOperations(tooManyRequests)...)
+	scoped = append(scoped, groupOperations(tooManyRequests)...)
+	// users, attribute schemas and groups are scoped by tenant
+	for i := range scoped {
+		scoped[i].tenant = true
+	}
+	return append(ops, scoped...)
+}
+
+// attributeSchemaDocument - JSON Schema document, it is checked by the service.
+func attributeSchemaDocument() *openapi3.Schema {
+	schema := openapi3.NewObjectSchema().WithAnyAdditionalProperties()
+	schema.Description = "JSON Schema of attribute value, draft 2020-12 if $schema is not set"
+	return schema
+}
+
+// attributeSchemaOperations - registry of user attribute schemas.
+func attributeSchemaOperations(tooManyRequests response) []operation {
+	name := openapi3.Parameters{&openapi3.ParameterRef{Value: openapi3.NewPathParameter("name").
+		WithDescription("attribute name").
+		WithSchema(openapi3.NewStringSchema().WithPattern("^[a-z][a-z0-9_]{0,62}$"))}}
+	internalError := errorResponse(http.StatusInternalServerError, "unexpected error")
+
+	return []operation{
+		{
+			method: http.MethodGet, path: "/attribute-schemas", id: "listAttributeSchemas", tag: "attributes",
+			summary: "List schemas of user attributes",
+			responses: []response{
+				jsonResponse(http.StatusOK, "schemas ordered by name", schemaAttrSchemas),
+				tooManyRequests,
+				internalError,
+			},
+		},
+		{
+			method: http.MethodPut, path: "/attribute-schemas/{name}", id: "putAttributeSchema", tag: "attributes",
+			summary:     "Register schema of user attribute, existing one is replaced",
+			parameters:  name,
+			requestBody: openapi3.NewRequestBody().WithRequired(true).WithJSONSchema(attributeSchemaDocument()),
+			responses: []response{
+				jsonResponse(http.StatusOK, "schema is replaced", schemaMessage),
+				jsonResponse(http.StatusCreated, "schema is registered", schemaMessage),
+				errorResponse(http.StatusBadRequest, "invalid content type or schema"),
+				tooManyRequests,
+				internalError,
+			},
+		},
+		{
+			method: http.MethodDelete, path: "/attribute-schemas/{name}", id: "deleteAttributeSchema", tag: "attributes",
+			summary:    "Delete schema of user attribute",
+			parameters: name,
+			responses: []response{
+				jsonResponse(http.StatusOK, "schema is deleted", schemaMessage),
+				errorResponse(http.StatusNotFound, "schema does not exist"),
+				errorResponse(http.StatusConflict, "users, deleted ones included, have the attribute"),
+				tooManyRequests,
+				internalError,
+			},
+		},
+	}
+}
+
+// pageParameters - limit and offset of listings with next_offset in response.
+func pageParameters() openapi3.Parameters {
+	return openapi3.Parameters{
+		{Value: openapi3.NewQueryParameter("limit").
+			WithDescription("size of the page, 20 by default, values above 100 are lowered to it").
+			WithSchema(openapi3.NewIntegerSchema().WithMin(1))},
+		{Value: openapi3.NewQueryParameter("offset").
+			WithDescription("number of results to skip, next_offset of the previous page").
+			WithSchema(openapi3.NewIntegerSchema().WithMin(0))},
+	}
+}
+
+// groupOperations - groups of users and their members.
+func groupOperations(tooManyRequests response) []operation {
+	groupID := &openapi3.ParameterRef{Value: openapi3.NewPathParameter("id").
+		WithDescription("group id").
+		WithSchema(openapi3.NewInt64Schema())}
+	userID := &openapi3.ParameterRef{Value: openapi3.NewPathParameter("user_id").
+		WithDescription("user id").
+		WithSchema(openapi3.NewInt64Schema())}
+	groupBody := openapi3.NewRequestBody().WithRequired(true).WithJSONSchemaRef(ref(schemaGroupRequest))
+	groupDoesNotExist := errorResponse(http.StatusNotFound, "group does not exist")
+	nameIsTaken := errorResponse(http.StatusConflict, "group name is already taken")
+	internalError := errorResponse(http.StatusInternalServerError, "unexpected error")
+	bodyError := errorResponse(http.StatusInternalServerError, "request body can not be read or parsed, or unexpected error")
+
+	return []operation{
+		{
+			method: http.MethodPost, path: "/groups", id: "createGroup", tag: "groups",
+			summary:     "Create group",
+			requestBody: groupBody,
+			responses: []response{
+				jsonResponse(http.StatusCreated, "created group", schemaGroup),
+				errorResponse(http.StatusBadRequest, "invalid content type, name or description"),
+				nameIsTaken,
+				tooManyRequests,
+				bodyError,
+			},
+		},
+		{
+			method: http.MethodGet, path: "/groups", id: "listGroups", tag: "groups",
+			summary:    "List groups ordered by name",
+			parameters: pageParameters(),
+			responses: []response{
+				jsonResponse(http.StatusOK, "page of groups", schemaGroups),
+				errorResponse(http.StatusBadRequest, "invalid pagination"),
+				tooManyRequests,
+				internalError,
+			},
+		},
+		{
+			method: http.MethodGet, path: "/groups/{id}", id: "getGroup", tag: "groups",
+			summary:    "Get group",
+			parameters: openapi3.Parameters{groupID},
+			responses: []response{
+				jsonResponse(http.StatusOK, "group", schemaGroup),
+				errorResponse(http.StatusBadRequest, "invalid group id"),
+				groupDoesNotExist,
+				tooManyRequests,
+				internalError,
+			},
+		},
+		{
+			method: http.MethodPut, path: "/groups/{id}", id: "updateGroup", tag: "groups",
+			summary:     "Replace name and description of group",
+			parameters:  openapi3.Parameters{groupID},
+			requestBody: groupBody,
+			responses: []response{
+				jsonResponse(http.StatusOK, "group is updated", schemaMessage),
+				errorResponse(http.StatusBadRequest, "invalid group id, content type, name or description"),
+				groupDoesNotExist,
+				nameIsTaken,
+				tooManyRequests,
+				bodyError,
+			},
+		},
+		{
+			method: http.MethodDelete, path: "/groups/{id}", id: "deleteGroup", tag: "groups",
+			summary:    "Delete group with its memberships",
+			parameters: openapi3.Parameters{groupID},
+			responses: []response{
+				jsonResponse(http.StatusOK, "group is deleted", schemaMessage),
+				errorResponse(http.StatusBadRequest, "invalid group id"),
+				groupDoesNotExist,
+				tooManyRequests,
+				internalError,
+			},
+		},
+		{
+			method: http.MethodGet, path: "/groups/{id}/members", id: "listGroupMembers", tag: "groups",
+			summary:    "List active members of group ordered by user id",
+			parameters: append(openapi3.Parameters{groupID}, pageParameters()...),
+			responses: []response{
+				jsonResponse(http.StatusOK, "page of members, deleted users are hidden", schemaMembers),
+				errorResponse(http.StatusBadRequest, "invalid group id or pagination"),
+				groupDoesNotExist,
+				tooManyRequests,
+				internalError,
+			},
+		},
+		{
+			method: http.MethodPut, path: "/groups/{id}/members/{user_id}", id: "putGroupMember", tag: "groups",
+			summary:     "Add user to group or change its role there",
+			parameters:  openapi3.Parameters{groupID, userID},
+			requestBody: openapi3.NewRequestBody().WithRequired(true).WithJSONSchemaRef(ref(schemaMembership)),
+			responses: []response{
+				jsonResponse(http.StatusOK, "role of member is changed", schemaMessage),
+				jsonResponse(http.StatusCreated, "user is added to group", schemaMessage),
+				errorResponse(http.StatusBadRequest, "invalid ids, content type or role"),
+				errorResponse(http.StatusNotFound, "group or user does not exist"),
+				errorResponse(http.StatusGone, "user is deleted"),
+				tooManyRequests,
+				bodyError,
+			},
+		},
+		{
+			method: http.MethodDelete, path: "/groups/{id}/members/{user_id}", id: "deleteGroupMember", tag: "groups",
+			summary:    "Remove user from group",
+			parameters: openapi3.Parameters{groupID, userID},
+			responses: []response{
+				jsonResponse(http.StatusOK, "user is removed from group", schemaMessage),
+				errorResponse(http.StatusBadRequest, "invalid ids"),
+				errorResponse(http.StatusNotFound, "group does not exist or user is not its member"),
+				tooManyRequests,
+				internalError,
+			},
+		},
+	}
+}
+
+// userSchemas - schemas of request and response bodies of every version.
+var userSchemas = map[user_management.Version]struct{ create, update, user, batch, search string }{
+	user_management.V1: {
+		create: schemaCreateUserV1, update: schemaUpdateUserV1, user: schemaUserV1,
+		batch: schemaBatchGetV1, search: schemaSearchV1,
+	},
+	user_management.V2: {
+		create: schemaCreateUserV2, update: schemaUpdateUserV2, user: schemaUserV2,
+		batch: schemaBatchGetV2, search: schemaSearchV2,
+	},
+}
+
+// userOperations - users CRUD served under the group prefix in its versions.
+func userOperations(group user_management.Group) []operation {
+	// operations of unversioned aliases keep their original ids
+	idSuffix := ""
+	if len(group.Versions) == 1 {
+		idSuffix = strings.ToUpper(string(group.Versions[0]))
+	}
+	deprecated := group.Versions[0] == user_management.V1
+
+	createBody := openapi3.NewRequestBody().WithRequired(true).WithContent(versioned(group.Versions,
+		func(v user_management.Version) *openapi3.SchemaRef { return ref(userSchemas[v].create) }))
+	updateBody := openapi3.NewRequestBody().WithRequired(true).WithContent(versioned(group.Versions,
+		func(v user_management.Version) *openapi3.SchemaRef { return ref(userSchemas[v].update) }))
+	userResponse := response{
+		status: http.StatusOK, description: "user",
+		content: versioned(group.Versions,
+			func(v user_management.Version) *openapi3.SchemaRef { return ref(userSchemas[v].user) }),
+		headers: validatorHeaders(),
+	}
+	notModified := response{
+		status: http.StatusNotModified, description: "client copy of user is current",
+		headers: validatorHeaders(),
+	}
+
+	// v1 sends id of created user as plain text whatever is negotiated
+	created := openapi3.NewContent()
+	for i, v := range group.Versions {
+		if v == user_management.V1 {
+			created[contentTypeTextPlain] = openapi3.NewMediaType().WithSchema(openapi3.NewStringSchema())
+			continue
+		}
+		mediaType := openapi3.NewMediaType().WithSchemaRef(ref(schemaCreatedV2))
+		created[v.MediaType()] = mediaType
+		if i == 0 {
+			created[contentTypeJSON] = mediaType
+		}
+	}
+
+	// missing user is answered with 204, so there is no body even though handlers send one
+	userDoesNotExist := response{status: http.StatusNoContent, description: "user does not exist"}
+	invalidID := errorResponse(http.StatusBadRequest, "invalid user id")
+	notAcceptable := errorResponse(http.StatusNotAcceptable, "requested API version is not served by the route")
+	tooManyRequests := errorResponse(http.StatusTooManyRequests, "rate limit is exceeded")
+	emailDomainNotAllowed := errorResponse(http.StatusUnprocessableEntity, "email domain is not allowed by policy, error_code is "+models.ErrCodeEmailDomainNotAllowed)
+	internalError := errorResponse(http.StatusInternalServerError, "unexpected error")
+
+	return []operation{
+		{
+			method: http.MethodPost, path: group.Prefix + "/", id: "createUser" + idSuffix, tag: "users",
+			summary:     "Create user",
+			deprecated:  deprecated,
+			requestBody: createBody,
+			responses: []response{
+				{status: http.StatusCreated, description: "id of created user", content: created},
+				errorResponse(http.StatusBadRequest, "invalid content type or user fields"),
+				notAcceptable,
+				errorResponse(http.StatusConflict, "username or email is already taken"),
+				emailDomainNotAllowed,
+				tooManyRequests,
+				errorResponse(http.StatusInternalServerError, "request body can not be read or parsed, or unexpected error"),
+			},
+		},
+		{
+			method: http.MethodGet, path: group.Prefix + "/search", id: "searchUsers" + idSuffix, tag: "users",
+			summary: "Search active users by names, username and email, the most relevant first",
+			parameters: openapi3.Parameters{
+				{Value: openapi3.NewQueryParameter("q").
+					WithDescription("words to search, names match words starting with every one of them, " +
+						"username and email match similar text").
+					WithRequired(true).
+					WithSchema(openapi3.NewStringSchema().WithMinLength(1).WithMaxLength(200))},
+				pageParameters()[0],
+				pageParameters()[1],
+			},
+			deprecated: deprecated,
+			responses: []response{
+				{
+					status: http.StatusOK, description: "page of found users with highlighted matches",
+					content: versioned(group.Versions,
+						func(v user_management.Version) *openapi3.SchemaRef { return ref(userSchemas[v].search) }),
+				},
+				errorResponse(http.StatusBadRequest, "query has no letters or digits, or invalid pagination"),
+				notAcceptable,
+				tooManyRequests,
+				internalError,
+			},
+		},
+		{
+			method: http.MethodGet, path: group.Prefix + "/username-availability", id: "checkUsername" + idSuffix,
+			tag:     "users",
+			summary: "Check whether username is free for a new user, taken one gets available alternatives",
+			parameters: openapi3.Parameters{
+				{Value: openapi3.NewQueryParameter("username").
+					WithDescription("username to check, it is normalized as on creation").
+					WithRequired(true).
+					WithSchema(openapi3.NewStringSchema())},
+			},
+			deprecated: deprecated,
+			responses: []response{
+				{
+					status: http.StatusOK, description: "normalized username and its availability",
+					content: versioned(group.Versions,
+						func(user_management.Version) *openapi3.SchemaRef { return ref(schemaUsername) }),
+				},
+				errorResponse(http.StatusBadRequest, "username is empty"),
+				notAcceptable,
+				tooManyRequests,
+				internalError,
+			},
+		},
+		{
+			method: http.MethodGet, path: group.Prefix + "/{id}", id: "getUser" + idSuffix, tag: "users",
+			summary: "Get user",
+			userID:  true,
+			parameters: openapi3.Parameters{
+				{Value: openapi3.NewHeaderParameter("If-None-Match").
+					WithDescription("ETag of client copy, matching one is answered with 304").
+					WithSchema(openapi3.NewStringSchema())},
+				{Value: openapi3.NewHeaderParameter("If-Modified-Since").
+					WithDescription("Last-Modified of client copy, ignored with If-None-Match").
+					WithSchema(openapi3.NewStringSchema())},
+			},
+			deprecated: deprecated,
+			responses: []response{
+				userResponse,
+				notModified,
+				invalidID,
+				userDoesNotExist,
+				notAcceptable,
+				errorResponse(http.StatusGone, "user is deleted"),
+				tooManyRequests,
+				internalError,
+			},
+		},
+		{
+			method: http.MethodGet, path: group.Prefix + "/{id}/groups", id: "listUserGroups" + idSuffix, tag: "groups",
+			summary:    "List groups of user ordered by name, same in all versions",
+			userID:     true,
+			parameters: pageParameters(),
+			deprecated: deprecated,
+			responses: []response{
+				{
+					status: http.StatusOK, description: "page of groups with role of the user there",
+					content: versioned(group.Versions,
+						func(user_management.Version) *openapi3.SchemaRef { return ref(schemaUserGroups) }),
+				},
+				errorResponse(http.StatusBadRequest, "invalid user id or pagination"),
+				userDoesNotExist,
+				notAcceptable,
+				errorResponse(http.StatusGone, "user is deleted, its memberships are hidden until it is restored"),
+				tooManyRequests,
+				internalError,
+			},
+		},
+		{
+			method: http.MethodPut, path: group.Prefix + "/{id}", id: "updateUser" + idSuffix, tag: "users",
+			summary:     "Replace user fields",
+			userID:      true,
+			deprecated:  deprecated,
+			requestBody: updateBody,
+			responses: []response{
+				jsonResponse(http.StatusOK, "user is updated", schemaMessage),
+				errorResponse(http.StatusBadRequest, "invalid user id, content type or user fields"),
+				userDoesNotExist,
+				notAcceptable,
+				errorResponse(http.StatusConflict, "username or email is already taken"),
+				errorResponse(http.StatusGone, "user is deleted"),
+				emailDomainNotAllowed,
+				tooManyRequests,
+				errorResponse(http.StatusInternalServerError, "request body can not be read or parsed, or unexpected error"),
+			},
+		},
+		{
+			method: http.MethodDelete, path: group.Prefix + "/{id}", id: "deleteUser" + idSuffix, tag: "users",
+			summary:    "Mark user as deleted",
+			userID:     true,
+			deprecated: deprecated,
+			responses: []response{
+				jsonResponse(http.StatusOK, "user is deleted", schemaMessage),
+				invalidID,
+				userDoesNotExist,
+				notAcceptable,
+				errorResponse(http.StatusConflict, "user is already deleted"),
+				tooManyRequests,
+				internalError,
+			},
+		},
+		{
+			method: http.MethodPut, path: group.Prefix + "/{id}/avatar", id: "putAvatar" + idSuffix, tag: "avatars",
+			feature: config.FeatureAvatars,
+			summary: "Upload avatar as multipart form with file in field avatar or as raw image, " +
+				"type is sniffed from content, thumbnails of every size are made from the center square",
+			userID:      true,
+			deprecated:  deprecated,
+			requestBody: avatarBody(),
+			responses: []response{
+				jsonResponse(http.StatusOK, "avatar is stored", schemaAvatar),
+				errorResponse(http.StatusBadRequest, "invalid user id, malformed upload or image"),
+				userDoesNotExist,
+				notAcceptable,
+				errorResponse(http.StatusGone, "user is deleted"),
+				errorResponse(http.StatusRequestEntityTooLarge, "upload is larger than AVATAR_MAX_BYTES or image has more pixels than AVATAR_MAX_PIXELS"),
+				errorResponse(http.StatusUnsupportedMediaType, "image is not jpeg, png, gif or webp"),
+				tooManyRequests,
+				internalError,
+			},
+		},
+		{
+			method: http.MethodGet, path: group.Prefix + "/{id}/avatar", id: "getAvatar" + idSuffix, tag: "avatars",
+			feature: config.FeatureAvatars,
+			summary: "Get avatar as PNG thumbnail",
+			userID:  true,
+			parameters: openapi3.Parameters{
+				{Value: openapi3.NewQueryParameter("size").
+					WithDescription("edge of thumbnail: small is 64, medium is 128 and large is 256 pixels").
+					WithSchema(avatarSizeSchema())},
+				{Value: openapi3.NewQueryParameter("v").
+					WithDescription("version of avatar from avatar_url, it only makes the link unique").
+					WithSchema(openapi3.NewStringSchema())},
+			},
+			deprecated: deprecated,
+			responses: []response{
+				{
+					status: http.StatusOK, description: "avatar image",
+					content: openapi3.NewContentWithSchema(openapi3.NewStringSchema().WithFormat("binary"), []string{"image/png"}),
+					headers: openapi3.Headers{
+						"ETag":          header("version and size of avatar"),
+						"Last-Modified": header("time the avatar was stored"),
+						"Cache-Control": header("value configured for the route in CACHE_CONTROL"),
+					},
+				},
+				{status: http.StatusNotModified, description: "client copy of avatar is current"},
+				errorResponse(http.StatusBadRequest, "invalid user id or size"),
+				userDoesNotExist,
+				errorResponse(http.StatusNotFound, "user has no avatar"),
+				notAcceptable,
+				errorResponse(http.StatusGone, "user is deleted"),
+				tooManyRequests,
+				internalError,
+			},
+		},
+		{
+			method: http.MethodDelete, path: group.Prefix + "/{id}/avatar", id: "deleteAvatar" + idSuffix, tag: "avatars",
+			feature:    config.FeatureAvatars,
+			summary:    "Remove avatar",
+			userID:     true,
+			deprecated: deprecated,
+			responses: []response{
+				jsonResponse(http.StatusOK, "avatar is removed", schemaMessage),
+				invalidID,
+				userDoesNotExist,
+				errorResponse(http.StatusNotFound, "user has no avatar"),
+				notAcceptable,
+				errorResponse(http.StatusGone, "user is deleted"),
+				tooManyRequests,
+				internalError,
+			},
+		},
+		{
+			method: http.MethodGet, path: group.Prefix + "/{id}/export", id: "exportUser" + idSuffix, tag: "privacy",
+			summary: "Download ZIP archive of everything stored about user, deleted one included: " +
+				"manifest.json, user.json, history.json, groups.json and avatar/<size>.png",
+			userID:     true,
+			deprecated: deprecated,
+			responses: []response{
+				{
+					status: http.StatusOK, description: "data export archive",
+					content: openapi3.NewContentWithSchema(openapi3.NewStringSchema().WithFormat("binary"), []string{"application/zip"}),
+					headers: openapi3.Headers{
+						"Content-Disposition": header("attachment with file name user-<id>-export.zip"),
+						"Cache-Control":       header("value configured for the route in CACHE_CONTROL"),
+					},
+				},
+				invalidID,
+				userDoesNotExist,
+				notAcceptable,
+				errorResponse(http.StatusGone, "personal data of user is erased"),
+				tooManyRequests,
+				internalError,
+			},
+		},
+		{
+			method: http.MethodPost, path: group.Prefix + "/{id}/erase", id: "eraseUser" + idSuffix, tag: "privacy",
+			summary: "Erase personal data of active or deleted user irreversibly: fields are cleared, username is freed, " +
+				"memberships and avatar are removed and tombstone is recorded",
+			userID:     true,
+			deprecated: deprecated,
+			responses: []response{
+				jsonResponse(http.StatusOK, "user is erased", schemaMessage),
+				invalidID,
+				userDoesNotExist,
+				notAcceptable,
+				errorResponse(http.StatusGone, "personal data of user is already erased"),
+				tooManyRequests,
+				internalError,
+			},
+		},
+		{
+			method: http.MethodPost, path: group.Prefix + ":batchGet", id: "batchGetUsers" + idSuffix, tag: "users",
+			summary:    "Get users by ids at once, found users, missing and deleted ids are listed separately",
+			deprecated: deprecated,
+			requestBody: openapi3.NewRequestBody().WithRequired(true).WithContent(versioned(group.Versions,
+				func(user_management.Version) *openapi3.SchemaRef { return ref(schemaBatchGet) })),
+			responses: []response{
+				{
+					status: http.StatusOK, description: "users in request order",
+					content: versioned(group.Versions,
+						func(v user_management.Version) *openapi3.SchemaRef { return ref(userSchemas[v].batch) }),
+				},
+				errorResponse(http.StatusBadRequest, "invalid content type, empty or too many ids"),
+				notAcceptable,
+				tooManyRequests,
+				errorResponse(http.StatusInternalServerError, "request body can not be read or parsed, or unexpected error"),
+			},
+		},
+	}
+}
+
+// avatarBody - avatar upload, multipart form or raw image.
+func avatarBody() *openapi3.RequestBody {
+	binary := openapi3.NewStringSchema().WithFormat("binary")
+	content := openapi3.NewContentWithSchema(binary, []string{"image/*", "application/octet-stream"})
+	content["multipart/form-data"] = openapi3.NewMediaType().WithSchema(openapi3.NewObjectSchema().
+		WithProperty("avatar", binary).
+		WithRequired([]string{"avatar"}))
+	return openapi3.NewRequestBody().WithRequired(true).WithContent(content)
+}
+
+// avatarSizeSchema - names of avatar sizes.
+func avatarSizeSchema() *openapi3.Schema {
+	sizes := make([]any, 0, len(models.AvatarSizes))
+	for _, size := range models.AvatarSizes {
+		sizes = append(sizes, string(size))
+	}
+	schema := openapi3.NewStringSchema().WithEnum(sizes...)
+	schema.Default = string(models.AvatarLarge)
+	return schema
+}
+
+// versioned - body in every version served by a route: plain JSON is the default version,
+// vendor media types select the others.
+func versioned(versions []user_management.Version, schema func(v user_management.Version) *openapi3.SchemaRef) openapi3.Content {
+	content := openapi3.NewContentWithJSONSchemaRef(schema(versions[0]))
+	for _, v := range versions {
+		content[v.MediaType()] = openapi3.NewMediaType().WithSchemaRef(schema(v))
+	}
+	return content
+}
+
+// validatorHeaders - headers of cacheable user responses.
+func validatorHeaders() openapi3.Headers {
+	return openapi3.Headers{
+		"ETag":          header("validator of the representation, changes with every write of user"),
+		"Last-Modified": header("time of creation or of the last update of user"),
+		"Cache-Control": header("value configured for the route in CACHE_CONTROL"),
+	}
+}
+
+// deprecationHeaders - headers sent by deprecated API version, see user_management.Handler.UseVersion.
+func deprecationHeaders() openapi3.Headers {
+	return openapi3.Headers{
+		"Deprecation": header("date since the version is deprecated, e.g. @1792281600"),
+		"Sunset":      header("date after which the version may be removed, sent once it is planned"),
+		"Link":        header("successor version of the route"),
+	}
+}
+
+func header(description string) *openapi3.HeaderRef {
+	return &openapi3.HeaderRef{Value: &openapi3.Header{Parameter: openapi3.Parameter{
+		Description: description,
+		Schema:      openapi3.NewSchemaRef("", openapi3.NewStringSchema()),
+	}}}
+}
@@ -3,51 +3,168 @@ package handler
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/sonikq/gravitum_test_task/internal/config"
+	"github.com/sonikq/gravitum_test_task/internal/handler/admin"
+	"github.com/sonikq/gravitum_test_task/internal/handler/graphql"
+	"github.com/sonikq/gravitum_test_task/internal/handler/health"
+	"github.com/sonikq/gravitum_test_task/internal/handler/openapi"
 	"github.com/sonikq/gravitum_test_task/internal/handler/user_management"
 	"github.com/sonikq/gravitum_test_task/internal/server/middleware"
 	"github.com/sonikq/gravitum_test_task/internal/service"
+	healthcheck "github.com/sonikq/gravitum_test_task/pkg/health"
 	"github.com/sonikq/gravitum_test_task/pkg/logger"
+	"github.com/sonikq/gravitum_test_task/pkg/tenant"
 	"net/http"
 )
 
+const swaggerPrefix = "/swagger"
+
 type Handler struct {
 	UserManagement *user_management.Handler
+	Admin          *admin.Handler
+	Health         *health.Handler
+	GraphQL        *graphql.Handler
+	OpenAPI        *openapi.Handler
 }
 
 type Option struct {
-	Conf    config.Config
-	Logger  *logger.Logger
-	Service *service.Service
+	Conf         *config.Store
+	Logger       *logger.Logger
+	Service      *service.Service
+	ReloadConfig func() ([]config.Change, error)
+	Health       *healthcheck.Registry
 }
 
 func NewRouter(option Option) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
+	// handlers pass gin.Context as context, values of request context such as tenant must be visible through it
+	router.ContextWithFallback = true
 	router.Use(gin.Recovery())
 	router.Use(middleware.RequestResponseLogger(option.Logger))
 
-	h := Handler{UserManagement: user_management.New(&user_management.HandlerConfig{
-		Config:  option.Conf,
-		Logger:  option.Logger,
-		Service: option.Service,
-	}),
+	// mode is not reloadable, spec is checked by tests, so broken one fails at startup
+	validator, err := middleware.OpenAPIValidator(openapi.Spec(), option.Conf.Current().OpenAPIValidation, option.Logger)
+	if err != nil {
+		panic("openapi validator: " + err.Error())
+	}
+	router.Use(validator)
+
+	rateLimiter := middleware.NewRateLimiter(0, 0)
+	option.Conf.Subscribe(func(cfg config.Config) {
+		rateLimiter.SetLimit(cfg.RateLimitRPS, cfg.RateLimitBurst)
+	})
+
+	adminAuth := middleware.NewAdminAuth("")
+	option.Conf.Subscribe(func(cfg config.Config) {
+		adminAuth.SetToken(cfg.AdminToken)
+	})
+
+	features := middleware.NewFeatures(nil)
+	option.Conf.Subscribe(func(cfg config.Config) {
+		// value is checked on config load
+		disabled, _ := cfg.DisabledFeatures()
+		features.SetDisabled(disabled)
+	})
+
+	tenantResolver := middleware.NewTenant(tenant.Resolver{})
+	option.Conf.Subscribe(func(cfg config.Config) {
+		tenantResolver.SetResolver(cfg.TenantResolver())
+	})
+
+	// GET routes are keyed by their path without version prefix
+	cacheControl := middleware.NewCacheControl(nil)
+	option.Conf.Subscribe(func(cfg config.Config) {
+		// value is checked on config load
+		policies, _ := config.ParseCacheControl(cfg.CacheControl)
+		cacheControl.SetPolicies(policies)
+	})
+
+	h := Handler{
+		UserManagement: user_management.New(&user_management.HandlerConfig{
+			Config:  option.Conf,
+			Logger:  option.Logger,
+			Service: option.Service,
+		}),
+		Admin: admin.New(&admin.HandlerConfig{
+			Logger:       option.Logger,
+			ReloadConfig: option.ReloadConfig,
+		}),
+		Health: health.New(&health.HandlerConfig{
+			Logger:   option.Logger,
+			Registry: option.Health,
+		}),
+		GraphQL: graphql.New(&graphql.HandlerConfig{
+			Config:  option.Conf,
+			Logger:  option.Logger,
+			Service: option.Service,
+		}),
+		OpenAPI: openapi.New(swaggerPrefix),
 	}
 
-	router.GET("/healthcheck", func(ctx *gin.Context) {
+	router.GET("/healthcheck", cacheControl.Handler("/healthcheck"), func(ctx *gin.Context) {
 		ctx.JSON(http.StatusOK, gin.H{
 			"message": "I am alive!",
 		})
 	})
+	router.GET("/livez", cacheControl.Handler("/livez"), h.Health.Live)
+	router.GET("/readyz", cacheControl.Handler("/readyz"), h.Health.Ready)
+
+	adminGroup := router.Group("/admin", adminAuth.Handler())
+	{
+		adminGroup.POST("/config/reload", h.Admin.ReloadConfig)
+	}
+
+	// Tenant scoped routes
+	for _, g := range user_management.Groups {
+		middlewares := []gin.HandlerFunc{
+			rateLimiter.Handler(), tenantResolver.Handler(), h.UserManagement.UseVersion(g.Versions...),
+		}
+
+		userGroup := router.Group(g.Prefix, middlewares...)
+		{
+			userGroup.POST("/", h.UserManagement.CreateUser)
+			userGroup.GET("/search", cacheControl.Handler("/users/search"), h.UserManagement.SearchUsers)
+			userGroup.GET("/username-availability", cacheControl.Handler("/users/username-availability"),
+				h.UserManagement.CheckUsername)
+			userGroup.GET("/:id", cacheControl.Handler("/users/:id"), h.UserManagement.GetUser)
+			userGroup.GET("/:id/groups", cacheControl.Handler("/users/:id/groups"), h.UserManagement.ListUserGroups)
+			userGroup.PUT("/:id", h.UserManagement.UpdateUser)
+			userGroup.DELETE("/:id", h.UserManagement.DeleteUser)
+			avatars := features.Require(config.FeatureAvatars)
+			userGroup.PUT("/:id/avatar", avatars, h.UserManagement.PutAvatar)
+			userGroup.GET("/:id/avatar", avatars, cacheControl.Handler("/users/:id/avatar"), h.UserManagement.GetAvatar)
+			userGroup.DELETE("/:id/avatar", avatars, h.UserManagement.DeleteAvatar)
+			userGroup.GET("/:id/export", cacheControl.Handler("/users/:id/export"), h.UserManagement.ExportUser)
+			userGroup.POST("/:id/erase", h.UserManagement.EraseUser)
+		}
 
-	// Authorized routes
-	userGroup := router.Group("/users")
+		// custom methods, e.g. POST /users:batchGet
+		router.POST(g.Prefix+":method", append(middlewares, h.UserManagement.CustomMethod)...)
+	}
+
+	attributeSchemaGroup := router.Group("/attribute-schemas", rateLimiter.Handler(), tenantResolver.Handler())
 	{
-		userGroup.POST("/", h.UserManagement.CreateUser)
-		userGroup.GET("/:id", h.UserManagement.GetUser)
-		userGroup.PUT("/:id", h.UserManagement.UpdateUser)
-		userGroup.DELETE("/:id", h.UserManagement.DeleteUser)
+		attributeSchemaGroup.GET("", cacheControl.Handler("/attribute-schemas"), h.UserManagement.ListAttributeSchemas)
+		attributeSchemaGroup.PUT("/:name", h.UserManagement.PutAttributeSchema)
+		attributeSchemaGroup.DELETE("/:name", h.UserManagement.DeleteAttributeSchema)
+	}
 
+	groupsGroup := router.Group("/groups", rateLimiter.Handler(), tenantResolver.Handler())
+	{
+		groupsGroup.POST("", h.UserManagement.CreateGroup)
+		groupsGroup.GET("", cacheControl.Handler("/groups"), h.UserManagement.ListGroups)
+		groupsGroup.GET("/:id", cacheControl.Handler("/groups/:id"), h.UserManagement.GetGroup)
+		groupsGroup.PUT("/:id", h.UserManagement.UpdateGroup)
+		groupsGroup.DELETE("/:id", h.UserManagement.DeleteGroup)
+		groupsGroup.GET("/:id/members", cacheControl.Handler("/groups/:id/members"), h.UserManagement.ListGroupMembers)
+		groupsGroup.PUT("/:id/members/:user_id", h.UserManagement.PutGroupMember)
+		groupsGroup.DELETE("/:id/members/:user_id", h.UserManagement.DeleteGroupMember)
 	}
 
+	router.POST("/graphql", features.Require(config.FeatureGraphQL), rateLimiter.Handler(), tenantResolver.Handler(), h.GraphQL.Query)
+
+	router.GET("/openapi.json", cacheControl.Handler("/openapi.json"), h.OpenAPI.Document)
+	router.GET(swaggerPrefix+"/*any", features.Require(config.FeatureSwagger), cacheControl.Handler(swaggerPrefix+"/*any"), h.OpenAPI.SwaggerUI)
+
 	return router
 }
@@ -0,0 +1,917 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sonikq/gravitum_test_task/internal/config"
+	"github.com/sonikq/gravitum_test_task/internal/handler/openapi"
+	"github.com/sonikq/gravitum_test_task/internal/handler/user_management"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"github.com/sonikq/gravitum_test_task/internal/service"
+	"github.com/sonikq/gravitum_test_task/internal/service/servicetest"
+	healthcheck "github.com/sonikq/gravitum_test_task/pkg/health"
+	"github.com/sonikq/gravitum_test_task/pkg/logger"
+	"github.com/sonikq/gravitum_test_task/pkg/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testUser = models.UserInfo{
+	ID:        1,
+	Username:  "jdoe",
+	FirstName: "John",
+	LastName:  "Doe",
+	Email:     "jdoe@example.com",
+	Gender:    "M",
+	Age:       30,
+	CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+}
+
+const testUserBody = `{"username":"jdoe","first_name":"John","last_name":"Doe",` +
+	`"email":"jdoe@example.com","gender":"M","age":30}`
+
+const testUserBodyV2 = `{"username":"jdoe","name":{"first":"John","last":"Doe"},` +
+	`"email":"jdoe@example.com","gender":"M","age":30}`
+
+const testAdminToken = "admin-token"
+
+// newTestRouter creates router with strict OpenAPI validation over the mocked service
+func newTestRouter(t *testing.T, svc *servicetest.MockService) http.Handler {
+	t.Helper()
+
+	lg, err := logger.NewLogger("test", logger.Option{Level: "disabled"})
+	require.NoError(t, err)
+
+	cfg := config.Default()
+	cfg.OpenAPIValidation = config.ValidationStrict
+	cfg.AdminToken = testAdminToken
+
+	return NewRouter(Option{
+		Conf:    config.NewStore(cfg, config.Load),
+		Logger:  lg,
+		Service: &service.Service{IUserManagementService: svc},
+		ReloadConfig: func() ([]config.Change, error) {
+			return []config.Change{{Key: "log_level", Old: "info", New: "debug", Reloadable: true}}, nil
+		},
+		Health: healthcheck.NewRegistry(),
+	})
+}
+
+func serve(router http.Handler, method, target, contentType, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestSpec_CoversRoutes tests that the document describes exactly the routes of the router
+func TestSpec_CoversRoutes(t *testing.T) {
+	router := NewRouter(Option{
+		Conf:    config.NewStore(config.Default(), config.Load),
+		Logger:  &logger.Logger{},
+		Service: &service.Service{IUserManagementService: &servicetest.MockService{}},
+		Health:  healthcheck.NewRegistry(),
+	})
+
+	doc := openapi.Spec()
+	require.NoError(t, doc.Validate(context.Background()))
+
+	routes := make(map[string]bool)
+	for _, route := range router.Routes() {
+		if strings.HasPrefix(route.Path, swaggerPrefix) {
+			continue
+		}
+		paths := []string{strings.NewReplacer(":id", "{id}", ":name", "{name}", ":user_id", "{user_id}").Replace(route.Path)}
+		if prefix, ok := strings.CutSuffix(route.Path, ":method"); ok {
+			paths = paths[:0]
+			for _, method := range user_management.CustomMethods {
+				paths = append(paths, prefix+":"+method)
+			}
+		}
+
+		for _, path := range paths {
+			routes[route.Method+" "+path] = true
+
+			item := doc.Paths.Find(path)
+			require.NotNil(t, item, "route %s %s is missing in spec", route.Method, path)
+			assert.NotNil(t, item.GetOperation(route.Method), "route %s %s is missing in spec", route.Method, path)
+		}
+	}
+
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			assert.True(t, routes[method+" "+path], "spec operation %s %s has no route", method, path)
+		}
+	}
+}
+
+// TestRouter_StrictValidation tests that handlers answer as the document describes
+func TestRouter_StrictValidation(t *testing.T) {
+	svc := &servicetest.MockService{}
+	svc.On("CreateUser", mock.Anything).Return("1", nil)
+	svc.On("GetUser", int64(1)).Return(&testUser, nil)
+	svc.On("GetUser", int64(2)).Return(nil, models.ErrUserDoesNotExist)
+	svc.On("GetUser", int64(3)).Return(nil, models.ErrUserIsGone)
+	svc.On("UpdateUser", mock.MatchedBy(func(user models.UserInfo) bool { return user.ID == 4 })).
+		Return(models.ErrEmailIsAlreadyTaken)
+	svc.On("UpdateUser", mock.MatchedBy(func(user models.UserInfo) bool { return user.ID == 5 })).
+		Return(fmt.Errorf("%w: denied", models.ErrEmailDomainNotAllowed))
+	svc.On("UpdateUser", mock.Anything).Return(nil)
+	svc.On("DeleteUser", int64(1)).Return(nil)
+	svc.On("DeleteUser", int64(3)).Return(models.ErrDeleteDeletedUser)
+	svc.On("GetUsers", []int64{1}).Return(map[int64]*models.UserInfo{1: &testUser}, nil)
+	svc.On("ListAttributeSchemas").Return([]models.AttributeSchema{
+		{Name: "locale", Schema: []byte(`{"enum":["en","ru"]}`), CreatedAt: testUser.CreatedAt},
+	}, nil)
+	svc.On("PutAttributeSchema", models.AttributeSchema{Name: "locale", Schema: []byte(`{"enum":["en","ru"]}`)}).
+		Return(true, nil)
+	svc.On("PutAttributeSchema", mock.Anything).
+		Return(false, fmt.Errorf("%w: invalid attribute schema: jsonschema: ...", models.ErrInvalidAttributeSchema))
+	svc.On("DeleteAttributeSchema", "locale").Return(models.ErrAttributeSchemaIsInUse)
+	svc.On("DeleteAttributeSchema", "phone").Return(models.ErrAttributeSchemaDoesNotExist)
+	svc.On("DeleteAttributeSchema", mock.Anything).Return(nil)
+
+	router := newTestRouter(t, svc)
+
+	testCases := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		headers     []string
+		expected    int
+	}{
+		{name: "Healthcheck", method: http.MethodGet, target: "/healthcheck", expected: http.StatusOK},
+		{name: "Liveness", method: http.MethodGet, target: "/livez", expected: http.StatusOK},
+		{name: "Readiness", method: http.MethodGet, target: "/readyz", expected: http.StatusOK},
+		{
+			name: "Reload config", method: http.MethodPost, target: "/admin/config/reload",
+			headers: []string{"Authorization", "Bearer " + testAdminToken}, expected: http.StatusOK,
+		},
+		{name: "Reload config without token", method: http.MethodPost, target: "/admin/config/reload", expected: http.StatusUnauthorized},
+		{
+			name: "Create user", method: http.MethodPost, target: "/users/",
+			contentType: "application/json", body: testUserBody, expected: http.StatusCreated,
+		},
+		{name: "Get user", method: http.MethodGet, target: "/users/1", expected: http.StatusOK},
+		{name: "Get missing user", method: http.MethodGet, target: "/users/2", expected: http.StatusNoContent},
+		{name: "Get deleted user", method: http.MethodGet, target: "/users/3", expected: http.StatusGone},
+		{
+			name: "Update user", method: http.MethodPut, target: "/users/1",
+			contentType: "application/json", body: testUserBody, expected: http.StatusOK,
+		},
+		{
+			name: "Update user with taken email", method: http.MethodPut, target: "/users/4",
+			contentType: "application/json", body: testUserBody, expected: http.StatusConflict,
+		},
+		{
+			name: "Update user with email domain out of policy", method: http.MethodPut, target: "/users/5",
+			contentType: "application/json", body: testUserBody, expected: http.StatusUnprocessableEntity,
+		},
+		{name: "Delete user", method: http.MethodDelete, target: "/users/1", expected: http.StatusOK},
+		{name: "Delete deleted user", method: http.MethodDelete, target: "/users/3", expected: http.StatusConflict},
+		{
+			name: "Create user v1", method: http.MethodPost, target: "/v1/users/",
+			contentType: "application/json", body: testUserBody, expected: http.StatusCreated,
+		},
+		{
+			name: "Create user v2", method: http.MethodPost, target: "/v2/users/",
+			contentType: "application/json", body: testUserBodyV2, expected: http.StatusCreated,
+		},
+		{
+			name: "Create user v2 by media type", method: http.MethodPost, target: "/users/",
+			contentType: user_management.V2.MediaType(), body: testUserBodyV2, expected: http.StatusCreated,
+		},
+		{name: "Get user v2", method: http.MethodGet, target: "/v2/users/1", expected: http.StatusOK},
+		{
+			name: "Update user v2", method: http.MethodPut, target: "/v2/users/1",
+			contentType: "application/json", body: testUserBodyV2, expected: http.StatusOK,
+		},
+		{name: "Delete user v2", method: http.MethodDelete, target: "/v2/users/1", expected: http.StatusOK},
+		{name: "List attribute schemas", method: http.MethodGet, target: "/attribute-schemas", expected: http.StatusOK},
+		{
+			name: "Register attribute schema", method: http.MethodPut, target: "/attribute-schemas/locale",
+			contentType: "application/json", body: `{"enum":["en","ru"]}`, expected: http.StatusCreated,
+		},
+		{
+			name: "Register invalid attribute schema", method: http.MethodPut, target: "/attribute-schemas/office",
+			contentType: "application/json", body: `{"type":"room"}`, expected: http.StatusBadRequest,
+		},
+		{
+			name: "Register schema of invalid name", method: http.MethodPut, target: "/attribute-schemas/Office",
+			contentType: "application/json", body: `{"type":"string"}`, expected: http.StatusBadRequest,
+		},
+		{name: "Delete attribute schema", method: http.MethodDelete, target: "/attribute-schemas/floor", expected: http.StatusOK},
+		{name: "Delete attribute schema in use", method: http.MethodDelete, target: "/attribute-schemas/locale", expected: http.StatusConflict},
+		{name: "Delete missing attribute schema", method: http.MethodDelete, target: "/attribute-schemas/phone", expected: http.StatusNotFound},
+		{
+			name: "GraphQL", method: http.MethodPost, target: "/graphql",
+			contentType: "application/json", body: `{"query":"{ user(id: 1) { id username } }"}`,
+			expected: http.StatusOK,
+		},
+		{name: "Document", method: http.MethodGet, target: "/openapi.json", expected: http.StatusOK},
+		{name: "Swagger UI", method: http.MethodGet, target: "/swagger/", expected: http.StatusOK},
+		{name: "Swagger UI asset", method: http.MethodGet, target: "/swagger/swagger-ui.css", expected: http.StatusOK},
+		{name: "Unknown route", method: http.MethodGet, target: "/unknown", expected: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serve(router, tc.method, tc.target, tc.contentType, tc.body, tc.headers...)
+			assert.Equal(t, tc.expected, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+// TestRouter_StrictValidationRejects tests that requests and responses violating the document are replaced
+func TestRouter_StrictValidationRejects(t *testing.T) {
+	invalid := testUser
+	invalid.Gender = "X"
+
+	svc := &servicetest.MockService{}
+	svc.On("GetUser", int64(4)).Return(&invalid, nil)
+
+	router := newTestRouter(t, svc)
+
+	t.Run("Invalid path parameter", func(t *testing.T) {
+		rec := serve(router, http.MethodGet, "/users/abc", "", "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), models.ErrMsgKey)
+	})
+
+	t.Run("Invalid body", func(t *testing.T) {
+		body := strings.Replace(testUserBody, `"gender":"M"`, `"gender":"X"`, 1)
+		rec := serve(router, http.MethodPost, "/users/", "application/json", body)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "gender")
+	})
+
+	t.Run("Invalid content type", func(t *testing.T) {
+		rec := serve(router, http.MethodPost, "/users/", "text/plain", testUserBody)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Invalid response", func(t *testing.T) {
+		rec := serve(router, http.MethodGet, "/users/4", "", "")
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.NotContains(t, rec.Body.String(), "jdoe")
+	})
+
+	svc.AssertNotCalled(t, "CreateUser", mock.Anything)
+}
+
+// TestRouter_Versions tests representation and headers of every API version
+func TestRouter_Versions(t *testing.T) {
+	svc := &servicetest.MockService{}
+	svc.On("GetUser", int64(1)).Return(&testUser, nil)
+
+	router := newTestRouter(t, svc)
+
+	t.Run("Unversioned route is v1", func(t *testing.T) {
+		rec := serve(router, http.MethodGet, "/users/1", "", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"id":1,"username":"jdoe","first_name":"John","last_name":"Doe",`+
+			`"email":"jdoe@example.com","gender":"M","age":30,"created_at":"2026-01-02T03:04:05Z"}`, rec.Body.String())
+		assert.Empty(t, rec.Header().Get("Deprecation"), "v1 is not deprecated until the date is set")
+		assert.Empty(t, rec.Header().Get("Link"))
+		assert.Equal(t, "Accept, Content-Type", rec.Header().Get("Vary"))
+	})
+
+	t.Run("v2", func(t *testing.T) {
+		rec := serve(router, http.MethodGet, "/v2/users/1", "", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"id":1,"username":"jdoe","name":{"first":"John","last":"Doe"},`+
+			`"email":"jdoe@example.com","gender":"M","age":30,"attributes":{},"created_at":"2026-01-02T03:04:05Z"}`, rec.Body.String())
+		assert.Empty(t, rec.Header().Get("Deprecation"))
+	})
+
+	t.Run("Negotiated v2", func(t *testing.T) {
+		rec := serve(router, http.MethodGet, "/users/1", "", "", "Accept", user_management.V2.MediaType())
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, user_management.V2.MediaType(), rec.Header().Get("Content-Type"))
+		assert.Contains(t, rec.Body.String(), `"name":{"first":"John","last":"Doe"}`)
+		assert.Empty(t, rec.Header().Get("Deprecation"))
+	})
+
+	t.Run("Version not served by route", func(t *testing.T) {
+		rec := serve(router, http.MethodGet, "/v1/users/1", "", "", "Accept", user_management.V2.MediaType())
+		assert.Equal(t, http.StatusNotAcceptable, rec.Code)
+	})
+
+	t.Run("Deprecated", func(t *testing.T) {
+		lg, err := logger.NewLogger("test", logger.Option{Level: "disabled"})
+		require.NoError(t, err)
+
+		cfg := config.Default()
+		cfg.APIV1DeprecatedAt = "2026-10-18"
+		router := NewRouter(Option{
+			Conf:    config.NewStore(cfg, config.Load),
+			Logger:  lg,
+			Service: &service.Service{IUserManagementService: svc},
+			Health:  healthcheck.NewRegistry(),
+		})
+
+		rec := serve(router, http.MethodGet, "/users/1", "", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "@1792281600", rec.Header().Get("Deprecation"))
+		assert.Empty(t, rec.Header().Get("Sunset"))
+		assert.Equal(t, `</v2/users/1>; rel="successor-version"`, rec.Header().Get("Link"))
+	})
+
+	t.Run("Sunset", func(t *testing.T) {
+		lg, err := logger.NewLogger("test", logger.Option{Level: "disabled"})
+		require.NoError(t, err)
+
+		cfg := config.Default()
+		cfg.APIV1DeprecatedAt = "2026-10-18"
+		cfg.APIV1Sunset = "2027-04-01"
+		router := NewRouter(Option{
+			Conf:    config.NewStore(cfg, config.Load),
+			Logger:  lg,
+			Service: &service.Service{IUserManagementService: svc},
+			Health:  healthcheck.NewRegistry(),
+		})
+
+		rec := serve(router, http.MethodGet, "/v1/users/1", "", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "Thu, 01 Apr 2027 00:00:00 GMT", rec.Header().Get("Sunset"))
+		assert.Equal(t, `</v2/users/1>; rel="successor-version"`, rec.Header().Get("Link"))
+	})
+}
+
+// TestRouter_ConditionalGet tests ETag and Last-Modified validation of user responses
+func TestRouter_ConditionalGet(t *testing.T) {
+	svc := &servicetest.MockService{}
+	svc.On("GetUser", int64(1)).Return(&testUser, nil)
+	svc.On("GetUser", int64(3)).Return(nil, models.ErrUserIsGone)
+
+	router := newTestRouter(t, svc)
+
+	rec := serve(router, http.MethodGet, "/v2/users/1", "", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Equal(t, "Fri, 02 Jan 2026 03:04:05 GMT", rec.Header().Get("Last-Modified"))
+	assert.Equal(t, "private, no-cache", rec.Header().Get("Cache-Control"))
+
+	t.Run("Matching ETag", func(t *testing.T) {
+		rec := serve(router, http.MethodGet, "/v2/users/1", "", "", "If-None-Match", `"other", W/`+etag)
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Empty(t, rec.Body.String())
+		assert.Equal(t, etag, rec.Header().Get("ETag"))
+		assert.Equal(t, "private, no-cache", rec.Header().Get("Cache-Control"))
+	})
+
+	t.Run("Changed ETag takes precedence over date", func(t *testing.T) {
+		rec := serve(router, http.MethodGet, "/v2/users/1", "", "",
+			"If-None-Match", `"other"`, "If-Modified-Since", "Fri, 02 Jan 2026 03:04:05 GMT")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("Not modified since", func(t *testing.T) {
+		rec := serve(router, http.MethodGet, "/v2/users/1", "", "", "If-Modified-Since", "Fri, 02 Jan 2026 03:04:05 GMT")
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+	})
+
+	t.Run("Modified since", func(t *testing.T) {
+		rec := serve(router, http.MethodGet, "/v2/users/1", "", "", "If-Modified-Since", "Fri, 02 Jan 2026 03:04:04 GMT")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("ETag depends on version", func(t *testing.T) {
+		rec := serve(router, http.MethodGet, "/v1/users/1", "", "", "If-None-Match", etag)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+	})
+
+	t.Run("Errors are not cached", func(t *testing.T) {
+		rec := serve(router, http.MethodGet, "/v2/users/3", "", "")
+		assert.Equal(t, http.StatusGone, rec.Code)
+		assert.Empty(t, rec.Header().Get("Cache-Control"))
+	})
+}
+
+// TestRouter_BatchGet tests partition of requested ids into found, missing and deleted users
+func TestRouter_BatchGet(t *testing.T) {
+	deletedAt := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	deleted := testUser
+	deleted.ID, deleted.EndDate = 3, &deletedAt
+
+	svc := &servicetest.MockService{}
+	svc.On("GetUsers", []int64{2, 1, 3}).
+		Return(map[int64]*models.UserInfo{1: &testUser, 3: &deleted}, nil)
+
+	router := newTestRouter(t, svc)
+
+	t.Run("v1", func(t *testing.T) {
+		rec := serve(router, http.MethodPost, "/users:batchGet", "application/json", `{"ids":[2,1,3,1]}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.JSONEq(t, `{"users":[{"id":1,"username":"jdoe","first_name":"John","last_name":"Doe",`+
+			`"email":"jdoe@example.com","gender":"M","age":30,"created_at":"2026-01-02T03:04:05Z"}],`+
+			`"missing_ids":[2],"deleted_ids":[3]}`, rec.Body.String())
+	})
+
+	t.Run("v2", func(t *testing.T) {
+		rec := serve(router, http.MethodPost, "/v2/users:batchGet", "application/json", `{"ids":[2,1,3]}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Contains(t, rec.Body.String(), `"name":{"first":"John","last":"Doe"}`)
+	})
+
+	t.Run("Empty ids", func(t *testing.T) {
+		rec := serve(router, http.MethodPost, "/v1/users:batchGet", "application/json", `{"ids":[]}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "ids must not be empty")
+	})
+
+	t.Run("Too many ids", func(t *testing.T) {
+		ids := make([]string, 0, 101)
+		for i := 1; i <= 101; i++ {
+			ids = append(ids, strconv.Itoa(i))
+		}
+		rec := serve(router, http.MethodPost, "/v1/users:batchGet", "application/json",
+			`{"ids":[`+strings.Join(ids, ",")+`]}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "too many ids, maximum is 100")
+	})
+
+	t.Run("Unknown method", func(t *testing.T) {
+		rec := serve(router, http.MethodPost, "/users:batchDelete", "application/json", `{"ids":[1]}`)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	svc.AssertNumberOfCalls(t, "GetUsers", 2)
+}
+
+func TestRouter_Search(t *testing.T) {
+	hits := []models.UserSearchHit{{
+		User:       testUser,
+		Rank:       0.75,
+		Highlights: map[models.SearchField]string{models.SearchFieldLastName: "<mark>Doe</mark>"},
+	}}
+
+	svc := &servicetest.MockService{}
+	svc.On("SearchUsers", models.UserSearch{Query: "doe", Limit: 1}).Return(hits, nil)
+	svc.On("SearchUsers", models.UserSearch{Query: "doe", Limit: 20, Offset: 1}).Return([]models.UserSearchHit{}, nil)
+	svc.On("SearchUsers", models.UserSearch{Query: "!!", Limit: 20}).Return(nil, models.ErrInvalidSearchQuery)
+
+	router := newTestRouter(t, svc)
+
+	t.Run("v1", func(t *testing.T) {
+		rec := serve(router, http.MethodGet, "/users/search?q=doe&limit=1", "", "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.JSONEq(t, `{"results":[{"user":{"id":1,"username":"jdoe","first_name":"John","last_name":"Doe",`+
+			`"email":"jdoe@example.com","gender":"M","age":30,"created_at":"2026-01-02T03:04:05Z"},`+
+			`"rank":0.75,"highlights":{"last_name":"<mark>Doe</mark>"}}],"next_offset":1}`, rec.Body.String())
+	})
+
+	t.Run("v2 last page", func(t *testing.T) {
+		rec := serve(router, http.MethodGet, "/v2/users/search?q=doe&offset=1", "", "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.JSONEq(t, `{"results":[]}`, rec.Body.String())
+	})
+
+	t.Run("Query without words", func(t *testing.T) {
+		rec := serve(router, http.MethodGet, "/users/search?q=!!", "", "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), models.ErrInvalidSearchQuery.Error())
+	})
+
+	t.Run("Invalid parameters", func(t *testing.T) {
+		for _, target := range []string{"/users/search", "/users/search?q=doe&limit=0", "/users/search?q=doe&offset=-1"} {
+			rec := serve(router, http.MethodGet, target, "", "")
+			assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+		}
+	})
+
+	svc.AssertNumberOfCalls(t, "SearchUsers", 3)
+}
+
+func TestRouter_UsernameAvailability(t *testing.T) {
+	svc := &servicetest.MockService{}
+	svc.On("CheckUsername", "JDoe").Return(models.UsernameAvailability{
+		Username: "jdoe", Suggestions: []string{"jdoe1", "jdoe2"},
+	}, nil)
+	svc.On("CheckUsername", "  ").Return(models.UsernameAvailability{}, models.ErrInvalidUsername)
+
+	router := newTestRouter(t, svc)
+
+	rec := serve(router, http.MethodGet, "/v2/users/username-availability?username=JDoe", "", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"username":"jdoe","available":false,"suggestions":["jdoe1","jdoe2"]}`, rec.Body.String())
+
+	rec = serve(router, http.MethodGet, "/users/username-availability?username=%20%20", "", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), models.ErrInvalidUsername.Error())
+}
+
+// tenantRecorder records tenant of every GetUser call
+type tenantRecorder struct {
+	*servicetest.MockService
+	tenants []string
+}
+
+func (r *tenantRecorder) GetUser(ctx context.Context, id int64) (*models.UserInfo, error) {
+	r.tenants = append(r.tenants, tenant.FromContext(ctx))
+	return r.MockService.GetUser(ctx, id)
+}
+
+// TestRouter_Groups tests groups and membership routes under strict validation
+func TestRouter_Groups(t *testing.T) {
+	group := models.Group{ID: 7, Name: "Platform", Description: "platform team", CreatedAt: testUser.CreatedAt}
+
+	svc := &servicetest.MockService{}
+	svc.On("CreateGroup", models.Group{Name: "Platform", Description: "platform team"}).Return(&group, nil)
+	svc.On("CreateGroup", models.Group{Name: "Billing"}).Return(nil, models.ErrGroupNameIsAlreadyTaken)
+	svc.On("ListGroups", models.Page{Limit: 1}).Return([]models.Group{group}, nil)
+	svc.On("GetGroup", int64(8)).Return(nil, models.ErrGroupDoesNotExist)
+	svc.On("PutGroupMember", models.Membership{GroupID: 7, UserID: 1, Role: models.GroupRoleAdmin}).Return(true, nil)
+	svc.On("PutGroupMember", models.Membership{GroupID: 7, UserID: 2}).Return(false, models.ErrUserIsGone)
+	svc.On("ListGroupMembers", int64(7), models.Page{Limit: 20}).Return([]models.GroupMember{{
+		User: testUser, Role: models.GroupRoleAdmin, JoinedAt: testUser.CreatedAt,
+	}}, nil)
+	svc.On("DeleteGroupMember", int64(7), int64(3)).Return(models.ErrMemberDoesNotExist)
+	svc.On("ListUserGroups", int64(1), models.Page{Limit: 20, Offset: 5}).Return([]models.UserGroup{{
+		Group: group, Role: models.GroupRoleAdmin, JoinedAt: testUser.CreatedAt,
+	}}, nil)
+	svc.On("ListUserGroups", int64(2), models.Page{Limit: 20}).Return(nil, models.ErrUserIsGone)
+
+	router := newTestRouter(t, svc)
+	groupJSON := `{"id":7,"name":"Platform","description":"platform team","created_at":"2026-01-02T03:04:05Z"}`
+
+	rec := serve(router, http.MethodPost, "/groups", "application/json", `{"name":"Platform","description":"platform team"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	assert.JSONEq(t, groupJSON, rec.Body.String())
+
+	rec = serve(router, http.MethodPost, "/groups", "application/json", `{"name":"Billing"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = serve(router, http.MethodGet, "/groups?limit=1", "", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"groups":[`+groupJSON+`],"next_offset":1}`, rec.Body.String())
+
+	rec = serve(router, http.MethodGet, "/groups/8", "", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(router, http.MethodPut, "/groups/7/members/1", "application/json", `{"role":"admin"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	rec = serve(router, http.MethodPut, "/groups/7/members/2", "application/json", `{}`)
+	assert.Equal(t, http.StatusGone, rec.Code, rec.Body.String())
+
+	rec = serve(router, http.MethodPut, "/groups/7/members/1", "application/json", `{"role":"boss"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "role is checked by the document")
+
+	rec = serve(router, http.MethodGet, "/groups/7/members", "", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"members":[{"user_id":1,"username":"jdoe","first_name":"John","last_name":"Doe",`+
+		`"role":"admin","joined_at":"2026-01-02T03:04:05Z"}]}`, rec.Body.String())
+
+	rec = serve(router, http.MethodDelete, "/groups/7/members/3", "", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(router, http.MethodGet, "/v2/users/1/groups?offset=5", "", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"groups":[{"group":`+groupJSON+`,"role":"admin","joined_at":"2026-01-02T03:04:05Z"}]}`,
+		rec.Body.String())
+
+	rec = serve(router, http.MethodGet, "/users/2/groups", "", "")
+	assert.Equal(t, http.StatusGone, rec.Code)
+
+	svc.AssertExpectations(t)
+}
+
+// TestRouter_Avatar tests avatar upload as raw body and multipart form, its limits and conditional download
+func TestRouter_Avatar(t *testing.T) {
+	var img bytes.Buffer
+	require.NoError(t, png.Encode(&img, image.NewGray(image.Rect(0, 0, 4, 4))))
+	thumb := []byte("\x89PNG\r\n\x1a\nthumbnail")
+	modified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	avatar := models.Avatar{
+		Version: "abc", Size: models.AvatarSmall, ContentType: "image/png", Length: int64(len(thumb)), ModTime: modified,
+	}
+
+	svc := &servicetest.MockService{}
+	svc.On("PutAvatar", int64(1), img.Bytes()).Return("abc", nil)
+	svc.On("GetAvatar", int64(1), models.AvatarSmall).Return(io.NopCloser(bytes.NewReader(thumb)), avatar, nil).Twice()
+	svc.On("GetAvatar", int64(2), models.AvatarLarge).Return(nil, models.Avatar{}, models.ErrAvatarDoesNotExist)
+	svc.On("DeleteAvatar", int64(1)).Return(nil)
+
+	router := newTestRouter(t, svc)
+
+	rec := serve(router, http.MethodPut, "/users/1/avatar", "image/png", img.String())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"avatar_url":"/v1/users/1/avatar?v=abc"}`, rec.Body.String())
+
+	var form bytes.Buffer
+	w := multipart.NewWriter(&form)
+	part, err := w.CreateFormFile("avatar", "me.png")
+	require.NoError(t, err)
+	_, _ = part.Write(img.Bytes())
+	require.NoError(t, w.Close())
+	rec = serve(router, http.MethodPut, "/v2/users/1/avatar", w.FormDataContentType(), form.String())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"avatar_url":"/v2/users/1/avatar?v=abc"}`, rec.Body.String())
+
+	rec = serve(router, http.MethodPut, "/users/1/avatar", "image/png", "not an image, declared type is not trusted")
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code, rec.Body.String())
+
+	rec = serve(router, http.MethodPut, "/users/1/avatar", "image/png", img.String()+strings.Repeat("x", 5<<20))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code, rec.Body.String())
+
+	rec = serve(router, http.MethodGet, "/users/1/avatar?size=small&v=abc", "", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, thumb, rec.Body.Bytes())
+	assert.Equal(t, "image/png", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `"abc-small"`, rec.Header().Get("ETag"))
+	assert.Equal(t, "private, no-cache", rec.Header().Get("Cache-Control"))
+
+	rec = serve(router, http.MethodGet, "/users/1/avatar?size=small", "", "", "If-None-Match", `"abc-small"`)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	rec = serve(router, http.MethodGet, "/users/2/avatar", "", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(router, http.MethodGet, "/users/1/avatar?size=huge", "", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "size is checked by the document")
+
+	rec = serve(router, http.MethodDelete, "/users/1/avatar", "", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	svc.AssertExpectations(t)
+}
+
+// TestRouter_ExportAndErase tests data export archive and erasure of personal data
+func TestRouter_ExportAndErase(t *testing.T) {
+	deleted := testUser
+	deletedAt := testUser.CreatedAt.Add(time.Hour)
+	deleted.EndDate = &deletedAt
+	export := &models.UserExport{
+		Tenant: tenant.Default,
+		User:   deleted,
+		History: []models.UserEvent{
+			{Type: models.UserCreated, OccurredAt: testUser.CreatedAt},
+			{Type: models.UserDeleted, OccurredAt: deletedAt},
+		},
+		Groups: []models.UserGroup{{
+			Group: models.Group{ID: 7, Name: "Platform", CreatedAt: testUser.CreatedAt},
+			Role:  models.GroupRoleOwner, JoinedAt: testUser.CreatedAt,
+		}},
+		Avatar:     map[models.AvatarSize][]byte{models.AvatarSmall: []byte("small png")},
+		ExportedAt: testUser.CreatedAt.Add(2 * time.Hour),
+	}
+
+	svc := &servicetest.MockService{}
+	svc.On("ExportUser", int64(1)).Return(export, nil)
+	svc.On("ExportUser", int64(2)).Return(nil, models.ErrUserIsErased)
+	svc.On("EraseUser", int64(1)).Return(nil)
+	svc.On("EraseUser", int64(2)).Return(models.ErrUserIsErased)
+
+	router := newTestRouter(t, svc)
+
+	rec := serve(router, http.MethodGet, "/v2/users/1/export", "", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/zip", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="user-1-export.zip"`, rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+
+	archive, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	require.NoError(t, err)
+	files := make(map[string]string)
+	for _, f := range archive.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		files[f.Name] = string(data)
+	}
+	require.Len(t, files, 5)
+	assert.Contains(t, files["manifest.json"], `"files": [
+    "user.json",
+    "history.json",
+    "groups.json",
+    "avatar/small.png"
+  ]`)
+	assert.Contains(t, files["user.json"], `"email": "jdoe@example.com"`)
+	assert.Contains(t, files["user.json"], `"deleted_at": "2026-01-02T04:04:05Z"`)
+	assert.JSONEq(t, `[{"event":"created","occurred_at":"2026-01-02T03:04:05Z"},`+
+		`{"event":"deleted","occurred_at":"2026-01-02T04:04:05Z"}]`, files["history.json"])
+	assert.Contains(t, files["groups.json"], `"role": "owner"`)
+	assert.Equal(t, "small png", files["avatar/small.png"])
+
+	rec = serve(router, http.MethodGet, "/v2/users/2/export", "", "")
+	assert.Equal(t, http.StatusGone, rec.Code)
+
+	rec = serve(router, http.MethodPost, "/v2/users/1/erase", "", "")
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = serve(router, http.MethodPost, "/users/2/erase", "", "")
+	assert.Equal(t, http.StatusGone, rec.Code)
+	assert.JSONEq(t, `{"error_description":"user is gone, its personal data is erased"}`, rec.Body.String())
+
+	svc.AssertExpectations(t)
+}
+
+// TestRouter_Tenant tests resolving of tenant from header and bearer token into context of the service
+func TestRouter_Tenant(t *testing.T) {
+	svc := &tenantRecorder{MockService: &servicetest.MockService{}}
+	svc.On("GetUser", int64(1)).Return(&testUser, nil)
+
+	lg, err := logger.NewLogger("test", logger.Option{Level: "disabled"})
+	require.NoError(t, err)
+
+	cfg := config.Default()
+	cfg.OpenAPIValidation = config.ValidationStrict
+	cfg.TenantJWTKey = "key"
+	reloaded := cfg
+	store := config.NewStore(cfg, func() (config.Config, error) { return reloaded, nil })
+	router := NewRouter(Option{
+		Conf:    store,
+		Logger:  lg,
+		Service: &service.Service{IUserManagementService: svc},
+		Health:  healthcheck.NewRegistry(),
+	})
+
+	token := func(claims jwt.MapClaims) string {
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("key"))
+		require.NoError(t, err)
+		return "Bearer " + signed
+	}
+
+	testCases := []struct {
+		name     string
+		headers  []string
+		expected int
+		tenant   string
+	}{
+		{name: "No token", expected: http.StatusUnauthorized},
+		{name: "Header without token", headers: []string{"X-Tenant-ID", "acme"}, expected: http.StatusUnauthorized},
+		{name: "Token", headers: []string{"Authorization", token(jwt.MapClaims{"tenant_id": "globex"})},
+			expected: http.StatusOK, tenant: "globex"},
+		{name: "Header repeats token", headers: []string{
+			"X-Tenant-ID", "globex", "Authorization", token(jwt.MapClaims{"tenant_id": "globex"}),
+		}, expected: http.StatusOK, tenant: "globex"},
+		{name: "Header differs from token", headers: []string{
+			"X-Tenant-ID", "acme", "Authorization", token(jwt.MapClaims{"tenant_id": "globex"}),
+		}, expected: http.StatusForbidden},
+		{name: "Token without claim", headers: []string{"Authorization", token(jwt.MapClaims{"sub": "1"})},
+			expected: http.StatusUnauthorized},
+		{name: "Invalid tenant id", headers: []string{"Authorization", token(jwt.MapClaims{"tenant_id": "Acme Corp"})},
+			expected: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc.tenants = nil
+
+			rec := serve(router, http.MethodGet, "/v2/users/1", "", "", tc.headers...)
+
+			require.Equal(t, tc.expected, rec.Code, rec.Body.String())
+			if tc.tenant == "" {
+				assert.Empty(t, svc.tenants)
+				return
+			}
+			assert.Equal(t, []string{tc.tenant}, svc.tenants)
+			assert.Equal(t, []string{"X-Tenant-ID", "Authorization"}, rec.Header().Values("Vary"))
+		})
+	}
+
+	t.Run("Header without key", func(t *testing.T) {
+		reloaded.TenantJWTKey = ""
+		_, err := store.Reload()
+		require.NoError(t, err)
+		svc.tenants = nil
+
+		rec := serve(router, http.MethodGet, "/v2/users/1", "", "", "X-Tenant-ID", "acme")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, []string{"acme"}, svc.tenants)
+
+		rec = serve(router, http.MethodGet, "/v2/users/1", "", "", "X-Tenant-ID", "Acme Corp")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Required tenant", func(t *testing.T) {
+		reloaded.TenantJWTKey = ""
+		reloaded.TenantRequired = true
+		_, err := store.Reload()
+		require.NoError(t, err)
+
+		rec := serve(router, http.MethodGet, "/v2/users/1", "", "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.JSONEq(t, `{"error_description":"tenant is required"}`, rec.Body.String())
+
+		rec = serve(router, http.MethodGet, "/livez", "", "")
+		assert.Equal(t, http.StatusOK, rec.Code, "health routes are not scoped by tenant")
+	})
+}
+
+// TestRouter_Admin tests that admin routes require ADMIN_TOKEN and are disabled without it
+func TestRouter_Admin(t *testing.T) {
+	lg, err := logger.NewLogger("test", logger.Option{Level: "disabled"})
+	require.NoError(t, err)
+
+	cfg := config.Default()
+	cfg.OpenAPIValidation = config.ValidationStrict
+	reloaded := cfg
+	store := config.NewStore(cfg, func() (config.Config, error) { return reloaded, nil })
+	router := NewRouter(Option{
+		Conf:         store,
+		Logger:       lg,
+		Service:      &service.Service{IUserManagementService: &servicetest.MockService{}},
+		ReloadConfig: store.Reload,
+		Health:       healthcheck.NewRegistry(),
+	})
+
+	rec := serve(router, http.MethodPost, "/admin/config/reload", "", "", "Authorization", "Bearer ")
+	assert.Equal(t, http.StatusForbidden, rec.Code, "admin api is disabled without token")
+
+	reloaded.AdminToken = testAdminToken
+	_, err = store.Reload()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		authorization string
+		expected      int
+	}{
+		{name: "No token", expected: http.StatusUnauthorized},
+		{name: "Wrong token", authorization: "Bearer other", expected: http.StatusUnauthorized},
+		{name: "Wrong scheme", authorization: "Basic " + testAdminToken, expected: http.StatusUnauthorized},
+		{name: "Token", authorization: "Bearer " + testAdminToken, expected: http.StatusOK},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := serve(router, http.MethodPost, "/admin/config/reload", "", "", "Authorization", tc.authorization)
+			assert.Equal(t, tc.expected, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+// TestRouter_Features tests that routes of features disabled by FEATURES_DISABLED are answered with 404
+func TestRouter_Features(t *testing.T) {
+	svc := &servicetest.MockService{}
+	svc.On("GetAvatar", int64(1), models.AvatarLarge).Return(nil, models.Avatar{}, models.ErrAvatarDoesNotExist)
+
+	lg, err := logger.NewLogger("test", logger.Option{Level: "disabled"})
+	require.NoError(t, err)
+
+	cfg := config.Default()
+	cfg.OpenAPIValidation = config.ValidationStrict
+	reloaded := cfg
+	store := config.NewStore(cfg, func() (config.Config, error) { return reloaded, nil })
+	router := NewRouter(Option{
+		Conf:    store,
+		Logger:  lg,
+		Service: &service.Service{IUserManagementService: svc},
+		Health:  healthcheck.NewRegistry(),
+	})
+
+	rec := serve(router, http.MethodGet, "/swagger/", "", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	reloaded.FeaturesDisabled = "graphql, avatars, swagger"
+	_, err = store.Reload()
+	require.NoError(t, err)
+
+	rec = serve(router, http.MethodPost, "/graphql", "application/json", `{"query":"{ user(id: 1) { id } }"}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"error_description":"feature graphql is disabled"}`, rec.Body.String())
+	rec = serve(router, http.MethodPut, "/v2/users/1/avatar", "image/png", "png")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = serve(router, http.MethodGet, "/swagger/", "", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = serve(router, http.MethodGet, "/openapi.json", "", "")
+	assert.Equal(t, http.StatusOK, rec.Code, "document stays available")
+
+	reloaded.FeaturesDisabled = ""
+	_, err = store.Reload()
+	require.NoError(t, err)
+
+	rec = serve(router, http.MethodGet, "/v2/users/1/avatar", "", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.JSONEq(t, `{"error_description":"user has no avatar"}`, rec.Body.String(), "avatars are enabled again")
+	svc.AssertExpectations(t)
+}
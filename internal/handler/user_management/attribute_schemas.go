@@ -0,0 +1,116 @@
+package user_management
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/gravitum_test_task/internal/handler/user_management/dto"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"github.com/sonikq/gravitum_test_task/pkg/reader"
+	"net/http"
+	"strings"
+)
+
+// ListAttributeSchemas - all registered schemas of user attributes ordered by name.
+func (h *Handler) ListAttributeSchemas(ctx *gin.Context) {
+	const source = "handler.ListAttributeSchemas"
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	schemas, err := h.service.ListAttributeSchemas(c)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{models.ErrMsgKey: "internal server error, something went wrong"})
+		h.logger.Error().
+			Err(err).
+			Str("source", source).
+			Msg("failed to list attribute schemas")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.NewAttributeSchemas(schemas))
+}
+
+// PutAttributeSchema - registering JSON Schema from request body for attribute with name from path,
+// schema with the same name is replaced.
+func (h *Handler) PutAttributeSchema(ctx *gin.Context) {
+	const source = "handler.PutAttributeSchema"
+
+	if !strings.HasPrefix(ctx.GetHeader(contentTypeHeaderKey), contentTypeJSON) {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{models.ErrMsgKey: "Invalid type of content"})
+		h.logger.Error().
+			Str("error", "invalid content type").
+			Str("source", source).
+			Send()
+		return
+	}
+
+	bodyBytes, err := reader.GetBody(ctx.Request.Body)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{models.ErrMsgKey: "Error in reading request body"})
+		h.logger.Error().
+			Err(err).
+			Str("source", source).
+			Msg("failed to read request body")
+		return
+	}
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	created, err := h.service.PutAttributeSchema(c, models.AttributeSchema{Name: ctx.Param("name"), Schema: bodyBytes})
+	if err != nil {
+		statusCode, userMsg := http.StatusInternalServerError, "internal server error, something went wrong"
+		if errors.Is(err, models.ErrInvalidAttributeSchema) {
+			// message tells what is wrong with the schema
+			statusCode, userMsg = http.StatusBadRequest, err.Error()
+		}
+
+		ctx.AbortWithStatusJSON(statusCode, gin.H{models.ErrMsgKey: userMsg})
+		h.logger.Error().
+			Err(err).
+			Str("source", source).
+			Msg("failed to put attribute schema")
+		return
+	}
+
+	if created {
+		ctx.JSON(http.StatusCreated, gin.H{"message": "created"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "updated"})
+}
+
+// DeleteAttributeSchema - deleting schema of attribute with name from path, schema of attribute users have is kept.
+func (h *Handler) DeleteAttributeSchema(ctx *gin.Context) {
+	const source = "handler.DeleteAttributeSchema"
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	if err := h.service.DeleteAttributeSchema(c, ctx.Param("name")); err != nil {
+		var statusCode int
+		switch {
+		case errors.Is(err, models.ErrAttributeSchemaDoesNotExist):
+			statusCode = http.StatusNotFound
+		case errors.Is(err, models.ErrAttributeSchemaIsInUse):
+			statusCode = http.StatusConflict
+		default:
+			statusCode = http.StatusInternalServerError
+		}
+
+		userMsg := "internal server error, something went wrong"
+		if statusCode != http.StatusInternalServerError {
+			userMsg = err.Error()
+		}
+
+		ctx.AbortWithStatusJSON(statusCode, gin.H{models.ErrMsgKey: userMsg})
+		h.logger.Error().
+			Err(err).
+			Str("source", source).
+			Msg("failed to delete attribute schema")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "success"})
+}
@@ -0,0 +1,209 @@
+package user_management
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/gravitum_test_task/internal/handler/user_management/dto"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"github.com/sonikq/gravitum_test_task/pkg/thumbnail"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// avatarFormField - field of multipart form with avatar file.
+	avatarFormField = "avatar"
+	// multipartOverhead - allowance for boundaries and headers of multipart form over the avatar size limit.
+	multipartOverhead = 64 << 10
+)
+
+var errAvatarTooLarge = errors.New("avatar is too large")
+
+// PutAvatar - uploading avatar of user with id from path as multipart form with file in field "avatar"
+// or as raw image body. Type of image is sniffed from content, declared one is not trusted.
+// Size of upload is limited by AVATAR_MAX_BYTES and pixel count of image by AVATAR_MAX_PIXELS.
+func (h *Handler) PutAvatar(ctx *gin.Context) {
+	const source = "handler.PutAvatar"
+
+	userID, ok := h.pathID(ctx, source, "id", "user_id")
+	if !ok {
+		return
+	}
+
+	cfg := h.config.Current()
+	image, err := readAvatar(ctx, cfg.AvatarMaxBytes)
+	if err == nil {
+		err = thumbnail.Check(image, cfg.AvatarMaxPixels)
+	}
+	if err != nil {
+		h.abortWithAvatarError(ctx, source, err, "invalid avatar upload")
+		return
+	}
+
+	c, cancel := context.WithTimeout(ctx, cfg.CtxTimeOut)
+	defer cancel()
+
+	version, err := h.service.PutAvatar(c, userID, image)
+	if err != nil {
+		h.abortWithAvatarError(ctx, source, err, "failed to put avatar")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.AvatarUploaded{
+		AvatarURL: dto.AvatarURL(string(negotiation(ctx).version), userID, version),
+	})
+}
+
+// GetAvatar - avatar of user with id from path as PNG image, query parameter size is
+// small, medium or large (default). Images of a version never change, so ETag is the version.
+func (h *Handler) GetAvatar(ctx *gin.Context) {
+	const source = "handler.GetAvatar"
+
+	userID, ok := h.pathID(ctx, source, "id", "user_id")
+	if !ok {
+		return
+	}
+	size := models.AvatarSize(ctx.DefaultQuery("size", string(models.AvatarLarge)))
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	r, avatar, err := h.service.GetAvatar(c, userID, size)
+	if err != nil {
+		h.abortWithAvatarError(ctx, source, err, "failed to get avatar")
+		return
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+
+	etag, modified := `"`+avatar.Version+"-"+string(avatar.Size)+`"`, avatar.ModTime.UTC()
+	ctx.Header("ETag", etag)
+	ctx.Header("Last-Modified", modified.Format(http.TimeFormat))
+	if notModified(ctx.Request, etag, modified) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	ctx.DataFromReader(http.StatusOK, avatar.Length, avatar.ContentType, r, nil)
+}
+
+// DeleteAvatar - removing avatar of user with id from path.
+func (h *Handler) DeleteAvatar(ctx *gin.Context) {
+	const source = "handler.DeleteAvatar"
+
+	userID, ok := h.pathID(ctx, source, "id", "user_id")
+	if !ok {
+		return
+	}
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	if err := h.service.DeleteAvatar(c, userID); err != nil {
+		h.abortWithAvatarError(ctx, source, err, "failed to delete avatar")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "success"})
+}
+
+// readAvatar - image from multipart form field "avatar" or from raw body, at most maxBytes long.
+func readAvatar(ctx *gin.Context, maxBytes int) ([]byte, error) {
+	mediaType, params, err := mime.ParseMediaType(ctx.GetHeader(contentTypeHeaderKey))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid content type", thumbnail.ErrUnsupportedFormat)
+	}
+
+	switch {
+	case mediaType == "multipart/form-data":
+		body := http.MaxBytesReader(ctx.Writer, ctx.Request.Body, int64(maxBytes)+multipartOverhead)
+		form := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := form.NextPart()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil, fmt.Errorf("%w: form field %q is missing", models.ErrInvalidAvatar, avatarFormField)
+				}
+				return nil, uploadError(err)
+			}
+			if part.FormName() == avatarFormField {
+				return readLimited(part, maxBytes)
+			}
+		}
+
+	case strings.HasPrefix(mediaType, "image/"), mediaType == "application/octet-stream":
+		return readLimited(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, int64(maxBytes)), maxBytes)
+
+	default:
+		return nil, fmt.Errorf("%w: content type %s", thumbnail.ErrUnsupportedFormat, mediaType)
+	}
+}
+
+// readLimited - reading r to the end, errAvatarTooLarge if it is longer than maxBytes.
+func readLimited(r io.Reader, maxBytes int) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, int64(maxBytes)+1))
+	if err != nil {
+		return nil, uploadError(err)
+	}
+	if len(data) > maxBytes {
+		return nil, errAvatarTooLarge
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: empty upload", models.ErrInvalidAvatar)
+	}
+	return data, nil
+}
+
+// uploadError - errAvatarTooLarge for body over the limit, malformed upload otherwise.
+func uploadError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return errAvatarTooLarge
+	}
+	return fmt.Errorf("%w: %v", models.ErrInvalidAvatar, err)
+}
+
+// abortWithAvatarError - answering with status of error of avatars, missing and deleted users
+// are answered as in GetUser, unknown error is internal.
+func (h *Handler) abortWithAvatarError(ctx *gin.Context, source string, err error, logMsg string) {
+	var statusCode int
+	switch {
+	case errors.Is(err, models.ErrUserDoesNotExist):
+		statusCode = http.StatusNoContent
+	case errors.Is(err, models.ErrUserIsGone):
+		statusCode = http.StatusGone
+	case errors.Is(err, models.ErrAvatarDoesNotExist):
+		statusCode = http.StatusNotFound
+	case errors.Is(err, errAvatarTooLarge), errors.Is(err, thumbnail.ErrTooManyPixels):
+		statusCode = http.StatusRequestEntityTooLarge
+	case errors.Is(err, thumbnail.ErrUnsupportedFormat):
+		statusCode = http.StatusUnsupportedMediaType
+	case errors.Is(err, models.ErrInvalidAvatar), errors.Is(err, models.ErrInvalidAvatarSize),
+		errors.Is(err, thumbnail.ErrInvalidImage):
+		statusCode = http.StatusBadRequest
+	default:
+		statusCode = http.StatusInternalServerError
+	}
+
+	userMsg := "internal server error, something went wrong"
+	if statusCode != http.StatusInternalServerError {
+		userMsg = err.Error()
+	}
+	if errors.Is(err, errAvatarTooLarge) {
+		userMsg = errAvatarTooLarge.Error() + ", limit is " +
+			strconv.Itoa(h.config.Current().AvatarMaxBytes) + " bytes"
+	}
+
+	ctx.AbortWithStatusJSON(statusCode, gin.H{models.ErrMsgKey: userMsg})
+	h.logger.Error().
+		Err(err).
+		Str("source", source).
+		Msg(logMsg)
+}
@@ -0,0 +1,131 @@
+package user_management
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/sonikq/gravitum_test_task/internal/handler/user_management/dto"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"github.com/sonikq/gravitum_test_task/pkg/reader"
+	"net/http"
+	"strings"
+)
+
+// CustomMethods - names of POST <prefix>:<name> routes of users.
+var CustomMethods = []string{"batchGet"}
+
+// CustomMethod - dispatching POST <prefix>:<name> routes. Gin can not register a literal colon,
+// so all custom methods share a route with the name as parameter "method".
+func (h *Handler) CustomMethod(ctx *gin.Context) {
+	switch strings.TrimPrefix(ctx.Param("method"), ":") {
+	case "batchGet":
+		h.BatchGetUsers(ctx)
+	default:
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{models.ErrMsgKey: "unknown method"})
+	}
+}
+
+// BatchGetUsers - getting users by ids in one query, found, missing and deleted ids are reported separately.
+func (h *Handler) BatchGetUsers(ctx *gin.Context) {
+	const source = "handler.BatchGetUsers"
+
+	version := negotiation(ctx)
+	if !version.validContentType(ctx.GetHeader(contentTypeHeaderKey)) {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{models.ErrMsgKey: "Invalid type of content"})
+		h.logger.Error().
+			Str("error", "invalid content type").
+			Str("source", source).
+			Send()
+		return
+	}
+
+	bodyBytes, err := reader.GetBody(ctx.Request.Body)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{models.ErrMsgKey: "Error in reading request body"})
+		h.logger.Error().
+			Err(err).
+			Str("source", source).
+			Msg("failed to read request body")
+		return
+	}
+
+	var request dto.BatchGetUsersRequest
+	if err = json.Unmarshal(bodyBytes, &request); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{models.ErrMsgKey: "Error in parsing request body"})
+		h.logger.Error().
+			Err(err).
+			Str("source", source).
+			Msg("failed to unmarshal request body")
+		return
+	}
+
+	cfg := h.config.Current()
+	ids, err := uniqueIDs(request.IDs, cfg.BatchGetMaxIDs)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{models.ErrMsgKey: err.Error()})
+		h.logger.Error().
+			Err(err).
+			Str("source", source).
+			Msg("failed to validate ids")
+		return
+	}
+
+	c, cancel := context.WithTimeout(ctx, cfg.CtxTimeOut)
+	defer cancel()
+
+	users, err := h.service.GetUsers(c, ids)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{models.ErrMsgKey: "internal server error, something went wrong"})
+		h.logger.Error().
+			Err(err).
+			Str("source", source).
+			Msg("failed to get users")
+		return
+	}
+
+	var (
+		found   = make([]*models.UserInfo, 0, len(ids))
+		missing []int64
+		deleted []int64
+	)
+	for _, id := range ids {
+		user, ok := users[id]
+		switch {
+		case !ok:
+			missing = append(missing, id)
+		case user.EndDate != nil:
+			deleted = append(deleted, id)
+		default:
+			found = append(found, user)
+		}
+	}
+
+	version.writeBatch(ctx, found, missing, deleted)
+}
+
+// uniqueIDs - requested ids without repeats in request order.
+func uniqueIDs(ids []int64, maxIDs int) ([]int64, error) {
+	if len(ids) == 0 {
+		return nil, errors.New("ids must not be empty")
+	}
+
+	seen := make(map[int64]struct{}, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id < 1 {
+			return nil, fmt.Errorf("ids must be positive, got %d", id)
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+
+	if len(unique) > maxIDs {
+		return nil, fmt.Errorf("too many ids, maximum is %d", maxIDs)
+	}
+	return unique, nil
+}
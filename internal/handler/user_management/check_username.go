@@ -0,0 +1,37 @@
+package user_management
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/gravitum_test_task/internal/handler/user_management/dto"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"net/http"
+)
+
+// CheckUsername - reporting whether username from query is free for a new user, normalized as on creation.
+// Taken username gets available alternatives, nothing is told about users holding it or deleted ones.
+func (h *Handler) CheckUsername(ctx *gin.Context) {
+	const source = "handler.CheckUsername"
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	availability, err := h.service.CheckUsername(c, ctx.Query("username"))
+	if err != nil {
+		statusCode, userMsg := http.StatusInternalServerError, "internal server error, something went wrong"
+		if errors.Is(err, models.ErrInvalidUsername) {
+			statusCode, userMsg = http.StatusBadRequest, models.ErrInvalidUsername.Error()
+		}
+
+		ctx.AbortWithStatusJSON(statusCode, gin.H{models.ErrMsgKey: userMsg})
+		h.logger.Error().
+			Err(err).
+			Str("source", source).
+			Msg("failed to check username")
+		return
+	}
+
+	negotiation(ctx).setContentType(ctx)
+	ctx.JSON(http.StatusOK, dto.NewUsernameAvailability(availability))
+}
@@ -0,0 +1,63 @@
+package user_management
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lastModified - time of the last change of user, HTTP dates have second precision.
+func lastModified(user *models.UserInfo) time.Time {
+	modified := user.CreatedAt
+	if user.UpdatedAt != nil && user.UpdatedAt.After(modified) {
+		modified = *user.UpdatedAt
+	}
+	return modified.UTC().Truncate(time.Second)
+}
+
+// entityTag - strong validator of user representation, it changes with every write of the row
+// and differs between versions and media types.
+func (n negotiated) entityTag(user *models.UserInfo) string {
+	parts := []string{
+		strconv.FormatInt(user.ID, 10),
+		strconv.FormatInt(user.CreatedAt.UnixNano(), 10),
+		"", "",
+		string(n.version),
+		n.mediaType,
+	}
+	if user.UpdatedAt != nil {
+		parts[2] = strconv.FormatInt(user.UpdatedAt.UnixNano(), 10)
+	}
+	if user.EndDate != nil {
+		parts[3] = strconv.FormatInt(user.EndDate.UnixNano(), 10)
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return `"` + hex.EncodeToString(sum[:12]) + `"`
+}
+
+// notModified - whether client copy is current according to conditional GET headers.
+// If-None-Match takes precedence, If-Modified-Since is used only without it (RFC 9110, 13.2.2).
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		// weak comparison, GET may be answered with 304 for a weakly matching tag
+		for _, candidate := range strings.Split(header, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if header := r.Header.Get("If-Modified-Since"); header != "" {
+		since, err := http.ParseTime(header)
+		return err == nil && !modified.After(since)
+	}
+
+	return false
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"github.com/gin-gonic/gin"
-	"github.com/goccy/go-json"
 	"github.com/sonikq/gravitum_test_task/internal/models"
 	"github.com/sonikq/gravitum_test_task/pkg/reader"
 	"net/http"
@@ -13,7 +12,8 @@ import (
 func (h *Handler) CreateUser(ctx *gin.Context) {
 	const source = "handler.CreateUser"
 
-	if ctx.GetHeader(contentTypeHeaderKey) != contentTypeJSON {
+	version := negotiation(ctx)
+	if !version.validContentType(ctx.GetHeader(contentTypeHeaderKey)) {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{models.ErrMsgKey: "Invalid type of content"})
 		h.logger.Error().
 			Str("error", "invalid content type").
@@ -32,11 +32,11 @@ func (h *Handler) CreateUser(ctx *gin.Context) {
 		return
 	}
 
-	c, cancel := context.WithTimeout(ctx, h.config.CtxTimeOut)
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
 	defer cancel()
 
-	var request models.UserInfo
-	if err = json.Unmarshal(bodyBytes, &request); err != nil {
+	request, err := version.decodeCreate(bodyBytes)
+	if err != nil {
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{models.ErrMsgKey: "Error in parsing request body"})
 		h.logger.Error().
 			Err(err).
@@ -50,6 +50,7 @@ func (h *Handler) CreateUser(ctx *gin.Context) {
 		var (
 			statusCode int
 			userMsg    string
+			errCode    string
 			logMsg     = "failed to create user"
 		)
 
@@ -58,10 +59,32 @@ func (h *Handler) CreateUser(ctx *gin.Context) {
 			statusCode = http.StatusConflict
 			userMsg = models.ErrUsernameIsAlreadyTaken.Error()
 
+		case errors.Is(err, models.ErrEmailIsAlreadyTaken):
+			statusCode = http.StatusConflict
+			userMsg = models.ErrEmailIsAlreadyTaken.Error()
+
+		case errors.Is(err, models.ErrEmailDomainNotAllowed):
+			statusCode = http.StatusUnprocessableEntity
+			userMsg, logMsg = models.ErrEmailDomainNotAllowed.Error(), "email domain is out of policy"
+			errCode = models.ErrCodeEmailDomainNotAllowed
+
 		case errors.Is(err, models.ErrInvalidEmail):
 			statusCode = http.StatusBadRequest
 			userMsg, logMsg = models.ErrInvalidEmail.Error(), "failed to validate email"
 
+		case errors.Is(err, models.ErrInvalidUsername):
+			statusCode = http.StatusBadRequest
+			userMsg, logMsg = models.ErrInvalidUsername.Error(), "failed to validate username"
+
+		case errors.Is(err, models.ErrInvalidName):
+			statusCode = http.StatusBadRequest
+			userMsg, logMsg = models.ErrInvalidName.Error(), "failed to validate name"
+
+		case errors.Is(err, models.ErrInvalidAttributes):
+			// message tells which attribute is invalid and why
+			statusCode = http.StatusBadRequest
+			userMsg, logMsg = err.Error(), "failed to validate attributes"
+
 		case errors.Is(err, models.ErrInvalidAge):
 			statusCode = http.StatusBadRequest
 			userMsg, logMsg = models.ErrInvalidEmail.Error(), "failed to validate age"
@@ -75,12 +98,23 @@ func (h *Handler) CreateUser(ctx *gin.Context) {
 			userMsg = "internal server error, something went wrong"
 		}
 
-		ctx.AbortWithStatusJSON(statusCode, gin.H{models.ErrMsgKey: userMsg})
+		body := gin.H{models.ErrMsgKey: userMsg}
+		if errCode != "" {
+			body[models.ErrCodeKey] = errCode
+		}
+		ctx.AbortWithStatusJSON(statusCode, body)
 		h.logger.Error().
 			Err(err).
 			Str("source", source).
 			Msg(logMsg)
 		return
 	}
-	ctx.Data(http.StatusCreated, contentTypeTextPlain, []byte(id))
+
+	if err = version.writeCreated(ctx, id); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{models.ErrMsgKey: "internal server error, something went wrong"})
+		h.logger.Error().
+			Err(err).
+			Str("source", source).
+			Msg("failed to send created user")
+	}
 }
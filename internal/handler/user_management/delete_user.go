@@ -22,7 +22,7 @@ func (h *Handler) DeleteUser(ctx *gin.Context) {
 		return
 	}
 
-	c, cancel := context.WithTimeout(ctx, h.config.CtxTimeOut)
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
 	defer cancel()
 
 	err = h.service.DeleteUser(c, int64(userID))
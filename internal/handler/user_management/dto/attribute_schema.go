@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"encoding/json"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"time"
+)
+
+// AttributeSchema - JSON Schema of user attribute, same in all versions.
+type AttributeSchema struct {
+	Name      string          `json:"name"`
+	Schema    json.RawMessage `json:"schema"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt *time.Time      `json:"updated_at,omitempty"`
+}
+
+// AttributeSchemas - all registered attribute schemas.
+type AttributeSchemas struct {
+	Schemas []AttributeSchema `json:"schemas"`
+}
+
+// NewAttributeSchemas - attribute schemas in API representation.
+func NewAttributeSchemas(schemas []models.AttributeSchema) AttributeSchemas {
+	out := AttributeSchemas{Schemas: make([]AttributeSchema, 0, len(schemas))}
+	for _, schema := range schemas {
+		out.Schemas = append(out.Schemas, AttributeSchema{
+			Name:      schema.Name,
+			Schema:    schema.Schema,
+			CreatedAt: schema.CreatedAt,
+			UpdatedAt: schema.UpdatedAt,
+		})
+	}
+	return out
+}
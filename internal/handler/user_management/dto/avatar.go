@@ -0,0 +1,6 @@
+package dto
+
+// AvatarUploaded - response of avatar upload with link to the new avatar.
+type AvatarUploaded struct {
+	AvatarURL string `json:"avatar_url"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+// BatchGetUsersRequest - ids of users to get at once, same in all versions.
+type BatchGetUsersRequest struct {
+	IDs []int64 `json:"ids"`
+}
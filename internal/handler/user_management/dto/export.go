@@ -0,0 +1,142 @@
+package dto
+
+import (
+	"archive/zip"
+	"github.com/goccy/go-json"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"io"
+	"time"
+)
+
+// ExportedUser - user in data export with every stored field, same in all versions.
+type ExportedUser struct {
+	ID         int64          `json:"id"`
+	Tenant     string         `json:"tenant"`
+	Username   string         `json:"username"`
+	FirstName  string         `json:"first_name"`
+	MiddleName string         `json:"middle_name"`
+	LastName   string         `json:"last_name"`
+	Email      string         `json:"email"`
+	Gender     string         `json:"gender"`
+	Age        uint8          `json:"age"`
+	Attributes map[string]any `json:"attributes"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  *time.Time     `json:"updated_at"`
+	DeletedAt  *time.Time     `json:"deleted_at"`
+}
+
+// ExportedMembership - membership of user in group in data export.
+type ExportedMembership struct {
+	Group     Group      `json:"group"`
+	Role      string     `json:"role"`
+	JoinedAt  time.Time  `json:"joined_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// ExportedEvent - change of user in audit history of data export.
+type ExportedEvent struct {
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// ExportManifest - description of data export archive.
+type ExportManifest struct {
+	UserID     int64     `json:"user_id"`
+	Tenant     string    `json:"tenant"`
+	ExportedAt time.Time `json:"exported_at"`
+	// Files - files of the archive besides the manifest.
+	Files []string `json:"files"`
+}
+
+// WriteExportArchive - writing data export as ZIP archive with manifest.json, user.json, history.json,
+// groups.json and avatar images in avatar/<size>.png.
+func WriteExportArchive(w io.Writer, export *models.UserExport) error {
+	user := export.User
+	attributes := user.Attributes
+	if attributes == nil {
+		attributes = map[string]any{}
+	}
+	history := make([]ExportedEvent, 0, len(export.History))
+	for _, event := range export.History {
+		history = append(history, ExportedEvent{Event: string(event.Type), OccurredAt: event.OccurredAt})
+	}
+	memberships := make([]ExportedMembership, 0, len(export.Groups))
+	for i := range export.Groups {
+		memberships = append(memberships, ExportedMembership{
+			Group:     NewGroup(&export.Groups[i].Group),
+			Role:      string(export.Groups[i].Role),
+			JoinedAt:  export.Groups[i].JoinedAt,
+			UpdatedAt: export.Groups[i].UpdatedAt,
+		})
+	}
+
+	type file struct {
+		name string
+		data []byte
+	}
+	files := make([]file, 0, 3+len(export.Avatar))
+	for _, f := range []struct {
+		name  string
+		value any
+	}{
+		{"user.json", ExportedUser{
+			ID:         user.ID,
+			Tenant:     export.Tenant,
+			Username:   user.Username,
+			FirstName:  user.FirstName,
+			MiddleName: user.MiddleName,
+			LastName:   user.LastName,
+			Email:      user.Email,
+			Gender:     user.Gender,
+			Age:        user.Age,
+			Attributes: attributes,
+			CreatedAt:  user.CreatedAt,
+			UpdatedAt:  user.UpdatedAt,
+			DeletedAt:  user.EndDate,
+		}},
+		{"history.json", history},
+		{"groups.json", memberships},
+	} {
+		data, err := json.MarshalIndent(f.value, "", "  ")
+		if err != nil {
+			return err
+		}
+		files = append(files, file{name: f.name, data: data})
+	}
+	for _, size := range models.AvatarSizes {
+		if data, ok := export.Avatar[size]; ok {
+			files = append(files, file{name: "avatar/" + string(size) + ".png", data: data})
+		}
+	}
+
+	manifest := ExportManifest{
+		UserID:     user.ID,
+		Tenant:     export.Tenant,
+		ExportedAt: export.ExportedAt,
+		Files:      make([]string, 0, len(files)),
+	}
+	for _, f := range files {
+		manifest.Files = append(manifest.Files, f.name)
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	files = append([]file{{name: "manifest.json", data: data}}, files...)
+
+	archive := zip.NewWriter(w)
+	for _, f := range files {
+		fw, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     f.name,
+			Method:   zip.Deflate,
+			Modified: export.ExportedAt,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err = fw.Write(f.data); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
@@ -0,0 +1,119 @@
+package dto
+
+import (
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"time"
+)
+
+// GroupRequest - name and description of created or replaced group.
+type GroupRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ToModel - group with id from path, zero for created one.
+func (r *GroupRequest) ToModel(id int64) models.Group {
+	return models.Group{ID: id, Name: r.Name, Description: r.Description}
+}
+
+// Group - group of users, same in all versions.
+type Group struct {
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+// NewGroup - group in API representation.
+func NewGroup(group *models.Group) Group {
+	return Group{
+		ID:          group.ID,
+		Name:        group.Name,
+		Description: group.Description,
+		CreatedAt:   group.CreatedAt,
+		UpdatedAt:   group.UpdatedAt,
+	}
+}
+
+// Groups - page of groups, next offset is absent on the last page.
+type Groups struct {
+	Groups     []Group `json:"groups"`
+	NextOffset *int    `json:"next_offset,omitempty"`
+}
+
+// NewGroups - page of groups in API representation.
+func NewGroups(groups []models.Group, nextOffset *int) Groups {
+	out := Groups{Groups: make([]Group, 0, len(groups)), NextOffset: nextOffset}
+	for i := range groups {
+		out.Groups = append(out.Groups, NewGroup(&groups[i]))
+	}
+	return out
+}
+
+// MembershipRequest - role of user in group, member if it is empty.
+type MembershipRequest struct {
+	Role string `json:"role"`
+}
+
+// GroupMember - active user in group.
+type GroupMember struct {
+	UserID     int64      `json:"user_id"`
+	Username   string     `json:"username"`
+	FirstName  string     `json:"first_name"`
+	MiddleName string     `json:"middle_name,omitempty"`
+	LastName   string     `json:"last_name"`
+	Role       string     `json:"role"`
+	JoinedAt   time.Time  `json:"joined_at"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+// GroupMembers - page of group members, next offset is absent on the last page.
+type GroupMembers struct {
+	Members    []GroupMember `json:"members"`
+	NextOffset *int          `json:"next_offset,omitempty"`
+}
+
+// NewGroupMembers - page of group members in API representation.
+func NewGroupMembers(members []models.GroupMember, nextOffset *int) GroupMembers {
+	out := GroupMembers{Members: make([]GroupMember, 0, len(members)), NextOffset: nextOffset}
+	for _, member := range members {
+		out.Members = append(out.Members, GroupMember{
+			UserID:     member.User.ID,
+			Username:   member.User.Username,
+			FirstName:  member.User.FirstName,
+			MiddleName: member.User.MiddleName,
+			LastName:   member.User.LastName,
+			Role:       string(member.Role),
+			JoinedAt:   member.JoinedAt,
+			UpdatedAt:  member.UpdatedAt,
+		})
+	}
+	return out
+}
+
+// UserGroup - group user is a member of with role of the user there, same in all versions.
+type UserGroup struct {
+	Group    Group     `json:"group"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// UserGroups - page of groups of user, next offset is absent on the last page.
+type UserGroups struct {
+	Groups     []UserGroup `json:"groups"`
+	NextOffset *int        `json:"next_offset,omitempty"`
+}
+
+// NewUserGroups - page of groups of user in API representation.
+func NewUserGroups(groups []models.UserGroup, nextOffset *int) UserGroups {
+	out := UserGroups{Groups: make([]UserGroup, 0, len(groups)), NextOffset: nextOffset}
+	for i := range groups {
+		out.Groups = append(out.Groups, UserGroup{
+			Group:    NewGroup(&groups[i].Group),
+			Role:     string(groups[i].Role),
+			JoinedAt: groups[i].JoinedAt,
+		})
+	}
+	return out
+}
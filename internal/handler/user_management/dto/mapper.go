@@ -0,0 +1,202 @@
+package dto
+
+import (
+	"fmt"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+)
+
+// ToModel - user to be created from API v1 request.
+func (r CreateUserV1) ToModel() models.UserInfo {
+	return models.UserInfo{
+		Username:   r.Username,
+		FirstName:  r.FirstName,
+		MiddleName: r.MiddleName,
+		LastName:   r.LastName,
+		Email:      r.Email,
+		Gender:     r.Gender,
+		Age:        r.Age,
+	}
+}
+
+// ToModel - new state of user with id from API v1 request.
+func (r UpdateUserV1) ToModel(id int64) models.UserInfo {
+	return models.UserInfo{
+		ID:         id,
+		Username:   r.Username,
+		FirstName:  r.FirstName,
+		MiddleName: r.MiddleName,
+		LastName:   r.LastName,
+		Email:      r.Email,
+		Gender:     r.Gender,
+		Age:        r.Age,
+	}
+}
+
+// NewUserV1 - user in API v1 representation.
+func NewUserV1(user *models.UserInfo) UserV1 {
+	return UserV1{
+		ID:         user.ID,
+		Username:   user.Username,
+		FirstName:  user.FirstName,
+		MiddleName: user.MiddleName,
+		LastName:   user.LastName,
+		Email:      user.Email,
+		Gender:     user.Gender,
+		Age:        user.Age,
+		CreatedAt:  user.CreatedAt,
+		UpdatedAt:  user.UpdatedAt,
+		DeletedAt:  user.EndDate,
+		AvatarURL:  avatarURL("v1", user),
+	}
+}
+
+// ToModel - user to be created from API v2 request.
+func (r CreateUserV2) ToModel() models.UserInfo {
+	return models.UserInfo{
+		Username:   r.Username,
+		FirstName:  r.Name.First,
+		MiddleName: r.Name.Middle,
+		LastName:   r.Name.Last,
+		Email:      r.Email,
+		Gender:     r.Gender,
+		Age:        r.Age,
+		Attributes: r.Attributes,
+	}
+}
+
+// ToModel - new state of user with id from API v2 request.
+func (r UpdateUserV2) ToModel(id int64) models.UserInfo {
+	return models.UserInfo{
+		ID:         id,
+		Username:   r.Username,
+		FirstName:  r.Name.First,
+		MiddleName: r.Name.Middle,
+		LastName:   r.Name.Last,
+		Email:      r.Email,
+		Gender:     r.Gender,
+		Age:        r.Age,
+		Attributes: r.Attributes,
+	}
+}
+
+// NewUserV2 - user in API v2 representation.
+func NewUserV2(user *models.UserInfo) UserV2 {
+	out := UserV2{
+		ID:       user.ID,
+		Username: user.Username,
+		Name: NameV2{
+			First:  user.FirstName,
+			Middle: user.MiddleName,
+			Last:   user.LastName,
+		},
+		Email:      user.Email,
+		Gender:     user.Gender,
+		Age:        user.Age,
+		Attributes: user.Attributes,
+		CreatedAt:  user.CreatedAt,
+		UpdatedAt:  user.UpdatedAt,
+		DeletedAt:  user.EndDate,
+		AvatarURL:  avatarURL("v2", user),
+	}
+	if out.Attributes == nil {
+		out.Attributes = map[string]any{}
+	}
+	return out
+}
+
+// NewBatchGetUsersV1 - result of batch get in API v1 representation.
+func NewBatchGetUsersV1(users []*models.UserInfo, missingIDs, deletedIDs []int64) BatchGetUsersV1 {
+	result := BatchGetUsersV1{
+		Users:      make([]UserV1, 0, len(users)),
+		MissingIDs: nonNil(missingIDs),
+		DeletedIDs: nonNil(deletedIDs),
+	}
+	for _, user := range users {
+		result.Users = append(result.Users, NewUserV1(user))
+	}
+	return result
+}
+
+// NewBatchGetUsersV2 - result of batch get in API v2 representation.
+func NewBatchGetUsersV2(users []*models.UserInfo, missingIDs, deletedIDs []int64) BatchGetUsersV2 {
+	result := BatchGetUsersV2{
+		Users:      make([]UserV2, 0, len(users)),
+		MissingIDs: nonNil(missingIDs),
+		DeletedIDs: nonNil(deletedIDs),
+	}
+	for _, user := range users {
+		result.Users = append(result.Users, NewUserV2(user))
+	}
+	return result
+}
+
+// nonNil - empty list is sent as [] instead of null.
+func nonNil(ids []int64) []int64 {
+	if ids == nil {
+		return []int64{}
+	}
+	return ids
+}
+
+// searchFieldsV2 - paths of searched fields in UserV2.
+var searchFieldsV2 = map[models.SearchField]string{
+	models.SearchFieldUsername:   "username",
+	models.SearchFieldFirstName:  "name.first",
+	models.SearchFieldMiddleName: "name.middle",
+	models.SearchFieldLastName:   "name.last",
+	models.SearchFieldEmail:      "email",
+}
+
+// NewSearchUsersV1 - page of users search in API v1 representation.
+func NewSearchUsersV1(hits []models.UserSearchHit, nextOffset *int) SearchUsersV1 {
+	result := SearchUsersV1{
+		Results:    make([]SearchResultV1, 0, len(hits)),
+		NextOffset: nextOffset,
+	}
+	for i := range hits {
+		highlights := make(map[string]string, len(hits[i].Highlights))
+		for field, value := range hits[i].Highlights {
+			highlights[string(field)] = value
+		}
+		result.Results = append(result.Results, SearchResultV1{
+			User:       NewUserV1(&hits[i].User),
+			Rank:       hits[i].Rank,
+			Highlights: highlights,
+		})
+	}
+	return result
+}
+
+// NewSearchUsersV2 - page of users search in API v2 representation.
+func NewSearchUsersV2(hits []models.UserSearchHit, nextOffset *int) SearchUsersV2 {
+	result := SearchUsersV2{
+		Results:    make([]SearchResultV2, 0, len(hits)),
+		NextOffset: nextOffset,
+	}
+	for i := range hits {
+		highlights := make(map[string]string, len(hits[i].Highlights))
+		for field, value := range hits[i].Highlights {
+			highlights[searchFieldsV2[field]] = value
+		}
+		result.Results = append(result.Results, SearchResultV2{
+			User:       NewUserV2(&hits[i].User),
+			Rank:       hits[i].Rank,
+			Highlights: highlights,
+		})
+	}
+	return result
+}
+
+// AvatarURL - link to avatar of user with version in API version apiVersion, the version changes
+// with every upload, so the link can be cached.
+func AvatarURL(apiVersion string, userID int64, avatarVersion string) string {
+	return fmt.Sprintf("/%s/users/%d/avatar?v=%s", apiVersion, userID, avatarVersion)
+}
+
+// avatarURL - link to avatar of user, empty for user without avatar and for deleted one.
+func avatarURL(apiVersion string, user *models.UserInfo) string {
+	if user.AvatarVersion == "" || user.EndDate != nil {
+		return ""
+	}
+	return AvatarURL(apiVersion, user.ID, user.AvatarVersion)
+}
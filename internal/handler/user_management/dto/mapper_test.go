@@ -0,0 +1,108 @@
+package dto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCreateUser_IgnoresID tests that id sent by client on creation does not reach the model
+func TestCreateUser_IgnoresID(t *testing.T) {
+	body := []byte(`{"id":42,"username":"jdoe","name":{"first":"John","last":"Doe"},"email":"jdoe@example.com"}`)
+
+	var v1 CreateUserV1
+	require.NoError(t, json.Unmarshal(body, &v1))
+	assert.Zero(t, v1.ToModel().ID)
+
+	var v2 CreateUserV2
+	require.NoError(t, json.Unmarshal(body, &v2))
+	user := v2.ToModel()
+	assert.Zero(t, user.ID)
+	assert.Equal(t, "John", user.FirstName)
+	assert.Equal(t, "Doe", user.LastName)
+}
+
+// TestUpdateUser_UsesPathID tests that id of updated user is taken from the route
+func TestUpdateUser_UsesPathID(t *testing.T) {
+	assert.Equal(t, int64(7), UpdateUserV1{Username: "jdoe"}.ToModel(7).ID)
+	assert.Equal(t, int64(7), UpdateUserV2{Username: "jdoe"}.ToModel(7).ID)
+}
+
+// TestNewUser_Timestamps tests that stored timestamps are exposed and deletion time is named deleted_at
+func TestNewUser_Timestamps(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	updated := created.Add(time.Hour)
+	deleted := created.Add(2 * time.Hour)
+	user := &models.UserInfo{
+		ID:        1,
+		Username:  "jdoe",
+		FirstName: "John",
+		LastName:  "Doe",
+		CreatedAt: created,
+		UpdatedAt: &updated,
+		EndDate:   &deleted,
+	}
+
+	body, err := json.Marshal(NewUserV1(user))
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"created_at":"2026-01-02T03:04:05Z"`)
+	assert.Contains(t, string(body), `"updated_at":"2026-01-02T04:04:05Z"`)
+	assert.Contains(t, string(body), `"deleted_at":"2026-01-02T05:04:05Z"`)
+
+	active := *user
+	active.UpdatedAt, active.EndDate = nil, nil
+	body, err = json.Marshal(NewUserV2(&active))
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "updated_at")
+	assert.NotContains(t, string(body), "deleted_at")
+	assert.Contains(t, string(body), `"name":{"first":"John","last":"Doe"}`)
+}
+
+// TestNewSearchUsers_Highlights tests that highlights are keyed by fields of the version
+func TestNewSearchUsers_Highlights(t *testing.T) {
+	hits := []models.UserSearchHit{{
+		User: models.UserInfo{ID: 1, Username: "jdoe", FirstName: "John", LastName: "Doe"},
+		Rank: 0.5,
+		Highlights: map[models.SearchField]string{
+			models.SearchFieldUsername:  "<mark>jdoe</mark>",
+			models.SearchFieldFirstName: "<mark>Jo</mark>hn",
+		},
+	}}
+
+	v1 := NewSearchUsersV1(hits, nil)
+	require.Len(t, v1.Results, 1)
+	assert.Equal(t, map[string]string{"username": "<mark>jdoe</mark>", "first_name": "<mark>Jo</mark>hn"}, v1.Results[0].Highlights)
+
+	next := 10
+	v2 := NewSearchUsersV2(hits, &next)
+	require.Len(t, v2.Results, 1)
+	assert.Equal(t, map[string]string{"username": "<mark>jdoe</mark>", "name.first": "<mark>Jo</mark>hn"}, v2.Results[0].Highlights)
+	assert.Equal(t, &next, v2.NextOffset)
+}
+
+// TestUserV2_Attributes tests that missing attributes of update stay nil and v1 has no attributes
+func TestUserV2_Attributes(t *testing.T) {
+	var update UpdateUserV2
+	require.NoError(t, json.Unmarshal([]byte(`{"username":"jdoe"}`), &update))
+	assert.Nil(t, update.ToModel(1).Attributes)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"username":"jdoe","attributes":{}}`), &update))
+	assert.Equal(t, map[string]any{}, update.ToModel(1).Attributes)
+
+	user := &models.UserInfo{ID: 1, Attributes: map[string]any{"locale": "ru"}}
+	body, err := json.Marshal(NewUserV2(user))
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"attributes":{"locale":"ru"}`)
+
+	body, err = json.Marshal(NewUserV1(user))
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "attributes")
+
+	body, err = json.Marshal(NewUserV2(&models.UserInfo{ID: 2}))
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"attributes":{}`)
+}
@@ -0,0 +1,23 @@
+package dto
+
+import "github.com/sonikq/gravitum_test_task/internal/models"
+
+// UsernameAvailability - result of username check, same in all versions.
+type UsernameAvailability struct {
+	Username    string   `json:"username"`
+	Available   bool     `json:"available"`
+	Suggestions []string `json:"suggestions"`
+}
+
+// NewUsernameAvailability - result of username check in API representation.
+func NewUsernameAvailability(availability models.UsernameAvailability) UsernameAvailability {
+	suggestions := availability.Suggestions
+	if suggestions == nil {
+		suggestions = []string{}
+	}
+	return UsernameAvailability{
+		Username:    availability.Username,
+		Available:   availability.Available,
+		Suggestions: suggestions,
+	}
+}
@@ -0,0 +1,61 @@
+package dto
+
+import "time"
+
+// CreateUserV1 - request of user creation in API v1.
+type CreateUserV1 struct {
+	Username   string `json:"username"`
+	FirstName  string `json:"first_name"`
+	MiddleName string `json:"middle_name,omitempty"`
+	LastName   string `json:"last_name"`
+	Email      string `json:"email"`
+	Gender     string `json:"gender"`
+	Age        uint8  `json:"age"`
+}
+
+// UpdateUserV1 - request of user update in API v1, all fields are replaced.
+type UpdateUserV1 struct {
+	Username   string `json:"username"`
+	FirstName  string `json:"first_name"`
+	MiddleName string `json:"middle_name,omitempty"`
+	LastName   string `json:"last_name"`
+	Email      string `json:"email"`
+	Gender     string `json:"gender"`
+	Age        uint8  `json:"age"`
+}
+
+// UserV1 - user in API v1, flat shape the API had before versioning.
+type UserV1 struct {
+	ID         int64      `json:"id"`
+	Username   string     `json:"username"`
+	FirstName  string     `json:"first_name"`
+	MiddleName string     `json:"middle_name,omitempty"`
+	LastName   string     `json:"last_name"`
+	Email      string     `json:"email"`
+	Gender     string     `json:"gender"`
+	Age        uint8      `json:"age"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+	AvatarURL  string     `json:"avatar_url,omitempty"`
+}
+
+// BatchGetUsersV1 - users found by ids in API v1.
+type BatchGetUsersV1 struct {
+	Users      []UserV1 `json:"users"`
+	MissingIDs []int64  `json:"missing_ids"`
+	DeletedIDs []int64  `json:"deleted_ids"`
+}
+
+// SearchResultV1 - user found by search in API v1, highlights are keyed by field names of UserV1.
+type SearchResultV1 struct {
+	User       UserV1            `json:"user"`
+	Rank       float64           `json:"rank"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+// SearchUsersV1 - page of users search in API v1, next offset is absent on the last page.
+type SearchUsersV1 struct {
+	Results    []SearchResultV1 `json:"results"`
+	NextOffset *int             `json:"next_offset,omitempty"`
+}
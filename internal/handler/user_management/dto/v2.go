@@ -0,0 +1,70 @@
+package dto
+
+import "time"
+
+// NameV2 - full name of user in API v2.
+type NameV2 struct {
+	First  string `json:"first"`
+	Middle string `json:"middle,omitempty"`
+	Last   string `json:"last"`
+}
+
+// CreateUserV2 - request of user creation in API v2.
+type CreateUserV2 struct {
+	Username   string         `json:"username"`
+	Name       NameV2         `json:"name"`
+	Email      string         `json:"email"`
+	Gender     string         `json:"gender"`
+	Age        uint8          `json:"age"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// UpdateUserV2 - request of user update in API v2, all fields are replaced, missing attributes are kept.
+type UpdateUserV2 struct {
+	Username   string         `json:"username"`
+	Name       NameV2         `json:"name"`
+	Email      string         `json:"email"`
+	Gender     string         `json:"gender"`
+	Age        uint8          `json:"age"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// UserV2 - user in API v2, parts of the name are grouped.
+type UserV2 struct {
+	ID         int64          `json:"id"`
+	Username   string         `json:"username"`
+	Name       NameV2         `json:"name"`
+	Email      string         `json:"email"`
+	Gender     string         `json:"gender"`
+	Age        uint8          `json:"age"`
+	Attributes map[string]any `json:"attributes"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  *time.Time     `json:"updated_at,omitempty"`
+	DeletedAt  *time.Time     `json:"deleted_at,omitempty"`
+	AvatarURL  string         `json:"avatar_url,omitempty"`
+}
+
+// CreatedV2 - response of user creation in API v2.
+type CreatedV2 struct {
+	ID int64 `json:"id"`
+}
+
+// BatchGetUsersV2 - users found by ids in API v2.
+type BatchGetUsersV2 struct {
+	Users      []UserV2 `json:"users"`
+	MissingIDs []int64  `json:"missing_ids"`
+	DeletedIDs []int64  `json:"deleted_ids"`
+}
+
+// SearchResultV2 - user found by search in API v2, highlights are keyed by paths of fields in UserV2, e.g. name.first.
+type SearchResultV2 struct {
+	User       UserV2            `json:"user"`
+	Rank       float64           `json:"rank"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+// SearchUsersV2 - page of users search in API v2, next offset is absent on the last page.
+type SearchUsersV2 struct {
+	Results    []SearchResultV2 `json:"results"`
+	NextOffset *int             `json:"next_offset,omitempty"`
+}
@@ -0,0 +1,83 @@
+package user_management
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/gravitum_test_task/internal/handler/user_management/dto"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"net/http"
+)
+
+// contentTypeZip - media type of data export archive.
+const contentTypeZip = "application/zip"
+
+// ExportUser - ZIP archive of everything stored about user with id from path, deleted user included,
+// see dto.WriteExportArchive. Missing user is answered with 204 and erased one with 410.
+func (h *Handler) ExportUser(ctx *gin.Context) {
+	const source = "handler.ExportUser"
+
+	userID, ok := h.pathID(ctx, source, "id", "user_id")
+	if !ok {
+		return
+	}
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	export, err := h.service.ExportUser(c, userID)
+	if err != nil {
+		h.abortWithErasureError(ctx, source, err, "failed to export user")
+		return
+	}
+
+	// archive is built before answering, so its failure is still answered with 500
+	var archive bytes.Buffer
+	if err = dto.WriteExportArchive(&archive, export); err != nil {
+		h.abortWithErasureError(ctx, source, err, "failed to write export archive")
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="user-%d-export.zip"`, userID))
+	ctx.Data(http.StatusOK, contentTypeZip, archive.Bytes())
+}
+
+// EraseUser - erasing personal data of active or deleted user with id from path, it can not be undone.
+// Missing user is answered with 204 and already erased one with 410.
+func (h *Handler) EraseUser(ctx *gin.Context) {
+	const source = "handler.EraseUser"
+
+	userID, ok := h.pathID(ctx, source, "id", "user_id")
+	if !ok {
+		return
+	}
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	if err := h.service.EraseUser(c, userID); err != nil {
+		h.abortWithErasureError(ctx, source, err, "failed to erase user")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "success"})
+}
+
+// abortWithErasureError - answering with status of error of export and erasure, unknown error is internal.
+func (h *Handler) abortWithErasureError(ctx *gin.Context, source string, err error, logMsg string) {
+	statusCode, userMsg := http.StatusInternalServerError, "internal server error, something went wrong"
+	switch {
+	case errors.Is(err, models.ErrUserDoesNotExist):
+		statusCode, userMsg = http.StatusNoContent, models.ErrUserDoesNotExist.Error()
+	case errors.Is(err, models.ErrUserIsErased):
+		statusCode, userMsg = http.StatusGone, models.ErrUserIsErased.Error()
+	}
+
+	ctx.AbortWithStatusJSON(statusCode, gin.H{models.ErrMsgKey: userMsg})
+	h.logger.Error().
+		Err(err).
+		Str("source", source).
+		Msg(logMsg)
+}
@@ -22,7 +22,7 @@ func (h *Handler) GetUser(ctx *gin.Context) {
 		return
 	}
 
-	c, cancel := context.WithTimeout(ctx, h.config.CtxTimeOut)
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
 	defer cancel()
 
 	userInfo, err := h.service.GetUser(c, int64(userID))
@@ -54,5 +54,15 @@ func (h *Handler) GetUser(ctx *gin.Context) {
 			Msg(logMsg)
 		return
 	}
-	ctx.JSON(http.StatusOK, userInfo)
+
+	version := negotiation(ctx)
+	etag, modified := version.entityTag(userInfo), lastModified(userInfo)
+	ctx.Header("ETag", etag)
+	ctx.Header("Last-Modified", modified.Format(http.TimeFormat))
+	if notModified(ctx.Request, etag, modified) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	version.writeUser(ctx, userInfo)
 }
@@ -0,0 +1,341 @@
+package user_management
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/sonikq/gravitum_test_task/internal/handler/user_management/dto"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"github.com/sonikq/gravitum_test_task/pkg/reader"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CreateGroup - creating group from request body, created group is sent back.
+func (h *Handler) CreateGroup(ctx *gin.Context) {
+	const source = "handler.CreateGroup"
+
+	var request dto.GroupRequest
+	if !h.readJSON(ctx, source, &request) {
+		return
+	}
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	group, err := h.service.CreateGroup(c, request.ToModel(0))
+	if err != nil {
+		h.abortWithGroupError(ctx, source, err, "failed to create group")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.NewGroup(group))
+}
+
+// ListGroups - page of groups ordered by name, query parameters are limit and offset.
+func (h *Handler) ListGroups(ctx *gin.Context) {
+	const source = "handler.ListGroups"
+
+	page, ok := h.page(ctx, source)
+	if !ok {
+		return
+	}
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	groups, err := h.service.ListGroups(c, page)
+	if err != nil {
+		h.abortWithGroupError(ctx, source, err, "failed to list groups")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.NewGroups(groups, nextOffset(page, len(groups))))
+}
+
+// GetGroup - group with id from path.
+func (h *Handler) GetGroup(ctx *gin.Context) {
+	const source = "handler.GetGroup"
+
+	groupID, ok := h.pathID(ctx, source, "id", "group_id")
+	if !ok {
+		return
+	}
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	group, err := h.service.GetGroup(c, groupID)
+	if err != nil {
+		h.abortWithGroupError(ctx, source, err, "failed to get group")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.NewGroup(group))
+}
+
+// UpdateGroup - replacing name and description of group with id from path.
+func (h *Handler) UpdateGroup(ctx *gin.Context) {
+	const source = "handler.UpdateGroup"
+
+	groupID, ok := h.pathID(ctx, source, "id", "group_id")
+	if !ok {
+		return
+	}
+
+	var request dto.GroupRequest
+	if !h.readJSON(ctx, source, &request) {
+		return
+	}
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	if err := h.service.UpdateGroup(c, request.ToModel(groupID)); err != nil {
+		h.abortWithGroupError(ctx, source, err, "failed to update group")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "success"})
+}
+
+// DeleteGroup - deleting group with id from path, its members stay as they are.
+func (h *Handler) DeleteGroup(ctx *gin.Context) {
+	const source = "handler.DeleteGroup"
+
+	groupID, ok := h.pathID(ctx, source, "id", "group_id")
+	if !ok {
+		return
+	}
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	if err := h.service.DeleteGroup(c, groupID); err != nil {
+		h.abortWithGroupError(ctx, source, err, "failed to delete group")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "success"})
+}
+
+// ListGroupMembers - page of active members of group ordered by user id, query parameters are limit and offset.
+func (h *Handler) ListGroupMembers(ctx *gin.Context) {
+	const source = "handler.ListGroupMembers"
+
+	groupID, ok := h.pathID(ctx, source, "id", "group_id")
+	if !ok {
+		return
+	}
+	page, ok := h.page(ctx, source)
+	if !ok {
+		return
+	}
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	members, err := h.service.ListGroupMembers(c, groupID, page)
+	if err != nil {
+		h.abortWithGroupError(ctx, source, err, "failed to list group members")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.NewGroupMembers(members, nextOffset(page, len(members))))
+}
+
+// PutGroupMember - adding user to group with role from request body or changing role of member.
+func (h *Handler) PutGroupMember(ctx *gin.Context) {
+	const source = "handler.PutGroupMember"
+
+	groupID, ok := h.pathID(ctx, source, "id", "group_id")
+	if !ok {
+		return
+	}
+	userID, ok := h.pathID(ctx, source, "user_id", "user_id")
+	if !ok {
+		return
+	}
+
+	var request dto.MembershipRequest
+	if !h.readJSON(ctx, source, &request) {
+		return
+	}
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	created, err := h.service.PutGroupMember(c, models.Membership{
+		GroupID: groupID,
+		UserID:  userID,
+		Role:    models.GroupRole(request.Role),
+	})
+	if err != nil {
+		h.abortWithGroupError(ctx, source, err, "failed to put group member")
+		return
+	}
+
+	if created {
+		ctx.JSON(http.StatusCreated, gin.H{"message": "created"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "updated"})
+}
+
+// DeleteGroupMember - removing user from group.
+func (h *Handler) DeleteGroupMember(ctx *gin.Context) {
+	const source = "handler.DeleteGroupMember"
+
+	groupID, ok := h.pathID(ctx, source, "id", "group_id")
+	if !ok {
+		return
+	}
+	userID, ok := h.pathID(ctx, source, "user_id", "user_id")
+	if !ok {
+		return
+	}
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	if err := h.service.DeleteGroupMember(c, groupID, userID); err != nil {
+		h.abortWithGroupError(ctx, source, err, "failed to delete group member")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "success"})
+}
+
+// ListUserGroups - page of groups of user with id from path ordered by name, same in all versions.
+// Missing user is answered with 204 and deleted one with 410 as in GetUser.
+func (h *Handler) ListUserGroups(ctx *gin.Context) {
+	const source = "handler.ListUserGroups"
+
+	userID, ok := h.pathID(ctx, source, "id", "user_id")
+	if !ok {
+		return
+	}
+	page, ok := h.page(ctx, source)
+	if !ok {
+		return
+	}
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	groups, err := h.service.ListUserGroups(c, userID, page)
+	if err != nil {
+		statusCode, userMsg := http.StatusInternalServerError, "internal server error, something went wrong"
+		switch {
+		case errors.Is(err, models.ErrUserDoesNotExist):
+			statusCode, userMsg = http.StatusNoContent, models.ErrUserDoesNotExist.Error()
+		case errors.Is(err, models.ErrUserIsGone):
+			statusCode, userMsg = http.StatusGone, models.ErrUserIsGone.Error()
+		case errors.Is(err, models.ErrInvalidPagination):
+			statusCode, userMsg = http.StatusBadRequest, err.Error()
+		}
+
+		ctx.AbortWithStatusJSON(statusCode, gin.H{models.ErrMsgKey: userMsg})
+		h.logger.Error().
+			Err(err).
+			Str("source", source).
+			Msg("failed to list user groups")
+		return
+	}
+
+	negotiation(ctx).setContentType(ctx)
+	ctx.JSON(http.StatusOK, dto.NewUserGroups(groups, nextOffset(page, len(groups))))
+}
+
+// abortWithGroupError - answering with status of error of groups, unknown one is internal.
+func (h *Handler) abortWithGroupError(ctx *gin.Context, source string, err error, logMsg string) {
+	var statusCode int
+	switch {
+	case errors.Is(err, models.ErrInvalidGroup), errors.Is(err, models.ErrInvalidGroupRole),
+		errors.Is(err, models.ErrInvalidPagination):
+		statusCode = http.StatusBadRequest
+	case errors.Is(err, models.ErrGroupDoesNotExist), errors.Is(err, models.ErrMemberDoesNotExist),
+		errors.Is(err, models.ErrUserDoesNotExist):
+		statusCode = http.StatusNotFound
+	case errors.Is(err, models.ErrGroupNameIsAlreadyTaken):
+		statusCode = http.StatusConflict
+	case errors.Is(err, models.ErrUserIsGone):
+		statusCode = http.StatusGone
+	default:
+		statusCode = http.StatusInternalServerError
+	}
+
+	userMsg := "internal server error, something went wrong"
+	if statusCode != http.StatusInternalServerError {
+		userMsg = err.Error()
+	}
+
+	ctx.AbortWithStatusJSON(statusCode, gin.H{models.ErrMsgKey: userMsg})
+	h.logger.Error().
+		Err(err).
+		Str("source", source).
+		Msg(logMsg)
+}
+
+// pathID - integer id from path parameter, invalid one is answered with 400 named as field.
+func (h *Handler) pathID(ctx *gin.Context, source, param, field string) (int64, bool) {
+	value := ctx.Param(param)
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{models.ErrMsgKey: "Invalid type of " + field})
+		h.logger.Error().
+			Str("error", "invalid type of "+field+": "+value).
+			Str("source", source).
+			Send()
+		return 0, false
+	}
+	return id, true
+}
+
+// page - page from query parameters, invalid one is answered with 400.
+func (h *Handler) page(ctx *gin.Context, source string) (models.Page, bool) {
+	page, err := pageParams(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{models.ErrMsgKey: err.Error()})
+		h.logger.Error().
+			Err(err).
+			Str("source", source).
+			Msg("invalid pagination parameters")
+		return page, false
+	}
+	return page, true
+}
+
+// readJSON - parsing JSON request body into dst, failure is answered as in user handlers.
+func (h *Handler) readJSON(ctx *gin.Context, source string, dst any) bool {
+	if !strings.HasPrefix(ctx.GetHeader(contentTypeHeaderKey), contentTypeJSON) {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{models.ErrMsgKey: "Invalid type of content"})
+		h.logger.Error().
+			Str("error", "invalid content type").
+			Str("source", source).
+			Send()
+		return false
+	}
+
+	bodyBytes, err := reader.GetBody(ctx.Request.Body)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{models.ErrMsgKey: "Error in reading request body"})
+		h.logger.Error().
+			Err(err).
+			Str("source", source).
+			Msg("failed to read request body")
+		return false
+	}
+
+	if err = json.Unmarshal(bodyBytes, dst); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{models.ErrMsgKey: "Error in parsing request body"})
+		h.logger.Error().
+			Err(err).
+			Str("source", source).
+			Msg("failed to unmarshal request body")
+		return false
+	}
+	return true
+}
@@ -13,13 +13,13 @@ const (
 )
 
 type Handler struct {
-	config  config.Config
+	config  *config.Store
 	logger  *logger.Logger
 	service *service.Service
 }
 
 type HandlerConfig struct {
-	Config  config.Config
+	Config  *config.Store
 	Logger  *logger.Logger
 	Service *service.Service
 }
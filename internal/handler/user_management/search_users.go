@@ -0,0 +1,97 @@
+package user_management
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+)
+
+const (
+	defaultPageLimit  = 20
+	maxPageLimit      = 100
+	maxSearchQueryLen = 200
+)
+
+// SearchUsers - full-text search by names and fuzzy search by username and email among active users.
+// Query parameters are q, limit and offset, the page has next_offset unless it is the last one.
+func (h *Handler) SearchUsers(ctx *gin.Context) {
+	const source = "handler.SearchUsers"
+
+	search, err := searchParams(ctx)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{models.ErrMsgKey: err.Error()})
+		h.logger.Error().
+			Err(err).
+			Str("source", source).
+			Msg("invalid search parameters")
+		return
+	}
+
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
+	defer cancel()
+
+	hits, err := h.service.SearchUsers(c, search)
+	if err != nil {
+		statusCode, userMsg := http.StatusInternalServerError, "internal server error, something went wrong"
+		if errors.Is(err, models.ErrInvalidSearchQuery) || errors.Is(err, models.ErrInvalidPagination) {
+			statusCode, userMsg = http.StatusBadRequest, err.Error()
+		}
+
+		ctx.AbortWithStatusJSON(statusCode, gin.H{models.ErrMsgKey: userMsg})
+		h.logger.Error().
+			Err(err).
+			Str("source", source).
+			Msg("failed to search users")
+		return
+	}
+
+	negotiation(ctx).writeSearch(ctx, hits, nextOffset(models.Page{Limit: search.Limit, Offset: search.Offset}, len(hits)))
+}
+
+// searchParams - search from query parameters, limit above maximum is lowered to it.
+func searchParams(ctx *gin.Context) (models.UserSearch, error) {
+	search := models.UserSearch{Query: ctx.Query("q")}
+	if utf8.RuneCountInString(search.Query) > maxSearchQueryLen {
+		return search, errors.New("q is too long, maximum is " + strconv.Itoa(maxSearchQueryLen) + " characters")
+	}
+
+	page, err := pageParams(ctx)
+	search.Limit, search.Offset = page.Limit, page.Offset
+	return search, err
+}
+
+// pageParams - page from limit and offset query parameters, limit above maximum is lowered to it.
+func pageParams(ctx *gin.Context) (models.Page, error) {
+	page := models.Page{Limit: defaultPageLimit}
+
+	if value := ctx.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return page, errors.New("limit must be a positive integer")
+		}
+		page.Limit = min(limit, maxPageLimit)
+	}
+
+	if value := ctx.Query("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return page, errors.New("offset must be a non-negative integer")
+		}
+		page.Offset = offset
+	}
+
+	return page, nil
+}
+
+// nextOffset - offset of the page after the one with n items, nil if it is the last one.
+func nextOffset(page models.Page, n int) *int {
+	if n < page.Limit {
+		return nil
+	}
+	next := page.Offset + page.Limit
+	return &next
+}
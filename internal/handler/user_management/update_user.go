@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"github.com/gin-gonic/gin"
-	"github.com/goccy/go-json"
 	"github.com/sonikq/gravitum_test_task/internal/models"
 	"github.com/sonikq/gravitum_test_task/pkg/reader"
 	"net/http"
@@ -25,7 +24,8 @@ func (h *Handler) UpdateUser(ctx *gin.Context) {
 		return
 	}
 
-	if ctx.GetHeader(contentTypeHeaderKey) != contentTypeJSON {
+	version := negotiation(ctx)
+	if !version.validContentType(ctx.GetHeader(contentTypeHeaderKey)) {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{models.ErrMsgKey: "Invalid type of content"})
 		h.logger.Error().
 			Str("error", "invalid content type").
@@ -44,11 +44,11 @@ func (h *Handler) UpdateUser(ctx *gin.Context) {
 		return
 	}
 
-	c, cancel := context.WithTimeout(ctx, h.config.CtxTimeOut)
+	c, cancel := context.WithTimeout(ctx, h.config.Current().CtxTimeOut)
 	defer cancel()
 
-	var request models.UserInfo
-	if err = json.Unmarshal(bodyBytes, &request); err != nil {
+	request, err := version.decodeUpdate(bodyBytes, int64(userID))
+	if err != nil {
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{models.ErrMsgKey: "Error in parsing request body"})
 		h.logger.Error().
 			Err(err).
@@ -56,13 +56,13 @@ func (h *Handler) UpdateUser(ctx *gin.Context) {
 			Msg("failed to unmarshal request body")
 		return
 	}
-	request.ID = int64(userID)
 
 	err = h.service.UpdateUser(c, request)
 	if err != nil {
 		var (
 			statusCode int
 			userMsg    string
+			errCode    string
 			logMsg     = "failed to update user"
 		)
 
@@ -75,10 +75,36 @@ func (h *Handler) UpdateUser(ctx *gin.Context) {
 			statusCode = http.StatusGone
 			userMsg, logMsg = models.ErrUserIsGone.Error(), "failed to update deleted user"
 
+		case errors.Is(err, models.ErrUsernameIsAlreadyTaken):
+			statusCode = http.StatusConflict
+			userMsg = models.ErrUsernameIsAlreadyTaken.Error()
+
+		case errors.Is(err, models.ErrEmailIsAlreadyTaken):
+			statusCode = http.StatusConflict
+			userMsg = models.ErrEmailIsAlreadyTaken.Error()
+
+		case errors.Is(err, models.ErrEmailDomainNotAllowed):
+			statusCode = http.StatusUnprocessableEntity
+			userMsg, logMsg = models.ErrEmailDomainNotAllowed.Error(), "email domain is out of policy"
+			errCode = models.ErrCodeEmailDomainNotAllowed
+
 		case errors.Is(err, models.ErrInvalidEmail):
 			statusCode = http.StatusBadRequest
 			userMsg, logMsg = models.ErrInvalidEmail.Error(), "failed to validate email"
 
+		case errors.Is(err, models.ErrInvalidUsername):
+			statusCode = http.StatusBadRequest
+			userMsg, logMsg = models.ErrInvalidUsername.Error(), "failed to validate username"
+
+		case errors.Is(err, models.ErrInvalidName):
+			statusCode = http.StatusBadRequest
+			userMsg, logMsg = models.ErrInvalidName.Error(), "failed to validate name"
+
+		case errors.Is(err, models.ErrInvalidAttributes):
+			// message tells which attribute is invalid and why
+			statusCode = http.StatusBadRequest
+			userMsg, logMsg = err.Error(), "failed to validate attributes"
+
 		case errors.Is(err, models.ErrInvalidAge):
 			statusCode = http.StatusBadRequest
 			userMsg, logMsg = models.ErrInvalidEmail.Error(), "failed to validate age"
@@ -92,7 +118,11 @@ func (h *Handler) UpdateUser(ctx *gin.Context) {
 			userMsg = "internal server error, something went wrong"
 		}
 
-		ctx.AbortWithStatusJSON(statusCode, gin.H{models.ErrMsgKey: userMsg})
+		body := gin.H{models.ErrMsgKey: userMsg}
+		if errCode != "" {
+			body[models.ErrCodeKey] = errCode
+		}
+		ctx.AbortWithStatusJSON(statusCode, body)
 		h.logger.Error().
 			Err(err).
 			Str("source", source).
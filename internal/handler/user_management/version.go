@@ -0,0 +1,239 @@
+package user_management
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/goccy/go-json"
+	"github.com/sonikq/gravitum_test_task/internal/config"
+	"github.com/sonikq/gravitum_test_task/internal/handler/user_management/dto"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Version - version of users representation in REST API.
+type Version string
+
+const (
+	V1 Version = "v1"
+	V2 Version = "v2"
+)
+
+// Versions - all served versions, the latest one is the last.
+var Versions = []Version{V1, V2}
+
+// Group - prefix of users routes and versions served under it, the first one is the default.
+type Group struct {
+	Prefix   string
+	Versions []Version
+}
+
+// Groups - all users routes, unversioned ones are aliases of v1 kept for the transition period.
+var Groups = []Group{
+	{Prefix: "/users", Versions: Versions},
+	{Prefix: "/v1/users", Versions: []Version{V1}},
+	{Prefix: "/v2/users", Versions: []Version{V2}},
+}
+
+const versionKey = "api_version"
+
+// MediaType - vendor media type which selects the version in Accept and Content-Type headers.
+func (v Version) MediaType() string {
+	return "application/vnd.user-management." + string(v) + "+json"
+}
+
+// negotiated - version chosen for the request and media type of its responses.
+type negotiated struct {
+	version   Version
+	mediaType string
+}
+
+// UseVersion - choosing representation of users among versions served by the route, the first one is the default.
+// Vendor media type in Accept, or in Content-Type if Accept has none, selects another version,
+// and responses are sent with it. Deprecated version gets Deprecation, Sunset and successor Link headers.
+func (h *Handler) UseVersion(versions ...Version) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		const source = "handler.UseVersion"
+
+		chosen := negotiated{version: versions[0], mediaType: contentTypeJSON}
+
+		requested, ok := requestedVersion(ctx.GetHeader("Accept"))
+		if !ok {
+			requested, ok = requestedVersion(ctx.GetHeader(contentTypeHeaderKey))
+		}
+		if ok {
+			if !containsVersion(versions, requested) {
+				ctx.AbortWithStatusJSON(http.StatusNotAcceptable, gin.H{
+					models.ErrMsgKey: fmt.Sprintf("API version %s is not served by this route", requested),
+				})
+				h.logger.Error().
+					Str("error", "unsupported api version: "+string(requested)).
+					Str("source", source).
+					Send()
+				return
+			}
+			chosen = negotiated{version: requested, mediaType: requested.MediaType()}
+		}
+
+		if len(versions) > 1 {
+			ctx.Header("Vary", "Accept, Content-Type")
+		}
+		if chosen.version == V1 {
+			h.deprecate(ctx)
+		}
+
+		ctx.Set(versionKey, chosen)
+		ctx.Next()
+	}
+}
+
+// deprecate - marking response of API v1 as deprecated according to current config.
+func (h *Handler) deprecate(ctx *gin.Context) {
+	cfg := h.config.Current()
+
+	// dates are checked on config load
+	deprecatedAt, _ := config.ParseDate(cfg.APIV1DeprecatedAt)
+	if deprecatedAt.IsZero() {
+		return
+	}
+	ctx.Header("Deprecation", "@"+strconv.FormatInt(deprecatedAt.Unix(), 10))
+
+	if sunset, _ := config.ParseDate(cfg.APIV1Sunset); !sunset.IsZero() {
+		ctx.Header("Sunset", sunset.Format(http.TimeFormat))
+	}
+
+	path := ctx.Request.URL.Path
+	successor := "/" + string(Versions[len(Versions)-1]) + strings.TrimPrefix(path, "/"+string(V1))
+	ctx.Header("Link", "<"+successor+`>; rel="successor-version"`)
+}
+
+// requestedVersion - version named by vendor media type in Accept or Content-Type header.
+func requestedVersion(header string) (Version, bool) {
+	for _, mediaType := range strings.Split(header, ",") {
+		mediaType, _, _ = strings.Cut(mediaType, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+		for _, v := range Versions {
+			if mediaType == v.MediaType() {
+				return v, true
+			}
+		}
+	}
+	return "", false
+}
+
+func containsVersion(versions []Version, v Version) bool {
+	for _, candidate := range versions {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiation - version chosen by UseVersion, routes without it are served as v1.
+func negotiation(ctx *gin.Context) negotiated {
+	if value, ok := ctx.Get(versionKey); ok {
+		return value.(negotiated)
+	}
+	return negotiated{version: V1, mediaType: contentTypeJSON}
+}
+
+// validContentType - whether request body is JSON of the chosen version.
+func (n negotiated) validContentType(contentType string) bool {
+	return contentType == contentTypeJSON || contentType == n.version.MediaType()
+}
+
+// decodeCreate - parsing user creation request of the chosen version.
+func (n negotiated) decodeCreate(body []byte) (models.UserInfo, error) {
+	switch n.version {
+	case V2:
+		var request dto.CreateUserV2
+		err := json.Unmarshal(body, &request)
+		return request.ToModel(), err
+	default:
+		var request dto.CreateUserV1
+		err := json.Unmarshal(body, &request)
+		return request.ToModel(), err
+	}
+}
+
+// decodeUpdate - parsing update request of user with id in the chosen version.
+func (n negotiated) decodeUpdate(body []byte, id int64) (models.UserInfo, error) {
+	switch n.version {
+	case V2:
+		var request dto.UpdateUserV2
+		err := json.Unmarshal(body, &request)
+		return request.ToModel(id), err
+	default:
+		var request dto.UpdateUserV1
+		err := json.Unmarshal(body, &request)
+		return request.ToModel(id), err
+	}
+}
+
+// writeUser - sending user in representation of the chosen version.
+func (n negotiated) writeUser(ctx *gin.Context, user *models.UserInfo) {
+	var body any
+	switch n.version {
+	case V2:
+		body = dto.NewUserV2(user)
+	default:
+		body = dto.NewUserV1(user)
+	}
+
+	n.setContentType(ctx)
+	ctx.JSON(http.StatusOK, body)
+}
+
+// writeCreated - sending id of created user, v1 sends it as plain text.
+func (n negotiated) writeCreated(ctx *gin.Context, id string) error {
+	if n.version == V1 {
+		ctx.Data(http.StatusCreated, contentTypeTextPlain, []byte(id))
+		return nil
+	}
+
+	userID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return errors.New("invalid id of created user: " + id)
+	}
+	n.setContentType(ctx)
+	ctx.JSON(http.StatusCreated, dto.CreatedV2{ID: userID})
+	return nil
+}
+
+// setContentType - answering with vendor media type if the client asked for it, gin sets plain JSON otherwise.
+func (n negotiated) setContentType(ctx *gin.Context) {
+	if n.mediaType != contentTypeJSON {
+		ctx.Header(contentTypeHeaderKey, n.mediaType)
+	}
+}
+
+// writeBatch - sending result of batch get in representation of the chosen version.
+func (n negotiated) writeBatch(ctx *gin.Context, users []*models.UserInfo, missingIDs, deletedIDs []int64) {
+	var body any
+	switch n.version {
+	case V2:
+		body = dto.NewBatchGetUsersV2(users, missingIDs, deletedIDs)
+	default:
+		body = dto.NewBatchGetUsersV1(users, missingIDs, deletedIDs)
+	}
+
+	n.setContentType(ctx)
+	ctx.JSON(http.StatusOK, body)
+}
+
+// writeSearch - sending page of users search in representation of the chosen version.
+func (n negotiated) writeSearch(ctx *gin.Context, hits []models.UserSearchHit, nextOffset *int) {
+	var body any
+	switch n.version {
+	case V2:
+		body = dto.NewSearchUsersV2(hits, nextOffset)
+	default:
+		body = dto.NewSearchUsersV1(hits, nextOffset)
+	}
+
+	n.setContentType(ctx)
+	ctx.JSON(http.StatusOK, body)
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+)
+
+// AvatarSize - name of square thumbnail avatar is stored in.
+type AvatarSize string
+
+const (
+	AvatarSmall  AvatarSize = "small"
+	AvatarMedium AvatarSize = "medium"
+	AvatarLarge  AvatarSize = "large"
+)
+
+// AvatarSizes - all sizes, the smallest first.
+var AvatarSizes = []AvatarSize{AvatarSmall, AvatarMedium, AvatarLarge}
+
+func (s AvatarSize) Valid() bool {
+	for _, size := range AvatarSizes {
+		if s == size {
+			return true
+		}
+	}
+	return false
+}
+
+// Avatar - metadata of avatar image of user in one size.
+type Avatar struct {
+	Version     string
+	Size        AvatarSize
+	ContentType string
+	Length      int64
+	ModTime     time.Time
+}
@@ -1,18 +1,58 @@
 package models
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 const (
-	ErrMsgKey = "error_description"
+	ErrMsgKey  = "error_description"
+	ErrCodeKey = "error_code"
+
+	// ErrCodeEmailDomainNotAllowed - machine readable code of ErrEmailDomainNotAllowed.
+	ErrCodeEmailDomainNotAllowed = "EMAIL_DOMAIN_NOT_ALLOWED"
 )
 
 var (
 	ErrTraceLayout            = "%s | error: %v"
+	ErrWrapTraceLayout        = "%s | error: %w"
 	ErrUsernameIsAlreadyTaken = errors.New("username is already taken")
+	ErrEmailIsAlreadyTaken    = errors.New("email is already taken")
 	ErrUserDoesNotExist       = errors.New("user not exist")
 	ErrInvalidEmail           = errors.New("invalid email")
+	ErrEmailDomainNotAllowed  = errors.New("email domain is not allowed")
+	ErrInvalidName            = errors.New("invalid name, letters, hyphens, apostrophes and spaces between words are allowed")
+	ErrInvalidUsername        = errors.New("invalid username, it must not be empty")
 	ErrInvalidGender          = errors.New("invalid gender, available is: F/M/O")
 	ErrInvalidAge             = errors.New("invalid age, the age must be greater than 1 and less than 150")
 	ErrUserIsGone             = errors.New("user is gone")
 	ErrDeleteDeletedUser      = errors.New("user has been deleted once")
+	ErrRestoreActiveUser      = errors.New("user is not deleted")
+	ErrPendingMigrations      = errors.New("database has pending migrations")
+	ErrInvalidSearchQuery     = errors.New("invalid search query, it must contain a letter or a digit")
+	ErrInvalidPagination      = errors.New("invalid pagination, limit and offset must not be negative")
+	ErrInvalidAttributes      = errors.New("invalid attributes")
+	ErrInvalidAttributeSchema = errors.New("invalid attribute schema")
+)
+
+var (
+	ErrAttributeSchemaDoesNotExist = errors.New("attribute schema does not exist")
+	ErrAttributeSchemaIsInUse      = errors.New("attribute schema is in use, users have the attribute")
+)
+
+var (
+	ErrInvalidGroup            = errors.New("invalid group")
+	ErrInvalidGroupRole        = errors.New("invalid group role, available is: owner/admin/member")
+	ErrGroupDoesNotExist       = errors.New("group does not exist")
+	ErrGroupNameIsAlreadyTaken = errors.New("group name is already taken")
+	ErrMemberDoesNotExist      = errors.New("user is not a member of the group")
+)
+
+var (
+	ErrInvalidAvatar      = errors.New("invalid avatar image")
+	ErrInvalidAvatarSize  = errors.New("invalid avatar size, available is: small/medium/large")
+	ErrAvatarDoesNotExist = errors.New("user has no avatar")
 )
+
+// ErrUserIsErased - personal data of user is erased, it is ErrUserIsGone too.
+var ErrUserIsErased = fmt.Errorf("%w, its personal data is erased", ErrUserIsGone)
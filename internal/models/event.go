@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// UserEventType - kind of change recorded in audit history of user.
+type UserEventType string
+
+const (
+	UserCreated  UserEventType = "created"
+	UserUpdated  UserEventType = "updated"
+	UserDeleted  UserEventType = "deleted"
+	UserRestored UserEventType = "restored"
+	UserErased   UserEventType = "erased"
+)
+
+// UserEvent - change of user in its audit history, avatar changes are updates.
+type UserEvent struct {
+	Type       UserEventType
+	OccurredAt time.Time
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// UserExport - everything stored about a user.
+type UserExport struct {
+	Tenant string
+	User   UserInfo
+	// History - audit history of the user, the oldest event first.
+	History []UserEvent
+	// Groups - all memberships, the ones hidden while user is deleted included.
+	Groups []UserGroup
+	// Avatar - images of avatar by size, empty for user without avatar.
+	Avatar     map[AvatarSize][]byte
+	ExportedAt time.Time
+}
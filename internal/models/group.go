@@ -0,0 +1,106 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	maxGroupNameLen        = 100
+	maxGroupDescriptionLen = 1000
+)
+
+// GroupRole - role of member inside a group.
+type GroupRole string
+
+const (
+	GroupRoleOwner  GroupRole = "owner"
+	GroupRoleAdmin  GroupRole = "admin"
+	GroupRoleMember GroupRole = "member"
+)
+
+// GroupRoles - all roles, the most privileged first.
+var GroupRoles = []GroupRole{GroupRoleOwner, GroupRoleAdmin, GroupRoleMember}
+
+func (r GroupRole) Valid() bool {
+	for _, role := range GroupRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Group - named set of users of a tenant, its name is unique case-insensitively.
+type Group struct {
+	ID          int64
+	Name        string
+	Description string
+	CreatedAt   time.Time
+	UpdatedAt   *time.Time
+}
+
+// Normalize - trimming spaces around name and description.
+func (g *Group) Normalize() {
+	g.Name = strings.TrimSpace(g.Name)
+	g.Description = strings.TrimSpace(g.Description)
+}
+
+func (g *Group) Validate() error {
+	if g.Name == "" || utf8.RuneCountInString(g.Name) > maxGroupNameLen || strings.IndexFunc(g.Name, unicode.IsControl) >= 0 {
+		return fmt.Errorf("%w: name must be 1 to %d characters without control ones", ErrInvalidGroup, maxGroupNameLen)
+	}
+
+	if utf8.RuneCountInString(g.Description) > maxGroupDescriptionLen {
+		return fmt.Errorf("%w: description must not be longer than %d characters", ErrInvalidGroup, maxGroupDescriptionLen)
+	}
+
+	return nil
+}
+
+// Membership - role of user in a group, member role is taken if it is empty.
+type Membership struct {
+	GroupID int64
+	UserID  int64
+	Role    GroupRole
+}
+
+func (m *Membership) Validate() error {
+	if !m.Role.Valid() {
+		return ErrInvalidGroupRole
+	}
+	return nil
+}
+
+// GroupMember - active user in a group, only id, username and names of user are set.
+type GroupMember struct {
+	User      UserInfo
+	Role      GroupRole
+	JoinedAt  time.Time
+	UpdatedAt *time.Time
+}
+
+// UserGroup - group user is a member of.
+type UserGroup struct {
+	Group     Group
+	Role      GroupRole
+	JoinedAt  time.Time
+	UpdatedAt *time.Time
+}
+
+// Page - limit and offset of a page of listing.
+type Page struct {
+	// Limit - maximal number of items, zero means no limit.
+	Limit  int
+	Offset int
+}
+
+func (p *Page) Validate() error {
+	if p.Limit < 0 || p.Offset < 0 {
+		return ErrInvalidPagination
+	}
+	return nil
+}
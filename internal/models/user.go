@@ -1,27 +1,107 @@
 package models
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sonikq/gravitum_test_task/pkg/attributes"
+	"github.com/sonikq/gravitum_test_task/pkg/emailpolicy"
 	"github.com/sonikq/gravitum_test_task/pkg/validator"
+	"golang.org/x/text/unicode/norm"
+	"slices"
+	"strings"
 	"time"
+	"unicode"
 )
 
+// UserInfo - user as it is stored, API representations are mapped from it in handlers.
 type UserInfo struct {
-	ID         int64      `json:"id"`
-	Username   string     `json:"username"`
-	FirstName  string     `json:"first_name"`
-	MiddleName string     `json:"middle_name,omitempty"`
-	LastName   string     `json:"last_name"`
-	Email      string     `json:"email"`
-	Gender     string     `json:"gender"`
-	Age        uint8      `json:"age"`
-	EndDate    *time.Time `json:"-"`
-}
-
-func (uf *UserInfo) Validate() error {
-	if !validator.ValidEmail(uf.Email) {
+	ID         int64
+	Username   string
+	FirstName  string
+	MiddleName string
+	LastName   string
+	Email      string
+	Gender     string
+	Age        uint8
+	// Attributes - values of attributes registered in attribute schemas, nil in update keeps the current ones.
+	Attributes map[string]any
+	CreatedAt  time.Time
+	UpdatedAt  *time.Time
+	// EndDate - time of deletion, nil for active user.
+	EndDate *time.Time
+	// AvatarVersion - version of avatar images in blob storage, empty for user without avatar.
+	AvatarVersion string
+	// ErasedAt - time personal data of user was erased, nil if it is kept. Erased user is deleted too.
+	ErasedAt *time.Time
+}
+
+// Normalize - bringing username and email to the form their uniqueness is checked in:
+// Unicode NFKC, lowercase, without surrounding spaces.
+func (uf *UserInfo) Normalize() {
+	uf.Username = normalizeIdentifier(uf.Username)
+	uf.Email = normalizeIdentifier(uf.Email)
+}
+
+// NormalizeUsername - username in the form its uniqueness is checked in, see UserInfo.Normalize.
+func NormalizeUsername(username string) string {
+	return normalizeIdentifier(username)
+}
+
+// ValidUsername - checking username in normalized form, the same rule is applied by UserInfo.Validate.
+func ValidUsername(username string) bool {
+	return username != ""
+}
+
+func normalizeIdentifier(s string) string {
+	return strings.ToLower(strings.TrimSpace(norm.NFKC.String(s)))
+}
+
+// EmailValidator - checking syntax and domain of email, e.g. *validator.EmailValidator with email policy.
+// Errors of domain checks wrap validator.ErrEmailDomainRejected or emailpolicy.ErrDomainNotAllowed.
+type EmailValidator interface {
+	Validate(ctx context.Context, email string) error
+}
+
+// Validate - validating new user normalized by Normalize, domain of email is checked by email validator.
+func (uf *UserInfo) Validate(ctx context.Context, email EmailValidator) error {
+	return uf.validate(ctx, email, nil)
+}
+
+// ValidateUpdate - validating user replacing current one. Email and names kept as they are in current
+// are not checked again, rows stored before their rules got stricter stay updatable,
+// and kept email domain could stop passing email policy since.
+func (uf *UserInfo) ValidateUpdate(ctx context.Context, email EmailValidator, current *UserInfo) error {
+	return uf.validate(ctx, email, current)
+}
+
+func (uf *UserInfo) validate(ctx context.Context, email EmailValidator, current *UserInfo) error {
+	var kept UserInfo
+	if current != nil {
+		kept = *current
+	}
+	unchanged := func(value, keptValue string) bool {
+		return current != nil && value == keptValue
+	}
+
+	if !unchanged(uf.Username, kept.Username) && !ValidUsername(uf.Username) {
+		return ErrInvalidUsername
+	}
+
+	checkEmail := !unchanged(uf.Email, kept.Email)
+	if checkEmail && !validator.ValidEmail(uf.Email) {
 		return ErrInvalidEmail
 	}
 
+	validName := func(name, keptName string) bool {
+		return unchanged(name, keptName) || validator.ValidName(name)
+	}
+	if !validName(uf.FirstName, kept.FirstName) || !validName(uf.LastName, kept.LastName) ||
+		uf.MiddleName != "" && !validName(uf.MiddleName, kept.MiddleName) {
+		return ErrInvalidName
+	}
+
 	if !validator.ValidGender(uf.Gender) {
 		return ErrInvalidGender
 	}
@@ -30,5 +110,134 @@ func (uf *UserInfo) Validate() error {
 		return ErrInvalidAge
 	}
 
+	// domain checks may look up DNS, so they go after checks of the other fields
+	if checkEmail && email != nil {
+		return emailDomainError(email.Validate(ctx, uf.Email))
+	}
 	return nil
 }
+
+// emailDomainError - domain out of email policy is not allowed, otherwise rejected domain makes email invalid.
+func emailDomainError(err error) error {
+	if errors.Is(err, emailpolicy.ErrDomainNotAllowed) {
+		return fmt.Errorf("%w: %w", ErrEmailDomainNotAllowed, err)
+	}
+	if errors.Is(err, validator.ErrEmailDomainRejected) {
+		return fmt.Errorf("%w: %w", ErrInvalidEmail, err)
+	}
+	return err
+}
+
+// UserFilter - conditions of active users listing, zero values mean no condition.
+type UserFilter struct {
+	// AfterID - only users with greater id, used as pagination cursor.
+	AfterID int64
+	// Limit - maximal number of users.
+	Limit          int
+	Gender         string
+	MinAge         uint8
+	MaxAge         uint8
+	UsernamePrefix string
+	// Attributes - values attributes of user must contain, objects and arrays match by containment.
+	Attributes map[string]any
+}
+
+func (f *UserFilter) Validate() error {
+	if f.Gender != "" && !validator.ValidGender(f.Gender) {
+		return ErrInvalidGender
+	}
+
+	if f.MaxAge != 0 && f.MinAge > f.MaxAge {
+		return ErrInvalidAge
+	}
+
+	for name := range f.Attributes {
+		if !attributes.ValidName(name) {
+			return fmt.Errorf("%w: %w", ErrInvalidAttributes, attributes.ErrInvalidName)
+		}
+	}
+
+	return nil
+}
+
+// AttributeSchema - JSON Schema of user attribute value, attributes without schema are rejected.
+type AttributeSchema struct {
+	Name      string
+	Schema    json.RawMessage
+	CreatedAt time.Time
+	UpdatedAt *time.Time
+}
+
+func (s *AttributeSchema) Validate() error {
+	if !attributes.ValidName(s.Name) {
+		return fmt.Errorf("%w: %w", ErrInvalidAttributeSchema, attributes.ErrInvalidName)
+	}
+
+	if _, err := attributes.Compile(s.Name, s.Schema); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidAttributeSchema, err)
+	}
+
+	return nil
+}
+
+// SearchField - field of user which search matches.
+type SearchField string
+
+const (
+	SearchFieldUsername   SearchField = "username"
+	SearchFieldFirstName  SearchField = "first_name"
+	SearchFieldMiddleName SearchField = "middle_name"
+	SearchFieldLastName   SearchField = "last_name"
+	SearchFieldEmail      SearchField = "email"
+)
+
+// UserSearch - full-text and fuzzy search of active users, results are paginated by offset.
+type UserSearch struct {
+	Query  string
+	Limit  int
+	Offset int
+}
+
+// Terms - lowercase words of query, only letters and digits are kept, repeats are dropped.
+func (s *UserSearch) Terms() []string {
+	words := strings.FieldsFunc(strings.ToLower(s.Query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		if !slices.Contains(terms, word) {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+func (s *UserSearch) Validate() error {
+	if len(s.Terms()) == 0 {
+		return ErrInvalidSearchQuery
+	}
+
+	if s.Limit < 0 || s.Offset < 0 {
+		return ErrInvalidPagination
+	}
+
+	return nil
+}
+
+// UserSearchHit - user found by search.
+type UserSearchHit struct {
+	User UserInfo
+	// Rank - relevance of user to the query, greater is better.
+	Rank float64
+	// Highlights - matched fields with occurrences of terms wrapped in <mark>, the rest is HTML-escaped.
+	Highlights map[SearchField]string
+}
+
+// UsernameAvailability - whether username can be taken by a new user, with available alternatives if it can not.
+type UsernameAvailability struct {
+	// Username - checked username after normalization.
+	Username    string
+	Available   bool
+	Suggestions []string
+}
@@ -1,32 +1,164 @@
 package postgres
 
 import (
+	"context"
 	"embed"
 	"fmt"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
 	"github.com/sonikq/gravitum_test_task/internal/models"
+	"io/fs"
 )
 
+// MigrationSourceDir - directory with migration sources relative to the project root,
+// new migrations are created there.
+const MigrationSourceDir = "internal/repository/postgres/migration"
+
+// migrationLockID - key of the advisory lock held while migrations are applied,
+// so concurrent migrators wait for each other instead of racing.
+const migrationLockID int64 = 7458612739904150021
+
 //go:embed migration/*.sql
 var embedMigrations embed.FS
 
-func migrate(pool *pgxpool.Pool) error {
-	const source = "postgres.migrate"
-	goose.SetBaseFS(embedMigrations)
+// Migrator - applies embedded migrations under a postgres advisory lock.
+type Migrator struct {
+	provider *goose.Provider
+}
+
+// NewMigrator - creating migrator on top of connection pool.
+func NewMigrator(pool *pgxpool.Pool) (*Migrator, error) {
+	const source = "postgres.NewMigrator"
 
-	if err := goose.SetDialect("postgres"); err != nil {
-		return fmt.Errorf(models.ErrTraceLayout, source, "goose.SetDialect: "+err.Error())
+	migrations, err := fs.Sub(embedMigrations, "migration")
+	if err != nil {
+		return nil, fmt.Errorf(models.ErrWrapTraceLayout, source, err)
+	}
+
+	locker, err := lock.NewPostgresSessionLocker(lock.WithLockID(migrationLockID))
+	if err != nil {
+		return nil, fmt.Errorf(models.ErrWrapTraceLayout, source, err)
 	}
 
 	db := stdlib.OpenDBFromPool(pool)
-	if err := goose.Up(db, "migration"); err != nil {
-		return fmt.Errorf(models.ErrTraceLayout, source, "goose.Up: "+err.Error())
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations,
+		goose.WithSessionLocker(locker))
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf(models.ErrWrapTraceLayout, source, fmt.Errorf("goose.NewProvider: %w", err))
+	}
+
+	return &Migrator{provider: provider}, nil
+}
+
+// Up - applying all pending migrations.
+func (m *Migrator) Up(ctx context.Context) ([]*goose.MigrationResult, error) {
+	const source = "postgres.Migrator.Up"
+	results, err := m.provider.Up(ctx)
+	if err != nil {
+		return results, fmt.Errorf(models.ErrWrapTraceLayout, source, err)
+	}
+	return results, nil
+}
+
+// Down - rolling back the last applied migration.
+func (m *Migrator) Down(ctx context.Context) (*goose.MigrationResult, error) {
+	const source = "postgres.Migrator.Down"
+	result, err := m.provider.Down(ctx)
+	if err != nil {
+		return result, fmt.Errorf(models.ErrWrapTraceLayout, source, err)
+	}
+	return result, nil
+}
+
+// Redo - rolling back the last applied migration and applying it again.
+func (m *Migrator) Redo(ctx context.Context) ([]*goose.MigrationResult, error) {
+	const source = "postgres.Migrator.Redo"
+	version, err := m.provider.GetDBVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf(models.ErrWrapTraceLayout, source, err)
+	}
+
+	down, err := m.provider.Down(ctx)
+	if err != nil {
+		return nil, fmt.Errorf(models.ErrWrapTraceLayout, source, err)
+	}
+
+	up, err := m.provider.ApplyVersion(ctx, version, true)
+	if err != nil {
+		return []*goose.MigrationResult{down}, fmt.Errorf(models.ErrWrapTraceLayout, source, err)
+	}
+	return []*goose.MigrationResult{down, up}, nil
+}
+
+// HasPending - checking whether there are migrations to apply, advisory lock is not taken.
+func (m *Migrator) HasPending(ctx context.Context) (bool, error) {
+	const source = "postgres.Migrator.HasPending"
+	pending, err := m.provider.HasPending(ctx)
+	if err != nil {
+		return false, fmt.Errorf(models.ErrWrapTraceLayout, source, err)
+	}
+	return pending, nil
+}
+
+// Status - getting state of every known migration.
+func (m *Migrator) Status(ctx context.Context) ([]*goose.MigrationStatus, error) {
+	const source = "postgres.Migrator.Status"
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf(models.ErrWrapTraceLayout, source, err)
+	}
+	return statuses, nil
+}
+
+// Version - getting current version of DB schema.
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	const source = "postgres.Migrator.Version"
+	version, err := m.provider.GetDBVersion(ctx)
+	if err != nil {
+		return 0, fmt.Errorf(models.ErrWrapTraceLayout, source, err)
+	}
+	return version, nil
+}
+
+// Close - releasing DB handle used by migrator, the pool itself stays open.
+func (m *Migrator) Close() error {
+	return m.provider.Close()
+}
+
+// CreateMigration - writing a new blank sql migration into dir.
+func CreateMigration(dir, name string) error {
+	const source = "postgres.CreateMigration"
+	if err := goose.Create(nil, dir, name, "sql"); err != nil {
+		return fmt.Errorf(models.ErrWrapTraceLayout, source, err)
+	}
+	return nil
+}
+
+// migrateThrough - applying pending migrations through a separate connection to uri.
+func migrateThrough(ctx context.Context, uri string) error {
+	const source = "postgres.migrateThrough"
+	pool, err := pgxpool.New(ctx, uri)
+	if err != nil {
+		return fmt.Errorf(models.ErrWrapTraceLayout, source, err)
+	}
+	defer pool.Close()
+
+	return migrate(ctx, pool)
+}
+
+func migrate(ctx context.Context, pool *pgxpool.Pool) error {
+	const source = "postgres.migrate"
+	migrator, err := NewMigrator(pool)
+	if err != nil {
+		return fmt.Errorf(models.ErrWrapTraceLayout, source, err)
 	}
+	defer migrator.Close()
 
-	if err := db.Close(); err != nil {
-		return fmt.Errorf(models.ErrTraceLayout, source, err)
+	if _, err = migrator.Up(ctx); err != nil {
+		return fmt.Errorf(models.ErrWrapTraceLayout, source, err)
 	}
 	return nil
 }
@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+-- service stores username and email normalized, lower() also covers case of rows written before that,
+-- but not their NFKC form and surrounding spaces, existing rows are kept as they are;
+-- deleted users do not hold their username and email
+DO $$
+DECLARE
+    usernames TEXT;
+    emails TEXT;
+BEGIN
+    SELECT string_agg(quote_literal(username), ', ') INTO usernames
+    FROM (SELECT lower(username) AS username FROM users WHERE end_date IS NULL
+          GROUP BY lower(username) HAVING count(*) > 1) AS d;
+    SELECT string_agg(quote_literal(email), ', ') INTO emails
+    FROM (SELECT lower(email) AS email FROM users WHERE end_date IS NULL
+          GROUP BY lower(email) HAVING count(*) > 1) AS d;
+
+    IF usernames IS NOT NULL OR emails IS NOT NULL THEN
+        RAISE EXCEPTION 'can not make username and email unique, they are shared by several active users regardless of case: usernames %, emails %',
+            coalesce(usernames, 'none'), coalesce(emails, 'none')
+            USING HINT = 'change username or email of all active users but one sharing them, or delete such users, and retry';
+    END IF;
+END $$;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_username_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_active_key ON users (lower(username)) WHERE end_date IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_active_key ON users (lower(email)) WHERE end_date IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- unique username can not be restored while a deleted user shares it with another one,
+-- such rows are left to be resolved manually instead of being removed
+DO $$
+DECLARE
+    duplicates TEXT;
+BEGIN
+    SELECT string_agg(quote_literal(username), ', ') INTO duplicates
+    FROM (SELECT username FROM users GROUP BY username HAVING count(*) > 1) AS d;
+
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'can not restore unique username, it is shared by several users: %', duplicates
+            USING HINT = 'rename or remove deleted users holding these usernames and retry';
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS users_email_active_key;
+DROP INDEX IF EXISTS users_username_active_key;
+
+ALTER TABLE users ADD CONSTRAINT users_username_key UNIQUE (username);
+-- +goose StatementEnd
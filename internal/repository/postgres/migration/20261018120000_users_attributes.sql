@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}'
+    CONSTRAINT users_attributes_object CHECK (jsonb_typeof(attributes) = 'object');
+
+-- default operator class serves both containment filters and key existence checks
+CREATE INDEX IF NOT EXISTS users_attributes_idx ON users USING gin (attributes);
+
+CREATE TABLE IF NOT EXISTS attribute_schemas (
+    name TEXT PRIMARY KEY,
+    schema JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS attribute_schemas;
+
+DROP INDEX IF EXISTS users_attributes_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS attributes;
+-- +goose StatementEnd
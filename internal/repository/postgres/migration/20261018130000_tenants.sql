@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+-- existing rows belong to the default tenant, new ones must name their tenant
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE users ALTER COLUMN tenant_id DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS users_tenant_id_idx ON users (tenant_id, id);
+
+-- username and email are unique among active users of the same tenant only
+DROP INDEX IF EXISTS users_username_active_key;
+DROP INDEX IF EXISTS users_email_active_key;
+CREATE UNIQUE INDEX users_username_active_key ON users (tenant_id, lower(username)) WHERE end_date IS NULL;
+CREATE UNIQUE INDEX users_email_active_key ON users (tenant_id, lower(email)) WHERE end_date IS NULL;
+
+ALTER TABLE attribute_schemas ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE attribute_schemas ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE attribute_schemas DROP CONSTRAINT attribute_schemas_pkey;
+ALTER TABLE attribute_schemas ADD CONSTRAINT attribute_schemas_pkey PRIMARY KEY (tenant_id, name);
+
+-- policies restrict roles which do not own the tables, the owner bypasses them;
+-- repository sets app.tenant_id on every connection it takes from the pool when DB_ROW_SECURITY is on
+ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+CREATE POLICY users_tenant_isolation ON users
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE attribute_schemas ENABLE ROW LEVEL SECURITY;
+CREATE POLICY attribute_schemas_tenant_isolation ON attribute_schemas
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- fails if different tenants have the same username, email or attribute name
+DROP POLICY IF EXISTS attribute_schemas_tenant_isolation ON attribute_schemas;
+ALTER TABLE attribute_schemas DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS users_tenant_isolation ON users;
+ALTER TABLE users DISABLE ROW LEVEL SECURITY;
+
+ALTER TABLE attribute_schemas DROP CONSTRAINT attribute_schemas_pkey;
+ALTER TABLE attribute_schemas ADD CONSTRAINT attribute_schemas_pkey PRIMARY KEY (name);
+ALTER TABLE attribute_schemas DROP COLUMN IF EXISTS tenant_id;
+
+DROP INDEX IF EXISTS users_username_active_key;
+DROP INDEX IF EXISTS users_email_active_key;
+CREATE UNIQUE INDEX users_username_active_key ON users (lower(username)) WHERE end_date IS NULL;
+CREATE UNIQUE INDEX users_email_active_key ON users (lower(email)) WHERE end_date IS NULL;
+
+DROP INDEX IF EXISTS users_tenant_id_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+-- +goose StatementEnd
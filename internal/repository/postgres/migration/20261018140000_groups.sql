@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+-- members reference users of their own tenant only
+DROP INDEX IF EXISTS users_tenant_id_idx;
+ALTER TABLE users ADD CONSTRAINT users_tenant_id_id_key UNIQUE (tenant_id, id);
+
+CREATE TABLE IF NOT EXISTS groups (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT groups_tenant_id_id_key UNIQUE (tenant_id, id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS groups_name_key ON groups (tenant_id, lower(name));
+
+-- memberships of deleted users are kept, so they are back once the user is restored
+CREATE TABLE IF NOT EXISTS group_members (
+    tenant_id TEXT NOT NULL,
+    group_id BIGINT NOT NULL,
+    user_id INTEGER NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('owner', 'admin', 'member')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (group_id, user_id),
+    FOREIGN KEY (tenant_id, group_id) REFERENCES groups (tenant_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, user_id) REFERENCES users (tenant_id, id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS group_members_user_id_idx ON group_members (user_id);
+
+ALTER TABLE groups ENABLE ROW LEVEL SECURITY;
+CREATE POLICY groups_tenant_isolation ON groups
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE group_members ENABLE ROW LEVEL SECURITY;
+CREATE POLICY group_members_tenant_isolation ON group_members
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS group_members;
+DROP TABLE IF EXISTS groups;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_tenant_id_id_key;
+CREATE INDEX IF NOT EXISTS users_tenant_id_idx ON users (tenant_id, id);
+-- +goose StatementEnd
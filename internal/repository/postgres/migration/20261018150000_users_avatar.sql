@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- version of avatar images in blob storage, empty for user without avatar
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_version TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_version;
+-- +goose StatementEnd
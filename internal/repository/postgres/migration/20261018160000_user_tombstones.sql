@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- record of erased user, row of the user is kept anonymized so its id is never reused;
+-- there is no foreign key, tombstone holds no personal data and outlives the row
+CREATE TABLE IF NOT EXISTS user_tombstones (
+    tenant_id TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    erased_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (tenant_id, user_id)
+);
+
+ALTER TABLE user_tombstones ENABLE ROW LEVEL SECURITY;
+CREATE POLICY user_tombstones_tenant_isolation ON user_tombstones
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_tombstones;
+-- +goose StatementEnd
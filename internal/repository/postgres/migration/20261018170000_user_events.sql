@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+-- audit history of users, events hold no personal data, so they are kept when the user is erased
+CREATE TABLE IF NOT EXISTS user_events (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    user_id INTEGER NOT NULL,
+    event TEXT NOT NULL CHECK (event IN ('created', 'updated', 'deleted', 'restored', 'erased')),
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS user_events_user_idx ON user_events (tenant_id, user_id, occurred_at, id);
+
+-- history of existing users is restored from the times stored with them: creation, the last update,
+-- deletion and erasure; earlier updates and restorations are not known
+INSERT INTO user_events (tenant_id, user_id, event, occurred_at)
+SELECT tenant_id, id, 'created', beg_date FROM users
+UNION ALL
+SELECT tenant_id, id, 'updated', updated_at FROM users WHERE updated_at IS NOT NULL
+UNION ALL
+SELECT tenant_id, id, 'deleted', end_date FROM users WHERE end_date IS NOT NULL
+UNION ALL
+SELECT tenant_id, user_id, 'erased', erased_at FROM user_tombstones
+ORDER BY 4;
+
+ALTER TABLE user_events ENABLE ROW LEVEL SECURITY;
+CREATE POLICY user_events_tenant_isolation ON user_events
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_events;
+-- +goose StatementEnd
@@ -11,10 +11,110 @@ package postgres
 //age SMALLINT CHECK (age >= 0 AND age <= 150)
 //);
 
+// emailUniqueIndex - index keeping emails of active users unique.
+const emailUniqueIndex = "users_email_active_key"
+
+// userColumns - columns of user in the order userFields scans them,
+// gender and age of erased user are null.
+const userColumns = `id, username, first_name, middle_name, last_name, email, coalesce(gender, ''), coalesce(age, 0),
+attributes, beg_date, updated_at, end_date, avatar_version,
+(select erased_at from user_tombstones t where t.tenant_id = users.tenant_id and t.user_id = users.id)`
+
+// every query is scoped by tenant passed as $1, changes of users are recorded in user_events by the same statement
+const (
+	createUser = `with created as (insert into users(tenant_id, username, first_name, middle_name, last_name, email, gender, age, attributes, beg_date) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, now()) returning id),
+event as (insert into user_events(tenant_id, user_id, event) select $1, id, 'created' from created)
+select id from created`
+	getUser    = `select ` + userColumns + ` from users where tenant_id = $1 and id = $2`
+	updateUser = `with updated as (update users set username = $2, first_name = $3,
+middle_name = $4, last_name = $5, email = $6, gender = $7, age = $8, attributes = $9, updated_at = now() where tenant_id = $1 and id = $10 returning id)
+insert into user_events(tenant_id, user_id, event) select $1, id, 'updated' from updated`
+	deleteUser = `with deleted as (update users set end_date = now() where tenant_id = $1 and id = $2 returning id)
+insert into user_events(tenant_id, user_id, event) select $1, id, 'deleted' from deleted`
+	restoreUser = `with restored as (update users set end_date = null, updated_at = now() where tenant_id = $1 and id = $2
+and not exists(select 1 from user_tombstones where tenant_id = $1 and user_id = $2) returning id)
+insert into user_events(tenant_id, user_id, event) select $1, id, 'restored' from restored`
+	getUsers       = `select ` + userColumns + ` from users where tenant_id = $1 and id = any($2)`
+	listUsers      = `select ` + userColumns + ` from users where tenant_id = $1 and end_date is null and id > $2`
+	takenUsernames = `select lower(username) from users where tenant_id = $1 and lower(username) = any($2) and end_date is null`
+	// $2 - prefix tsquery of names, $3 - terms for trigram similarity of username and email
+	searchUsers = `select ` + userColumns + `,
+ts_rank(search_vector, query) + greatest(word_similarity($3, username), word_similarity($3, email)) as rank
+from users, to_tsquery('simple', $2) query
+where tenant_id = $1 and end_date is null and (search_vector @@ query or $3 <% username or $3 <% email)
+order by rank desc, id limit $4 offset $5`
+	// version of active user is replaced and the previous one is returned, row is locked
+	// in subquery so concurrent uploads see versions of each other
+	setAvatarVersion = `with old as (select id, avatar_version from users where tenant_id = $1 and id = $2 for update),
+changed as (update users u set avatar_version = $3, updated_at = now()
+from old where u.tenant_id = $1 and u.id = old.id and u.end_date is null returning old.avatar_version),
+event as (insert into user_events(tenant_id, user_id, event) select $1, $2, 'updated' from changed)
+select avatar_version from changed`
+	// personal fields are cleared, the username is freed and the user is deleted if it is not yet,
+	// memberships are removed, tombstone and event are recorded in one statement; previous avatar version is returned
+	eraseUser = `with old as (select id, avatar_version from users where tenant_id = $1 and id = $2 for update),
+erased as (update users u set username = 'erased-' || u.id, first_name = '', middle_name = '', last_name = '',
+email = '', gender = null, age = null, attributes = '{}', avatar_version = '',
+end_date = coalesce(u.end_date, now()), updated_at = now()
+from old where u.tenant_id = $1 and u.id = old.id returning old.avatar_version),
+memberships as (delete from group_members where tenant_id = $1 and user_id = $2),
+tombstone as (insert into user_tombstones(tenant_id, user_id) select $1, $2 from erased),
+event as (insert into user_events(tenant_id, user_id, event) select $1, $2, 'erased' from erased)
+select avatar_version from erased`
+	listUserEvents = `select event, occurred_at from user_events where tenant_id = $1 and user_id = $2 order by occurred_at, id`
+)
+
+const (
+	listAttributeSchemas = `select name, schema, created_at, updated_at from attribute_schemas where tenant_id = $1 order by name`
+	// created is true for inserted row, xmax of updated one is set
+	putAttributeSchema = `insert into attribute_schemas(tenant_id, name, schema) values ($1, $2, $3)
+on conflict (tenant_id, name) do update set schema = excluded.schema, updated_at = now() returning xmax = 0`
+	// schema is deleted only if no user of the tenant, deleted ones included, has the attribute;
+	// existence is checked on the snapshot taken before deletion
+	deleteAttributeSchema = `with deleted as (
+delete from attribute_schemas where tenant_id = $1 and name = $2
+and not exists (select 1 from users where tenant_id = $1 and attributes ? $2) returning name)
+select exists(select 1 from deleted), exists(select 1 from attribute_schemas where tenant_id = $1 and name = $2)`
+)
+
+// tombstoneKey - primary key of user tombstones, its violation means the user is already erased.
+const tombstoneKey = "user_tombstones_pkey"
+
+// setTenant - tenant compared with rows by row security policies, it is kept by the connection.
+const setTenant = `select set_config('app.tenant_id', $1, false)`
+
+// bypassesRowSecurity - whether row security policies do not restrict the current role: it is superuser,
+// has BYPASSRLS or owns users table, directly or through membership. Missing table restricts nothing yet.
+const bypassesRowSecurity = `select r.rolsuper or r.rolbypassrls or coalesce(pg_has_role(c.relowner, 'USAGE'), false)
+from pg_roles r left join pg_class c on c.oid = to_regclass('users') where r.rolname = current_user`
+
+// groupNameUniqueIndex - index keeping names of groups of a tenant unique.
+const groupNameUniqueIndex = "groups_name_key"
+
 const (
-	createUser = `insert into users(username, first_name, middle_name, last_name, email, gender, age, beg_date) values ($1, $2, $3, $4, $5, $6, $7, now()) returning id`
-	getUser    = `select id, username, first_name, middle_name, last_name, email, gender, age, end_date from users where id = $1`
-	updateUser = `update users set username = $1, first_name = $2,
-middle_name = $3, last_name = $4, email = $5, gender = $6, age = $7, updated_at = now() where id = $8;`
-	deleteUser = `update users set end_date = now() where id = $1`
+	createGroup = `insert into groups(tenant_id, name, description) values ($1, $2, $3) returning id, created_at`
+	getGroup    = `select id, name, description, created_at, updated_at from groups where tenant_id = $1 and id = $2`
+	listGroups  = `select id, name, description, created_at, updated_at from groups where tenant_id = $1
+order by lower(name), id limit nullif($2, 0) offset $3`
+	updateGroup = `update groups set name = $3, description = $4, updated_at = now() where tenant_id = $1 and id = $2`
+	deleteGroup = `delete from groups where tenant_id = $1 and id = $2`
+	// only active users join groups; created is null if nothing is put,
+	// active is null if user does not exist
+	putGroupMember = `with put as (
+insert into group_members(tenant_id, group_id, user_id, role)
+select $1, $2, $3, $4 where exists(select 1 from groups where tenant_id = $1 and id = $2)
+and exists(select 1 from users where tenant_id = $1 and id = $3 and end_date is null)
+on conflict (group_id, user_id) do update set role = excluded.role, updated_at = now() returning xmax = 0 as created)
+select (select created from put), exists(select 1 from groups where tenant_id = $1 and id = $2),
+(select end_date is null from users where tenant_id = $1 and id = $3)`
+	deleteGroupMember = `with deleted as (
+delete from group_members where tenant_id = $1 and group_id = $2 and user_id = $3 returning user_id)
+select exists(select 1 from deleted), exists(select 1 from groups where tenant_id = $1 and id = $2)`
+	// memberships of deleted users are hidden until they are restored
+	listGroupMembers = `select u.id, u.username, u.first_name, u.middle_name, u.last_name, m.role, m.created_at, m.updated_at
+from group_members m join users u on u.tenant_id = m.tenant_id and u.id = m.user_id
+where m.tenant_id = $1 and m.group_id = $2 and u.end_date is null order by u.id limit nullif($3, 0) offset $4`
+	listUserGroups = `select g.id, g.name, g.description, g.created_at, g.updated_at, m.role, m.created_at, m.updated_at
+from group_members m join groups g on g.tenant_id = m.tenant_id and g.id = m.group_id
+where m.tenant_id = $1 and m.user_id = $2 order by lower(g.name), g.id limit nullif($3, 0) offset $4`
 )
@@ -9,42 +9,75 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sonikq/gravitum_test_task/internal/models"
+	"github.com/sonikq/gravitum_test_task/pkg/tenant"
 	"log"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type Repository struct {
 	pool *pgxpool.Pool
+
+	// checker - migrator of readiness checks, created on the first check and reused
+	checkerOnce sync.Once
+	checker     *Migrator
+	checkerErr  error
+	// migrated - no migration was pending on a check, embedded migrations can not change
+	// while the process runs, so it stays true
+	migrated atomic.Bool
 }
 
-func NewStorage(ctx context.Context, uri string, dbPoolWorkers int) (*Repository, error) {
+// NewStorage - connecting to DB, pending migrations are applied when autoMigrate is set,
+// through migrationURI if it is not empty, e.g. as the owner of the tables while uri connects as another role.
+// With rowSecurity every connection is bound to tenant of ctx it is taken with, so row security policies
+// let the role of uri see rows of that tenant only; the role must be restricted by them.
+func NewStorage(ctx context.Context, uri, migrationURI string, dbPoolWorkers int, autoMigrate, rowSecurity bool) (*Repository, error) {
 	const source = "repository.NewStorage"
 	t1 := time.Now()
 	var pool *pgxpool.Pool
 	config, err := pgxpool.ParseConfig(uri)
 	if err != nil {
-		return nil, fmt.Errorf(models.ErrTraceLayout, source, err)
+		return nil, fmt.Errorf(models.ErrWrapTraceLayout, source, err)
 	}
 	config.MaxConns = int32(dbPoolWorkers)
+	if rowSecurity {
+		// connection failing to switch tenant is destroyed, so it never serves another tenant
+		config.BeforeAcquire = func(ctx context.Context, conn *pgx.Conn) bool {
+			_, err := conn.Exec(ctx, setTenant, tenant.FromContext(ctx))
+			return err == nil
+		}
+	}
 
 	pool, err = pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
-		return nil, fmt.Errorf(models.ErrTraceLayout, source, err)
+		return nil, fmt.Errorf(models.ErrWrapTraceLayout, source, err)
 	}
 
 	if err = pool.Ping(ctx); err != nil {
-		return nil, fmt.Errorf(models.ErrTraceLayout, source, err)
+		pool.Close()
+		return nil, fmt.Errorf(models.ErrWrapTraceLayout, source, err)
 	}
-	//err = retrier.DoWithRetries(func() error {
-	//	return pool.Ping(ctx)
-	//})
-	//if err != nil {
-	//	return nil, fmt.Errorf(models.ErrTraceLayout, source, err)
-	//}
 
-	if err = migrate(pool); err != nil {
-		return nil, fmt.Errorf(models.ErrTraceLayout, source, err)
+	if autoMigrate {
+		if migrationURI != "" {
+			err = migrateThrough(ctx, migrationURI)
+		} else {
+			err = migrate(ctx, pool)
+		}
+		if err != nil {
+			pool.Close()
+			return nil, fmt.Errorf(models.ErrWrapTraceLayout, source, err)
+		}
+	}
+
+	if rowSecurity {
+		if err = checkRowSecurity(ctx, pool); err != nil {
+			pool.Close()
+			return nil, fmt.Errorf(models.ErrWrapTraceLayout, source, err)
+		}
 	}
 
 	log.Printf("connection to database took: %v\n", time.Since(t1))
@@ -52,23 +85,76 @@ func NewStorage(ctx context.Context, uri string, dbPoolWorkers int) (*Repository
 	return &Repository{pool: pool}, nil
 }
 
+// ErrRowSecurityBypassed - role is superuser, has BYPASSRLS or owns the tables, row security policies
+// do not restrict it, so turning row security on would protect nothing.
+var ErrRowSecurityBypassed = errors.New("row security policies do not restrict the database role, " +
+	"it is superuser, has BYPASSRLS or owns the tables")
+
+// checkRowSecurity - checking that row security policies restrict the role of pool.
+func checkRowSecurity(ctx context.Context, pool *pgxpool.Pool) error {
+	var bypassed bool
+	if err := pool.QueryRow(ctx, bypassesRowSecurity).Scan(&bypassed); err != nil {
+		return err
+	}
+	if bypassed {
+		return ErrRowSecurityBypassed
+	}
+	return nil
+}
+
 // Close - closing connection pool to DB.
 func (r *Repository) Close() {
+	if r.checker != nil {
+		_ = r.checker.Close()
+	}
 	r.pool.Close()
 }
 
+// Migrator - creating migrator working through the repository pool.
+func (r *Repository) Migrator() (*Migrator, error) {
+	return NewMigrator(r.pool)
+}
+
+// Ping - checking connection to DB.
+func (r *Repository) Ping(ctx context.Context) error {
+	return r.pool.Ping(ctx)
+}
+
+// CheckMigrations - checking that all embedded migrations are applied, advisory lock of migrations
+// is not taken. Once nothing is pending DB is not queried anymore.
+func (r *Repository) CheckMigrations(ctx context.Context) error {
+	const source = "repository.CheckMigrations"
+	if r.migrated.Load() {
+		return nil
+	}
+
+	r.checkerOnce.Do(func() {
+		r.checker, r.checkerErr = r.Migrator()
+	})
+	if r.checkerErr != nil {
+		return fmt.Errorf(models.ErrWrapTraceLayout, source, r.checkerErr)
+	}
+
+	pending, err := r.checker.HasPending(ctx)
+	if err != nil {
+		return fmt.Errorf(models.ErrWrapTraceLayout, source, err)
+	}
+	if pending {
+		return models.ErrPendingMigrations
+	}
+	r.migrated.Store(true)
+	return nil
+}
+
 // CreateUser - creates a new user.
 func (r *Repository) CreateUser(ctx context.Context, body models.UserInfo) (string, error) {
 	const source = "repository.CreateUser"
 	var userID int64
-	err := r.pool.QueryRow(ctx, createUser, body.Username,
-		body.FirstName, body.MiddleName, body.LastName, body.Email, body.Gender, body.Age).Scan(&userID)
+	err := r.pool.QueryRow(ctx, createUser, tenant.FromContext(ctx), body.Username, body.FirstName, body.MiddleName,
+		body.LastName, body.Email, body.Gender, body.Age, attributesOf(body)).Scan(&userID)
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if ok := errors.As(err, &pgErr); ok {
-			if pgErr.Code == pgerrcode.UniqueViolation {
-				return "", models.ErrUsernameIsAlreadyTaken
-			}
+		if taken := takenIdentifier(err); taken != nil {
+			return "", taken
 		}
 	}
 	return strconv.Itoa(int(userID)), err
@@ -78,9 +164,7 @@ func (r *Repository) CreateUser(ctx context.Context, body models.UserInfo) (stri
 func (r *Repository) GetUser(ctx context.Context, id int64) (*models.UserInfo, error) {
 	const source = "repository.GetUser"
 	userInfo := new(models.UserInfo)
-	if err := r.pool.QueryRow(ctx, getUser, id).
-		Scan(&userInfo.ID, &userInfo.Username, &userInfo.FirstName, &userInfo.MiddleName,
-			&userInfo.LastName, &userInfo.Email, &userInfo.Gender, &userInfo.Age, &userInfo.EndDate); err != nil {
+	if err := scanUser(r.pool.QueryRow(ctx, getUser, tenant.FromContext(ctx), id), userInfo); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, models.ErrUserDoesNotExist
 		}
@@ -93,9 +177,12 @@ func (r *Repository) GetUser(ctx context.Context, id int64) (*models.UserInfo, e
 // UpdateUser - updating user info by id.
 func (r *Repository) UpdateUser(ctx context.Context, body models.UserInfo, id int64) error {
 	const source = "repository.UpdateUser"
-	_, err := r.pool.Exec(ctx, updateUser, body.Username, body.FirstName,
-		body.MiddleName, body.LastName, body.Email, body.Gender, body.Age, id)
+	_, err := r.pool.Exec(ctx, updateUser, tenant.FromContext(ctx), body.Username, body.FirstName,
+		body.MiddleName, body.LastName, body.Email, body.Gender, body.Age, attributesOf(body), id)
 	if err != nil {
+		if taken := takenIdentifier(err); taken != nil {
+			return taken
+		}
 		return fmt.Errorf(models.ErrTraceLayout, source, "error in updating user info: "+err.Error())
 	}
 	return nil
@@ -104,9 +191,490 @@ func (r *Repository) UpdateUser(ctx context.Context, body models.UserInfo, id in
 // DeleteUser - setting end_date for user meta.
 func (r *Repository) DeleteUser(ctx context.Context, id int64) error {
 	const source = "repository.UpdateUser"
-	_, err := r.pool.Exec(ctx, deleteUser, id)
+	_, err := r.pool.Exec(ctx, deleteUser, tenant.FromContext(ctx), id)
 	if err != nil {
 		return fmt.Errorf(models.ErrTraceLayout, source, "error in deleting user info: "+err.Error())
 	}
 	return nil
 }
+
+// RestoreUser - clearing end_date of deleted user, fails if username or email is taken since deletion.
+func (r *Repository) RestoreUser(ctx context.Context, id int64) error {
+	const source = "repository.RestoreUser"
+	_, err := r.pool.Exec(ctx, restoreUser, tenant.FromContext(ctx), id)
+	if err != nil {
+		if taken := takenIdentifier(err); taken != nil {
+			return taken
+		}
+		return fmt.Errorf(models.ErrTraceLayout, source, "error in restoring user info: "+err.Error())
+	}
+	return nil
+}
+
+// SetAvatarVersion - replacing version of avatar of active user, empty version means no avatar.
+// Previous version is returned so its images can be removed.
+func (r *Repository) SetAvatarVersion(ctx context.Context, id int64, version string) (string, error) {
+	const source = "repository.SetAvatarVersion"
+	var previous string
+	err := r.pool.QueryRow(ctx, setAvatarVersion, tenant.FromContext(ctx), id, version).Scan(&previous)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", models.ErrUserDoesNotExist
+		}
+		return "", fmt.Errorf(models.ErrTraceLayout, source, "error in setting avatar version: "+err.Error())
+	}
+	return previous, nil
+}
+
+// EraseUser - anonymizing user, removing its memberships and recording tombstone at once,
+// previous avatar version is returned so its images can be removed.
+func (r *Repository) EraseUser(ctx context.Context, id int64) (string, error) {
+	const source = "repository.EraseUser"
+	var avatarVersion string
+	err := r.pool.QueryRow(ctx, eraseUser, tenant.FromContext(ctx), id).Scan(&avatarVersion)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return "", models.ErrUserDoesNotExist
+		case errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation && pgErr.ConstraintName == tombstoneKey:
+			return "", models.ErrUserIsErased
+		}
+		return "", fmt.Errorf(models.ErrTraceLayout, source, "error in erasing user: "+err.Error())
+	}
+	return avatarVersion, nil
+}
+
+// ListUserEvents - audit history of user, the oldest event first, events of erased user are kept.
+func (r *Repository) ListUserEvents(ctx context.Context, userID int64) ([]models.UserEvent, error) {
+	const source = "repository.ListUserEvents"
+	rows, err := r.pool.Query(ctx, listUserEvents, tenant.FromContext(ctx), userID)
+	if err != nil {
+		return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in listing user events: "+err.Error())
+	}
+	defer rows.Close()
+
+	var events []models.UserEvent
+	for rows.Next() {
+		var event models.UserEvent
+		if err = rows.Scan(&event.Type, &event.OccurredAt); err != nil {
+			return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in scanning user event: "+err.Error())
+		}
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in listing user events: "+err.Error())
+	}
+	return events, nil
+}
+
+// GetUsers - getting users by ids including deleted ones, missing ids are absent in the result.
+func (r *Repository) GetUsers(ctx context.Context, ids []int64) (map[int64]*models.UserInfo, error) {
+	const source = "repository.GetUsers"
+	rows, err := r.pool.Query(ctx, getUsers, tenant.FromContext(ctx), ids)
+	if err != nil {
+		return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in getting users info: "+err.Error())
+	}
+	defer rows.Close()
+
+	users := make(map[int64]*models.UserInfo, len(ids))
+	for rows.Next() {
+		userInfo := new(models.UserInfo)
+		if err = scanUser(rows, userInfo); err != nil {
+			return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in scanning user info: "+err.Error())
+		}
+		users[userInfo.ID] = userInfo
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in getting users info: "+err.Error())
+	}
+	return users, nil
+}
+
+// ListUsers - getting active users matching filter ordered by id.
+func (r *Repository) ListUsers(ctx context.Context, filter models.UserFilter) ([]models.UserInfo, error) {
+	const source = "repository.ListUsers"
+	query, args := buildListUsersQuery(tenant.FromContext(ctx), filter)
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in listing users: "+err.Error())
+	}
+	defer rows.Close()
+
+	users := make([]models.UserInfo, 0, filter.Limit)
+	for rows.Next() {
+		var userInfo models.UserInfo
+		if err = scanUser(rows, &userInfo); err != nil {
+			return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in scanning user info: "+err.Error())
+		}
+		users = append(users, userInfo)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in listing users: "+err.Error())
+	}
+	return users, nil
+}
+
+// TakenUsernames - usernames among normalized ones which are held by active users.
+func (r *Repository) TakenUsernames(ctx context.Context, usernames []string) (map[string]bool, error) {
+	const source = "repository.TakenUsernames"
+	rows, err := r.pool.Query(ctx, takenUsernames, tenant.FromContext(ctx), usernames)
+	if err != nil {
+		return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in checking usernames: "+err.Error())
+	}
+	defer rows.Close()
+
+	taken := make(map[string]bool, len(usernames))
+	for rows.Next() {
+		var username string
+		if err = rows.Scan(&username); err != nil {
+			return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in scanning username: "+err.Error())
+		}
+		taken[username] = true
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in checking usernames: "+err.Error())
+	}
+	return taken, nil
+}
+
+// SearchUsers - getting active users whose names contain words starting with every term
+// or whose username or email is similar to terms, the most relevant first.
+func (r *Repository) SearchUsers(ctx context.Context, search models.UserSearch) ([]models.UserSearchHit, error) {
+	const source = "repository.SearchUsers"
+	terms := search.Terms()
+	rows, err := r.pool.Query(ctx, searchUsers, tenant.FromContext(ctx), prefixTSQuery(terms), strings.Join(terms, " "),
+		search.Limit, search.Offset)
+	if err != nil {
+		return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in searching users: "+err.Error())
+	}
+	defer rows.Close()
+
+	hits := make([]models.UserSearchHit, 0, search.Limit)
+	for rows.Next() {
+		var hit models.UserSearchHit
+		if err = rows.Scan(append(userFields(&hit.User), &hit.Rank)...); err != nil {
+			return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in scanning user info: "+err.Error())
+		}
+		hits = append(hits, hit)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in searching users: "+err.Error())
+	}
+	return hits, nil
+}
+
+// ListAttributeSchemas - getting all attribute schemas ordered by name.
+func (r *Repository) ListAttributeSchemas(ctx context.Context) ([]models.AttributeSchema, error) {
+	const source = "repository.ListAttributeSchemas"
+	rows, err := r.pool.Query(ctx, listAttributeSchemas, tenant.FromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in listing attribute schemas: "+err.Error())
+	}
+	defer rows.Close()
+
+	schemas := make([]models.AttributeSchema, 0)
+	for rows.Next() {
+		var schema models.AttributeSchema
+		if err = rows.Scan(&schema.Name, &schema.Schema, &schema.CreatedAt, &schema.UpdatedAt); err != nil {
+			return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in scanning attribute schema: "+err.Error())
+		}
+		schemas = append(schemas, schema)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in listing attribute schemas: "+err.Error())
+	}
+	return schemas, nil
+}
+
+// PutAttributeSchema - creating attribute schema or replacing the one with the same name, created tells which happened.
+func (r *Repository) PutAttributeSchema(ctx context.Context, schema models.AttributeSchema) (bool, error) {
+	const source = "repository.PutAttributeSchema"
+	var created bool
+	if err := r.pool.QueryRow(ctx, putAttributeSchema, tenant.FromContext(ctx), schema.Name, schema.Schema).Scan(&created); err != nil {
+		return false, fmt.Errorf(models.ErrTraceLayout, source, "error in putting attribute schema: "+err.Error())
+	}
+	return created, nil
+}
+
+// DeleteAttributeSchema - deleting attribute schema by name unless some user has the attribute.
+func (r *Repository) DeleteAttributeSchema(ctx context.Context, name string) error {
+	const source = "repository.DeleteAttributeSchema"
+	var deleted, found bool
+	if err := r.pool.QueryRow(ctx, deleteAttributeSchema, tenant.FromContext(ctx), name).Scan(&deleted, &found); err != nil {
+		return fmt.Errorf(models.ErrTraceLayout, source, "error in deleting attribute schema: "+err.Error())
+	}
+
+	switch {
+	case deleted:
+		return nil
+	case found:
+		return models.ErrAttributeSchemaIsInUse
+	default:
+		return models.ErrAttributeSchemaDoesNotExist
+	}
+}
+
+// CreateGroup - creating group, its id and creation time are set.
+func (r *Repository) CreateGroup(ctx context.Context, group models.Group) (*models.Group, error) {
+	const source = "repository.CreateGroup"
+	err := r.pool.QueryRow(ctx, createGroup, tenant.FromContext(ctx), group.Name, group.Description).
+		Scan(&group.ID, &group.CreatedAt)
+	if err != nil {
+		if takenGroupName(err) {
+			return nil, models.ErrGroupNameIsAlreadyTaken
+		}
+		return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in creating group: "+err.Error())
+	}
+	return &group, nil
+}
+
+// GetGroup - getting group by id.
+func (r *Repository) GetGroup(ctx context.Context, id int64) (*models.Group, error) {
+	const source = "repository.GetGroup"
+	group := new(models.Group)
+	err := r.pool.QueryRow(ctx, getGroup, tenant.FromContext(ctx), id).Scan(groupFields(group)...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrGroupDoesNotExist
+		}
+		return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in getting group: "+err.Error())
+	}
+	return group, nil
+}
+
+// ListGroups - getting page of groups ordered by name.
+func (r *Repository) ListGroups(ctx context.Context, page models.Page) ([]models.Group, error) {
+	const source = "repository.ListGroups"
+	rows, err := r.pool.Query(ctx, listGroups, tenant.FromContext(ctx), page.Limit, page.Offset)
+	if err != nil {
+		return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in listing groups: "+err.Error())
+	}
+	defer rows.Close()
+
+	groups := make([]models.Group, 0, page.Limit)
+	for rows.Next() {
+		var group models.Group
+		if err = rows.Scan(groupFields(&group)...); err != nil {
+			return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in scanning group: "+err.Error())
+		}
+		groups = append(groups, group)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in listing groups: "+err.Error())
+	}
+	return groups, nil
+}
+
+// UpdateGroup - replacing name and description of group by id.
+func (r *Repository) UpdateGroup(ctx context.Context, group models.Group) error {
+	const source = "repository.UpdateGroup"
+	tag, err := r.pool.Exec(ctx, updateGroup, tenant.FromContext(ctx), group.ID, group.Name, group.Description)
+	if err != nil {
+		if takenGroupName(err) {
+			return models.ErrGroupNameIsAlreadyTaken
+		}
+		return fmt.Errorf(models.ErrTraceLayout, source, "error in updating group: "+err.Error())
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrGroupDoesNotExist
+	}
+	return nil
+}
+
+// DeleteGroup - deleting group by id together with its memberships.
+func (r *Repository) DeleteGroup(ctx context.Context, id int64) error {
+	const source = "repository.DeleteGroup"
+	tag, err := r.pool.Exec(ctx, deleteGroup, tenant.FromContext(ctx), id)
+	if err != nil {
+		return fmt.Errorf(models.ErrTraceLayout, source, "error in deleting group: "+err.Error())
+	}
+	if tag.RowsAffected() == 0 {
+		return models.ErrGroupDoesNotExist
+	}
+	return nil
+}
+
+// PutGroupMember - adding active user to group or changing its role there, created tells which happened.
+func (r *Repository) PutGroupMember(ctx context.Context, membership models.Membership) (bool, error) {
+	const source = "repository.PutGroupMember"
+	var (
+		created      *bool
+		groupFound   bool
+		userIsActive *bool
+	)
+	err := r.pool.QueryRow(ctx, putGroupMember, tenant.FromContext(ctx), membership.GroupID, membership.UserID,
+		membership.Role).Scan(&created, &groupFound, &userIsActive)
+	if err != nil {
+		return false, fmt.Errorf(models.ErrTraceLayout, source, "error in putting group member: "+err.Error())
+	}
+
+	switch {
+	case created != nil:
+		return *created, nil
+	case !groupFound:
+		return false, models.ErrGroupDoesNotExist
+	case userIsActive == nil:
+		return false, models.ErrUserDoesNotExist
+	default:
+		return false, models.ErrUserIsGone
+	}
+}
+
+// DeleteGroupMember - removing user from group, membership of deleted user is removed too.
+func (r *Repository) DeleteGroupMember(ctx context.Context, groupID, userID int64) error {
+	const source = "repository.DeleteGroupMember"
+	var deleted, groupFound bool
+	err := r.pool.QueryRow(ctx, deleteGroupMember, tenant.FromContext(ctx), groupID, userID).Scan(&deleted, &groupFound)
+	if err != nil {
+		return fmt.Errorf(models.ErrTraceLayout, source, "error in deleting group member: "+err.Error())
+	}
+
+	switch {
+	case deleted:
+		return nil
+	case !groupFound:
+		return models.ErrGroupDoesNotExist
+	default:
+		return models.ErrMemberDoesNotExist
+	}
+}
+
+// ListGroupMembers - getting page of active members of group ordered by user id.
+func (r *Repository) ListGroupMembers(ctx context.Context, groupID int64, page models.Page) ([]models.GroupMember, error) {
+	const source = "repository.ListGroupMembers"
+	rows, err := r.pool.Query(ctx, listGroupMembers, tenant.FromContext(ctx), groupID, page.Limit, page.Offset)
+	if err != nil {
+		return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in listing group members: "+err.Error())
+	}
+	defer rows.Close()
+
+	members := make([]models.GroupMember, 0, page.Limit)
+	for rows.Next() {
+		var member models.GroupMember
+		if err = rows.Scan(&member.User.ID, &member.User.Username, &member.User.FirstName, &member.User.MiddleName,
+			&member.User.LastName, &member.Role, &member.JoinedAt, &member.UpdatedAt); err != nil {
+			return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in scanning group member: "+err.Error())
+		}
+		members = append(members, member)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in listing group members: "+err.Error())
+	}
+	return members, nil
+}
+
+// ListUserGroups - getting page of groups user is a member of ordered by name.
+func (r *Repository) ListUserGroups(ctx context.Context, userID int64, page models.Page) ([]models.UserGroup, error) {
+	const source = "repository.ListUserGroups"
+	rows, err := r.pool.Query(ctx, listUserGroups, tenant.FromContext(ctx), userID, page.Limit, page.Offset)
+	if err != nil {
+		return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in listing user groups: "+err.Error())
+	}
+	defer rows.Close()
+
+	groups := make([]models.UserGroup, 0, page.Limit)
+	for rows.Next() {
+		var group models.UserGroup
+		if err = rows.Scan(append(groupFields(&group.Group), &group.Role, &group.JoinedAt, &group.UpdatedAt)...); err != nil {
+			return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in scanning user group: "+err.Error())
+		}
+		groups = append(groups, group)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf(models.ErrTraceLayout, source, "error in listing user groups: "+err.Error())
+	}
+	return groups, nil
+}
+
+// prefixTSQuery - tsquery matching words starting with every term, terms hold only letters and digits.
+func prefixTSQuery(terms []string) string {
+	prefixes := make([]string, 0, len(terms))
+	for _, term := range terms {
+		prefixes = append(prefixes, term+":*")
+	}
+	return strings.Join(prefixes, " & ")
+}
+
+// buildListUsersQuery - adding conditions of non-zero filter fields to listUsers query of the tenant.
+func buildListUsersQuery(tenantID string, filter models.UserFilter) (string, []any) {
+	var sb strings.Builder
+	sb.WriteString(listUsers)
+	args := []any{tenantID, filter.AfterID}
+
+	addCondition := func(condition string, arg any) {
+		args = append(args, arg)
+		fmt.Fprintf(&sb, " and %s $%d", condition, len(args))
+	}
+
+	if filter.Gender != "" {
+		addCondition("gender =", filter.Gender)
+	}
+	if filter.MinAge != 0 {
+		addCondition("age >=", filter.MinAge)
+	}
+	if filter.MaxAge != 0 {
+		addCondition("age <=", filter.MaxAge)
+	}
+	if filter.UsernamePrefix != "" {
+		addCondition("username like", escapeLike(filter.UsernamePrefix)+"%")
+	}
+	if len(filter.Attributes) > 0 {
+		addCondition("attributes @>", filter.Attributes)
+	}
+
+	sb.WriteString(" order by id")
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		fmt.Fprintf(&sb, " limit $%d", len(args))
+	}
+	return sb.String(), args
+}
+
+// takenIdentifier - error of username or email held by another active user, nil for other errors.
+func takenIdentifier(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != pgerrcode.UniqueViolation {
+		return nil
+	}
+
+	if pgErr.ConstraintName == emailUniqueIndex {
+		return models.ErrEmailIsAlreadyTaken
+	}
+	return models.ErrUsernameIsAlreadyTaken
+}
+
+// takenGroupName - whether error is violation of unique group name.
+func takenGroupName(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation && pgErr.ConstraintName == groupNameUniqueIndex
+}
+
+// attributesOf - attributes of user to store, column holds an empty object for user without them.
+func attributesOf(userInfo models.UserInfo) map[string]any {
+	if userInfo.Attributes == nil {
+		return map[string]any{}
+	}
+	return userInfo.Attributes
+}
+
+// escapeLike - escaping wildcards of LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+func scanUser(row pgx.Row, userInfo *models.UserInfo) error {
+	return row.Scan(userFields(userInfo)...)
+}
+
+// userFields - destinations of user columns in the order queries select them.
+func userFields(userInfo *models.UserInfo) []any {
+	return []any{&userInfo.ID, &userInfo.Username, &userInfo.FirstName, &userInfo.MiddleName,
+		&userInfo.LastName, &userInfo.Email, &userInfo.Gender, &userInfo.Age, &userInfo.Attributes,
+		&userInfo.CreatedAt, &userInfo.UpdatedAt, &userInfo.EndDate, &userInfo.AvatarVersion, &userInfo.ErasedAt}
+}
+
+// groupFields - destinations of group columns in the order queries select them.
+func groupFields(group *models.Group) []any {
+	return []any{&group.ID, &group.Name, &group.Description, &group.CreatedAt, &group.UpdatedAt}
+}
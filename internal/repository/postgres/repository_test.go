@@ -3,10 +3,13 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/sonikq/gravitum_test_task/internal/models"
+	"github.com/sonikq/gravitum_test_task/pkg/tenant"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
@@ -71,7 +74,7 @@ func TestRepository_Integration(t *testing.T) {
 	}()
 
 	// Create repository
-	repo, err := NewStorage(ctx, connString, 5)
+	repo, err := NewStorage(ctx, connString, "", 5, true, false)
 	require.NoError(t, err)
 	defer repo.Close()
 
@@ -88,6 +91,18 @@ func TestRepository_Integration(t *testing.T) {
 		testDeleteUser(ctx, t, repo)
 	})
 
+	t.Run("ListUsers", func(t *testing.T) {
+		testListUsers(ctx, t, repo)
+	})
+
+	t.Run("SearchUsers", func(t *testing.T) {
+		testSearchUsers(ctx, t, repo)
+	})
+
+	t.Run("Attributes", func(t *testing.T) {
+		testAttributes(ctx, t, repo)
+	})
+
 	t.Run("CreateDuplicateUser", func(t *testing.T) {
 		testCreateDuplicateUser(ctx, t, repo)
 	})
@@ -95,6 +110,26 @@ func TestRepository_Integration(t *testing.T) {
 	t.Run("GetNonExistentUser", func(t *testing.T) {
 		testGetNonExistentUser(ctx, t, repo)
 	})
+
+	t.Run("Groups", func(t *testing.T) {
+		testGroups(ctx, t, repo)
+	})
+
+	t.Run("AvatarVersion", func(t *testing.T) {
+		testAvatarVersion(ctx, t, repo)
+	})
+
+	t.Run("Erasure", func(t *testing.T) {
+		testErasure(ctx, t, repo)
+	})
+
+	t.Run("Tenants", func(t *testing.T) {
+		testTenants(ctx, t, repo)
+	})
+
+	t.Run("RowSecurity", func(t *testing.T) {
+		testRowSecurity(ctx, t, repo, connString)
+	})
 }
 
 func testCreateAndGetUser(ctx context.Context, t *testing.T, repo *Repository) {
@@ -127,6 +162,8 @@ func testCreateAndGetUser(ctx context.Context, t *testing.T, repo *Repository) {
 	assert.Equal(t, user.Gender, retrievedUser.Gender)
 	assert.Equal(t, user.Age, retrievedUser.Age)
 	assert.Nil(t, retrievedUser.EndDate)
+	assert.WithinDuration(t, time.Now(), retrievedUser.CreatedAt, 5*time.Second)
+	assert.Nil(t, retrievedUser.UpdatedAt)
 }
 
 func testUpdateUser(ctx context.Context, t *testing.T, repo *Repository) {
@@ -177,6 +214,8 @@ func testUpdateUser(ctx context.Context, t *testing.T, repo *Repository) {
 	assert.Equal(t, updatedUser.Email, retrievedUpdatedUser.Email)
 	assert.Equal(t, updatedUser.Gender, retrievedUpdatedUser.Gender)
 	assert.Equal(t, updatedUser.Age, retrievedUpdatedUser.Age)
+	require.NotNil(t, retrievedUpdatedUser.UpdatedAt)
+	assert.False(t, retrievedUpdatedUser.UpdatedAt.Before(retrievedUpdatedUser.CreatedAt))
 }
 
 func testDeleteUser(ctx context.Context, t *testing.T, repo *Repository) {
@@ -213,6 +252,126 @@ func testDeleteUser(ctx context.Context, t *testing.T, repo *Repository) {
 	assert.WithinDuration(t, time.Now(), *deletedUser.EndDate, 5*time.Second)
 }
 
+func testListUsers(ctx context.Context, t *testing.T, repo *Repository) {
+	// Deleted users are skipped
+	users, err := repo.ListUsers(ctx, models.UserFilter{Limit: 10})
+	require.NoError(t, err)
+	for _, user := range users {
+		assert.Nil(t, user.EndDate)
+	}
+	require.NotEmpty(t, users)
+
+	// Next page starts after the last id of the previous one
+	firstPage, err := repo.ListUsers(ctx, models.UserFilter{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, firstPage, 1)
+
+	secondPage, err := repo.ListUsers(ctx, models.UserFilter{AfterID: firstPage[0].ID, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, secondPage, len(users)-1)
+
+	// Filters are combined
+	filtered, err := repo.ListUsers(ctx, models.UserFilter{Gender: firstPage[0].Gender, UsernamePrefix: firstPage[0].Username})
+	require.NoError(t, err)
+	require.NotEmpty(t, filtered)
+	assert.Equal(t, firstPage[0].ID, filtered[0].ID)
+
+	// Batch lookup returns deleted users too and skips missing ids
+	batch, err := repo.GetUsers(ctx, []int64{firstPage[0].ID, 3, 9999})
+	require.NoError(t, err)
+	assert.Len(t, batch, 2)
+	assert.NotNil(t, batch[3].EndDate)
+}
+
+func testSearchUsers(ctx context.Context, t *testing.T, repo *Repository) {
+	user := models.UserInfo{
+		Username:   "searchable",
+		FirstName:  "Konstantin",
+		MiddleName: "Sergeevich",
+		LastName:   "Stanislavsky",
+		Email:      "k.stanislavsky@theatre.example",
+		Gender:     "M",
+		Age:        40,
+	}
+	id, err := repo.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	// Names match words starting with every term
+	hits, err := repo.SearchUsers(ctx, models.UserSearch{Query: "konst stanisl", Limit: 10})
+	require.NoError(t, err)
+	require.NotEmpty(t, hits)
+	assert.Equal(t, id, fmt.Sprint(hits[0].User.ID))
+	assert.Positive(t, hits[0].Rank)
+
+	// Username and email match similar text despite a typo
+	hits, err = repo.SearchUsers(ctx, models.UserSearch{Query: "serchable", Limit: 10})
+	require.NoError(t, err)
+	require.NotEmpty(t, hits)
+	assert.Equal(t, "searchable", hits[0].User.Username)
+
+	// Pages continue by offset
+	hits, err = repo.SearchUsers(ctx, models.UserSearch{Query: "konst stanisl", Limit: 10, Offset: 1})
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+}
+
+func testAttributes(ctx context.Context, t *testing.T, repo *Repository) {
+	created, err := repo.PutAttributeSchema(ctx, models.AttributeSchema{Name: "locale", Schema: []byte(`{"type": "string"}`)})
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	created, err = repo.PutAttributeSchema(ctx, models.AttributeSchema{Name: "locale", Schema: []byte(`{"enum": ["en", "ru"]}`)})
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	schemas, err := repo.ListAttributeSchemas(ctx)
+	require.NoError(t, err)
+	require.Len(t, schemas, 1)
+	assert.JSONEq(t, `{"enum": ["en", "ru"]}`, string(schemas[0].Schema))
+	assert.NotNil(t, schemas[0].UpdatedAt)
+
+	user := models.UserInfo{
+		Username:   "attributed",
+		FirstName:  "Attr",
+		LastName:   "User",
+		Email:      "attributed@example.com",
+		Gender:     "F",
+		Age:        33,
+		Attributes: map[string]any{"locale": "ru", "office": map[string]any{"floor": float64(3)}},
+	}
+	result, err := repo.CreateUser(ctx, user)
+	require.NoError(t, err)
+	id, err := strconv.ParseInt(result, 10, 64)
+	require.NoError(t, err)
+
+	stored, err := repo.GetUser(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, user.Attributes, stored.Attributes)
+
+	// Filter matches by containment, nested objects included
+	users, err := repo.ListUsers(ctx, models.UserFilter{Attributes: map[string]any{"office": map[string]any{"floor": 3}}})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, id, users[0].ID)
+
+	users, err = repo.ListUsers(ctx, models.UserFilter{Attributes: map[string]any{"locale": "en"}})
+	require.NoError(t, err)
+	assert.Empty(t, users)
+
+	// Users without attributes have an empty object
+	other, err := repo.GetUser(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{}, other.Attributes)
+
+	// Schema of attribute users have is kept
+	assert.ErrorIs(t, repo.DeleteAttributeSchema(ctx, "locale"), models.ErrAttributeSchemaIsInUse)
+	assert.ErrorIs(t, repo.DeleteAttributeSchema(ctx, "missing"), models.ErrAttributeSchemaDoesNotExist)
+
+	user.Attributes = map[string]any{}
+	require.NoError(t, repo.UpdateUser(ctx, user, id))
+	assert.NoError(t, repo.DeleteAttributeSchema(ctx, "locale"))
+}
+
 func testCreateDuplicateUser(ctx context.Context, t *testing.T, repo *Repository) {
 	// Create a test user
 	user := models.UserInfo{
@@ -233,6 +392,31 @@ func testCreateDuplicateUser(ctx context.Context, t *testing.T, repo *Repository
 	// Try to create the same user again
 	_, err = repo.CreateUser(ctx, user)
 	assert.ErrorIs(t, err, models.ErrUsernameIsAlreadyTaken)
+
+	// Username is unique regardless of case
+	sameUsername := user
+	sameUsername.Username, sameUsername.Email = "DuplicateUser", "other@example.com"
+	_, err = repo.CreateUser(ctx, sameUsername)
+	assert.ErrorIs(t, err, models.ErrUsernameIsAlreadyTaken)
+
+	// Email is unique too
+	sameEmail := user
+	sameEmail.Username, sameEmail.Email = "otheruser", "Duplicate@Example.com"
+	_, err = repo.CreateUser(ctx, sameEmail)
+	assert.ErrorIs(t, err, models.ErrEmailIsAlreadyTaken)
+
+	// Deleted user does not hold username and email, but can not be restored while they are taken
+	id, err := strconv.ParseInt(result, 10, 64)
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteUser(ctx, id))
+	_, err = repo.CreateUser(ctx, user)
+	require.NoError(t, err)
+	assert.ErrorIs(t, repo.RestoreUser(ctx, id), models.ErrUsernameIsAlreadyTaken)
+
+	// Only usernames of active users are taken
+	taken, err := repo.TakenUsernames(ctx, []string{"duplicateuser", "deleteuser", "freeuser"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"duplicateuser": true}, taken)
 }
 
 func testGetNonExistentUser(ctx context.Context, t *testing.T, repo *Repository) {
@@ -240,3 +424,317 @@ func testGetNonExistentUser(ctx context.Context, t *testing.T, repo *Repository)
 	_, err := repo.GetUser(ctx, 9999)
 	assert.ErrorIs(t, err, models.ErrUserDoesNotExist)
 }
+
+func testGroups(ctx context.Context, t *testing.T, repo *Repository) {
+	createUser := func(username string) int64 {
+		result, err := repo.CreateUser(ctx, models.UserInfo{
+			Username:  username,
+			FirstName: "Group",
+			LastName:  "Member",
+			Email:     username + "@example.com",
+			Gender:    "F",
+			Age:       30,
+		})
+		require.NoError(t, err)
+		id, err := strconv.ParseInt(result, 10, 64)
+		require.NoError(t, err)
+		return id
+	}
+	alice, bob := createUser("group_alice"), createUser("group_bob")
+
+	team, err := repo.CreateGroup(ctx, models.Group{Name: "Platform", Description: "platform team"})
+	require.NoError(t, err)
+	assert.NotZero(t, team.ID)
+	_, err = repo.CreateGroup(ctx, models.Group{Name: "PLATFORM"})
+	assert.ErrorIs(t, err, models.ErrGroupNameIsAlreadyTaken)
+
+	other, err := repo.CreateGroup(ctx, models.Group{Name: "Billing"})
+	require.NoError(t, err)
+	assert.ErrorIs(t, repo.UpdateGroup(ctx, models.Group{ID: other.ID, Name: "platform"}), models.ErrGroupNameIsAlreadyTaken)
+	require.NoError(t, repo.UpdateGroup(ctx, models.Group{ID: other.ID, Name: "Accounting"}))
+
+	groups, err := repo.ListGroups(ctx, models.Page{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "Accounting", groups[0].Name)
+
+	// Members are added once, putting them again changes role
+	created, err := repo.PutGroupMember(ctx, models.Membership{GroupID: team.ID, UserID: alice, Role: models.GroupRoleOwner})
+	require.NoError(t, err)
+	assert.True(t, created)
+	created, err = repo.PutGroupMember(ctx, models.Membership{GroupID: team.ID, UserID: bob, Role: models.GroupRoleMember})
+	require.NoError(t, err)
+	assert.True(t, created)
+	created, err = repo.PutGroupMember(ctx, models.Membership{GroupID: team.ID, UserID: bob, Role: models.GroupRoleAdmin})
+	require.NoError(t, err)
+	assert.False(t, created)
+	_, err = repo.PutGroupMember(ctx, models.Membership{GroupID: other.ID, UserID: bob, Role: models.GroupRoleMember})
+	require.NoError(t, err)
+
+	_, err = repo.PutGroupMember(ctx, models.Membership{GroupID: -1, UserID: bob, Role: models.GroupRoleMember})
+	assert.ErrorIs(t, err, models.ErrGroupDoesNotExist)
+	_, err = repo.PutGroupMember(ctx, models.Membership{GroupID: team.ID, UserID: -1, Role: models.GroupRoleMember})
+	assert.ErrorIs(t, err, models.ErrUserDoesNotExist)
+
+	members, err := repo.ListGroupMembers(ctx, team.ID, models.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, "group_alice", members[0].User.Username)
+	assert.Equal(t, models.GroupRoleAdmin, members[1].Role)
+	assert.NotNil(t, members[1].UpdatedAt)
+
+	userGroups, err := repo.ListUserGroups(ctx, bob, models.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, userGroups, 2)
+	assert.Equal(t, "Accounting", userGroups[0].Group.Name)
+	assert.Equal(t, "Platform", userGroups[1].Group.Name)
+
+	// Memberships of deleted user are hidden until it is restored, it can not join groups meanwhile
+	require.NoError(t, repo.DeleteUser(ctx, bob))
+	members, err = repo.ListGroupMembers(ctx, team.ID, models.Page{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, members, 1)
+	_, err = repo.PutGroupMember(ctx, models.Membership{GroupID: team.ID, UserID: bob, Role: models.GroupRoleMember})
+	assert.ErrorIs(t, err, models.ErrUserIsGone)
+
+	require.NoError(t, repo.RestoreUser(ctx, bob))
+	members, err = repo.ListGroupMembers(ctx, team.ID, models.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, models.GroupRoleAdmin, members[1].Role)
+
+	require.NoError(t, repo.DeleteGroupMember(ctx, team.ID, bob))
+	assert.ErrorIs(t, repo.DeleteGroupMember(ctx, team.ID, bob), models.ErrMemberDoesNotExist)
+	assert.ErrorIs(t, repo.DeleteGroupMember(ctx, -1, bob), models.ErrGroupDoesNotExist)
+
+	// Deleting group deletes its memberships, groups of other tenants are invisible
+	acme := tenant.WithID(ctx, "acme")
+	_, err = repo.GetGroup(acme, team.ID)
+	assert.ErrorIs(t, err, models.ErrGroupDoesNotExist)
+	assert.ErrorIs(t, repo.DeleteGroup(acme, team.ID), models.ErrGroupDoesNotExist)
+
+	require.NoError(t, repo.DeleteGroup(ctx, team.ID))
+	_, err = repo.GetGroup(ctx, team.ID)
+	assert.ErrorIs(t, err, models.ErrGroupDoesNotExist)
+	userGroups, err = repo.ListUserGroups(ctx, alice, models.Page{})
+	require.NoError(t, err)
+	assert.Empty(t, userGroups)
+}
+
+func testAvatarVersion(ctx context.Context, t *testing.T, repo *Repository) {
+	result, err := repo.CreateUser(ctx, models.UserInfo{
+		Username:  "avatar_user",
+		FirstName: "Avatar",
+		LastName:  "User",
+		Email:     "avatar_user@example.com",
+		Gender:    "O",
+		Age:       30,
+	})
+	require.NoError(t, err)
+	id, err := strconv.ParseInt(result, 10, 64)
+	require.NoError(t, err)
+
+	previous, err := repo.SetAvatarVersion(ctx, id, "v1")
+	require.NoError(t, err)
+	assert.Empty(t, previous)
+
+	previous, err = repo.SetAvatarVersion(ctx, id, "v2")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", previous)
+
+	user, err := repo.GetUser(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", user.AvatarVersion)
+	assert.NotNil(t, user.UpdatedAt, "avatar change updates the user")
+
+	require.NoError(t, repo.DeleteUser(ctx, id))
+	_, err = repo.SetAvatarVersion(ctx, id, "v3")
+	assert.ErrorIs(t, err, models.ErrUserDoesNotExist, "deleted user keeps the avatar")
+
+	_, err = repo.SetAvatarVersion(ctx, 999999, "v1")
+	assert.ErrorIs(t, err, models.ErrUserDoesNotExist)
+}
+
+func testErasure(ctx context.Context, t *testing.T, repo *Repository) {
+	user := models.UserInfo{
+		Username:   "erasure_user",
+		FirstName:  "Erasure",
+		MiddleName: "Middle",
+		LastName:   "User",
+		Email:      "erasure_user@example.com",
+		Gender:     "F",
+		Age:        41,
+		Attributes: map[string]any{"department": "legal"},
+	}
+	result, err := repo.CreateUser(ctx, user)
+	require.NoError(t, err)
+	id, err := strconv.ParseInt(result, 10, 64)
+	require.NoError(t, err)
+
+	_, err = repo.SetAvatarVersion(ctx, id, "v1")
+	require.NoError(t, err)
+	group, err := repo.CreateGroup(ctx, models.Group{Name: "Erasure"})
+	require.NoError(t, err)
+	_, err = repo.PutGroupMember(ctx, models.Membership{GroupID: group.ID, UserID: id, Role: models.GroupRoleMember})
+	require.NoError(t, err)
+
+	avatarVersion, err := repo.EraseUser(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", avatarVersion)
+
+	erased, err := repo.GetUser(ctx, id)
+	require.NoError(t, err)
+	assert.NotNil(t, erased.ErasedAt)
+	assert.NotNil(t, erased.EndDate, "erased user is deleted")
+	assert.Equal(t, "erased-"+result, erased.Username)
+	assert.Empty(t, erased.FirstName)
+	assert.Empty(t, erased.MiddleName)
+	assert.Empty(t, erased.LastName)
+	assert.Empty(t, erased.Email)
+	assert.Empty(t, erased.Gender)
+	assert.Zero(t, erased.Age)
+	assert.Empty(t, erased.Attributes)
+	assert.Empty(t, erased.AvatarVersion)
+
+	groups, err := repo.ListUserGroups(ctx, id, models.Page{})
+	require.NoError(t, err)
+	assert.Empty(t, groups, "memberships are erased")
+
+	_, err = repo.EraseUser(ctx, id)
+	assert.ErrorIs(t, err, models.ErrUserIsErased)
+	require.NoError(t, repo.RestoreUser(ctx, id))
+	erased, err = repo.GetUser(ctx, id)
+	require.NoError(t, err)
+	assert.NotNil(t, erased.EndDate, "erased user can not be restored")
+
+	// History is kept, failed erasure and restoration record nothing
+	events, err := repo.ListUserEvents(ctx, id)
+	require.NoError(t, err)
+	types := make([]models.UserEventType, 0, len(events))
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []models.UserEventType{models.UserCreated, models.UserUpdated, models.UserErased}, types)
+
+	// Username and email of erased user are free
+	_, err = repo.CreateUser(ctx, user)
+	require.NoError(t, err)
+
+	_, err = repo.EraseUser(ctx, 999999)
+	assert.ErrorIs(t, err, models.ErrUserDoesNotExist)
+}
+
+func testTenants(ctx context.Context, t *testing.T, repo *Repository) {
+	acme := tenant.WithID(ctx, "acme")
+
+	// Username and email taken in the default tenant are free in another one
+	user := models.UserInfo{
+		Username:  "testuser",
+		FirstName: "Acme",
+		LastName:  "User",
+		Email:     "test@example.com",
+		Gender:    "M",
+		Age:       30,
+	}
+	result, err := repo.CreateUser(acme, user)
+	require.NoError(t, err)
+	id, err := strconv.ParseInt(result, 10, 64)
+	require.NoError(t, err)
+
+	_, err = repo.CreateUser(acme, user)
+	assert.ErrorIs(t, err, models.ErrUsernameIsAlreadyTaken)
+
+	// Users of other tenants are invisible
+	_, err = repo.GetUser(ctx, id)
+	assert.ErrorIs(t, err, models.ErrUserDoesNotExist)
+	_, err = repo.GetUser(acme, 1)
+	assert.ErrorIs(t, err, models.ErrUserDoesNotExist)
+
+	users, err := repo.GetUsers(acme, []int64{1, id})
+	require.NoError(t, err)
+	assert.Len(t, users, 1)
+	assert.Contains(t, users, id)
+
+	listed, err := repo.ListUsers(acme, models.UserFilter{})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "Acme", listed[0].FirstName)
+
+	hits, err := repo.SearchUsers(acme, models.UserSearch{Query: "testuser", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, id, hits[0].User.ID)
+
+	// Writes do not reach users of other tenants
+	require.NoError(t, repo.DeleteUser(acme, 1))
+	stored, err := repo.GetUser(ctx, 1)
+	require.NoError(t, err)
+	assert.Nil(t, stored.EndDate)
+
+	// Attribute schemas are registered per tenant
+	_, err = repo.PutAttributeSchema(acme, models.AttributeSchema{Name: "badge", Schema: []byte(`{"type": "string"}`)})
+	require.NoError(t, err)
+	schemas, err := repo.ListAttributeSchemas(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, schemas)
+	assert.ErrorIs(t, repo.DeleteAttributeSchema(ctx, "badge"), models.ErrAttributeSchemaDoesNotExist)
+	assert.NoError(t, repo.DeleteAttributeSchema(acme, "badge"))
+}
+
+func testRowSecurity(ctx context.Context, t *testing.T, repo *Repository, connString string) {
+	// Policies apply to roles which do not own the tables
+	for _, statement := range []string{
+		`create role tenant_app login password 'tenant_app'`,
+		`grant select, insert, update, delete on users, attribute_schemas to tenant_app`,
+		`grant usage on sequence users_id_seq to tenant_app`,
+	} {
+		_, err := repo.pool.Exec(ctx, statement)
+		require.NoError(t, err)
+	}
+
+	// The owner is not restricted, so row security is refused for it
+	_, err := NewStorage(ctx, connString, "", 2, false, true)
+	assert.ErrorIs(t, err, ErrRowSecurityBypassed)
+
+	restricted, err := NewStorage(ctx, strings.Replace(connString, "test:test@", "tenant_app:tenant_app@", 1), "", 2, false, true)
+	require.NoError(t, err)
+	defer restricted.Close()
+
+	// Rows of other tenants are hidden even from queries without tenant condition
+	acme := tenant.WithID(ctx, "acme")
+	var count, expected int
+	require.NoError(t, restricted.pool.QueryRow(acme, `select count(*) from users`).Scan(&count))
+	require.NoError(t, repo.pool.QueryRow(ctx, `select count(*) from users where tenant_id = 'acme'`).Scan(&expected))
+	assert.Equal(t, expected, count)
+
+	_, err = restricted.pool.Exec(acme, `insert into users(tenant_id, username, first_name, last_name, email, beg_date)
+values ('default', 'intruder', 'In', 'Truder', 'intruder@example.com', now())`)
+	assert.ErrorContains(t, err, "row-level security")
+
+	users, err := restricted.ListUsers(acme, models.UserFilter{})
+	require.NoError(t, err)
+	assert.Len(t, users, 1)
+}
+
+func TestPrefixTSQuery(t *testing.T) {
+	assert.Equal(t, "ivan:* & petrov:*", prefixTSQuery([]string{"ivan", "petrov"}))
+	assert.Equal(t, "", prefixTSQuery(nil))
+}
+
+func TestBuildListUsersQuery(t *testing.T) {
+	query, args := buildListUsersQuery("acme", models.UserFilter{
+		AfterID:        10,
+		Limit:          20,
+		Gender:         "F",
+		MaxAge:         30,
+		UsernamePrefix: "a_b%",
+		Attributes:     map[string]any{"locale": "ru"},
+	})
+
+	assert.Equal(t, listUsers+" and gender = $3 and age <= $4 and username like $5 and attributes @> $6 order by id limit $7", query)
+	assert.Equal(t, []any{"acme", int64(10), "F", uint8(30), `a\_b\%%`, map[string]any{"locale": "ru"}, 20}, args)
+
+	query, args = buildListUsersQuery(tenant.Default, models.UserFilter{})
+	assert.Equal(t, listUsers+" order by id", query)
+	assert.Equal(t, []any{tenant.Default, int64(0)}, args)
+}
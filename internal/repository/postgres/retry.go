@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"errors"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sonikq/gravitum_test_task/pkg/retrier"
+)
+
+// IsRetryable - whether operation failed with err may succeed when repeated:
+// connection problems, server starting up or shutting down, serialization failures and deadlocks.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return isTransientCode(pgErr.Code)
+	}
+
+	if pgconn.SafeToRetry(err) || pgconn.Timeout(err) {
+		return true
+	}
+
+	return retrier.IsNetworkError(err)
+}
+
+func isTransientCode(code string) bool {
+	if pgerrcode.IsConnectionException(code) {
+		return true
+	}
+
+	switch code {
+	case pgerrcode.SerializationFailure,
+		pgerrcode.DeadlockDetected,
+		pgerrcode.TooManyConnections,
+		pgerrcode.LockNotAvailable,
+		pgerrcode.AdminShutdown,
+		pgerrcode.CrashShutdown,
+		pgerrcode.CannotConnectNow:
+		return true
+	}
+	return false
+}
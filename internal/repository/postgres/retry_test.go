@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryable(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "nil", err: nil, expected: false},
+		{name: "connection refused", err: fmt.Errorf(models.ErrWrapTraceLayout, "test", syscall.ECONNREFUSED), expected: true},
+		{name: "server starting up", err: &pgconn.PgError{Code: pgerrcode.CannotConnectNow}, expected: true},
+		{name: "serialization failure", err: &pgconn.PgError{Code: pgerrcode.SerializationFailure}, expected: true},
+		{name: "connection exception class", err: &pgconn.PgError{Code: pgerrcode.ConnectionFailure}, expected: true},
+		{name: "invalid password", err: &pgconn.PgError{Code: pgerrcode.InvalidPassword}, expected: false},
+		{name: "unique violation", err: &pgconn.PgError{Code: pgerrcode.UniqueViolation}, expected: false},
+		{name: "plain error", err: errors.New("syntax error"), expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsRetryable(tc.err))
+		})
+	}
+}
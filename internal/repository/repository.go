@@ -5,16 +5,72 @@ import (
 	"github.com/sonikq/gravitum_test_task/internal/config"
 	"github.com/sonikq/gravitum_test_task/internal/models"
 	"github.com/sonikq/gravitum_test_task/internal/repository/postgres"
+	"github.com/sonikq/gravitum_test_task/pkg/logger"
+	"github.com/sonikq/gravitum_test_task/pkg/retrier"
 )
 
 type IRepository interface {
 	Close()
+	Ping(ctx context.Context) error
+	CheckMigrations(ctx context.Context) error
 	CreateUser(ctx context.Context, body models.UserInfo) (string, error)
 	GetUser(ctx context.Context, id int64) (*models.UserInfo, error)
 	UpdateUser(ctx context.Context, body models.UserInfo, id int64) error
 	DeleteUser(ctx context.Context, id int64) error
+	RestoreUser(ctx context.Context, id int64) error
+	GetUsers(ctx context.Context, ids []int64) (map[int64]*models.UserInfo, error)
+	ListUsers(ctx context.Context, filter models.UserFilter) ([]models.UserInfo, error)
+	TakenUsernames(ctx context.Context, usernames []string) (map[string]bool, error)
+	SearchUsers(ctx context.Context, search models.UserSearch) ([]models.UserSearchHit, error)
+	ListAttributeSchemas(ctx context.Context) ([]models.AttributeSchema, error)
+	PutAttributeSchema(ctx context.Context, schema models.AttributeSchema) (bool, error)
+	DeleteAttributeSchema(ctx context.Context, name string) error
+	CreateGroup(ctx context.Context, group models.Group) (*models.Group, error)
+	GetGroup(ctx context.Context, id int64) (*models.Group, error)
+	ListGroups(ctx context.Context, page models.Page) ([]models.Group, error)
+	UpdateGroup(ctx context.Context, group models.Group) error
+	DeleteGroup(ctx context.Context, id int64) error
+	PutGroupMember(ctx context.Context, membership models.Membership) (bool, error)
+	DeleteGroupMember(ctx context.Context, groupID, userID int64) error
+	ListGroupMembers(ctx context.Context, groupID int64, page models.Page) ([]models.GroupMember, error)
+	ListUserGroups(ctx context.Context, userID int64, page models.Page) ([]models.UserGroup, error)
+	SetAvatarVersion(ctx context.Context, id int64, version string) (string, error)
+	EraseUser(ctx context.Context, id int64) (string, error)
+	ListUserEvents(ctx context.Context, userID int64) ([]models.UserEvent, error)
 }
 
-func New(ctx context.Context, cfg config.Config) (IRepository, error) {
-	return postgres.NewStorage(ctx, cfg.DatabaseDSN, cfg.DBPoolWorkers)
+// New - connecting to DB and applying migrations, transient failures are retried
+// with exponential backoff until ctx is done.
+func New(ctx context.Context, cfg config.Config, lg *logger.Logger) (IRepository, error) {
+	var repo *postgres.Repository
+	err := retrier.Do(ctx, retrier.Config{
+		Policy: retrier.Exponential{
+			Initial:    cfg.StartupRetryInitial,
+			Max:        cfg.StartupRetryMax,
+			Multiplier: cfg.StartupRetryMultiplier,
+			Jitter:     cfg.StartupRetryJitter,
+		},
+		Retryable: postgres.IsRetryable,
+		OnRetry: func(attempt retrier.Attempt) {
+			event := lg.Warn()
+			if attempt.Final {
+				event = lg.Error()
+			}
+			event.Err(attempt.Err).
+				Int("attempt", attempt.Number).
+				Str("elapsed", attempt.Elapsed.String()).
+				Str("retry_in", attempt.Delay.String()).
+				Bool("final", attempt.Final).
+				Msg("failed to initialize repository")
+		},
+	}, func(ctx context.Context) error {
+		var err error
+		repo, err = postgres.NewStorage(ctx, cfg.DatabaseDSN, cfg.DatabaseMigrationDSN, cfg.DBPoolWorkers,
+			cfg.AutoMigrate, cfg.DBRowSecurity)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return repo, nil
 }
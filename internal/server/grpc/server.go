@@ -0,0 +1,69 @@
+package grpc
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"net"
+)
+
+type Server struct {
+	address    string
+	grpcServer *grpc.Server
+	health     *health.Server
+}
+
+// NewServer - creating server with services added by register, health service and server reflection.
+func NewServer(address string, register func(s *grpc.Server), opts ...grpc.ServerOption) *Server {
+	grpcServer := grpc.NewServer(opts...)
+	register(grpcServer)
+
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	reflection.Register(grpcServer)
+
+	return &Server{
+		address:    address,
+		grpcServer: grpcServer,
+		health:     healthServer,
+	}
+}
+
+// Run - listening on address and serving until Shutdown, health of every registered service is SERVING.
+func (s *Server) Run() error {
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Serve - serving on the given listener.
+func (s *Server) Serve(listener net.Listener) error {
+	for name := range s.grpcServer.GetServiceInfo() {
+		s.health.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+	return s.grpcServer.Serve(listener)
+}
+
+// Shutdown - reporting NOT_SERVING and waiting for in-flight calls,
+// remaining connections are closed forcibly when ctx is done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.health.Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		return ctx.Err()
+	}
+}
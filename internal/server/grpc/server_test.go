@@ -0,0 +1,117 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startServer runs server on in-memory listener and returns connected client
+func startServer(t *testing.T, server *Server) *grpc.ClientConn {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+	t.Cleanup(func() {
+		if err := <-errCh; err != nil {
+			t.Errorf("Expected no serve error, got: %v", err)
+		}
+	})
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// TestServer_HealthAndReflection tests that health and reflection services are registered
+func TestServer_HealthAndReflection(t *testing.T) {
+	server := NewServer("", func(s *grpc.Server) {})
+	conn := startServer(t, server)
+	ctx := context.Background()
+
+	healthClient := healthpb.NewHealthClient(conn)
+	resp, err := healthClient.Check(ctx, &healthpb.HealthCheckRequest{Service: healthpb.Health_ServiceDesc.ServiceName})
+	if err != nil {
+		t.Fatalf("Expected health check to succeed, got: %v", err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("Expected SERVING, got %v", resp.Status)
+	}
+
+	streamCtx, cancelStream := context.WithCancel(ctx)
+	defer cancelStream()
+	stream, err := grpc_reflection_v1.NewServerReflectionClient(conn).ServerReflectionInfo(streamCtx)
+	if err != nil {
+		t.Fatalf("Expected reflection stream, got: %v", err)
+	}
+	err = stream.Send(&grpc_reflection_v1.ServerReflectionRequest{
+		MessageRequest: &grpc_reflection_v1.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		t.Fatalf("Failed to send reflection request: %v", err)
+	}
+	reflResp, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Failed to receive reflection response: %v", err)
+	}
+	if len(reflResp.GetListServicesResponse().GetService()) == 0 {
+		t.Errorf("Expected services to be listed")
+	}
+	cancelStream()
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err = server.Shutdown(shutdownCtx); err != nil {
+		t.Errorf("Expected graceful shutdown, got: %v", err)
+	}
+}
+
+// TestServer_RunInvalidAddress tests that listen errors are returned
+func TestServer_RunInvalidAddress(t *testing.T) {
+	server := NewServer(":99999", func(s *grpc.Server) {})
+
+	if err := server.Run(); err == nil {
+		t.Errorf("Expected listen error, got nil")
+	}
+}
+
+// TestServer_ShutdownTimeout tests that hanging calls are cut off when ctx is done
+func TestServer_ShutdownTimeout(t *testing.T) {
+	server := NewServer("", func(s *grpc.Server) {})
+	conn := startServer(t, server)
+
+	// Watch stream keeps the call open until the server stops
+	stream, err := healthpb.NewHealthClient(conn).Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Expected watch stream, got: %v", err)
+	}
+	if _, err = stream.Recv(); err != nil {
+		t.Fatalf("Expected first watch response, got: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err = server.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline error, got: %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// AdminAuth - bearer token of admin routes, token can be changed while serving.
+type AdminAuth struct {
+	token atomic.Pointer[string]
+}
+
+// NewAdminAuth - creating middleware of admin routes, empty token disables them.
+func NewAdminAuth(token string) *AdminAuth {
+	a := &AdminAuth{}
+	a.SetToken(token)
+	return a
+}
+
+// SetToken - applying new token to subsequent requests.
+func (a *AdminAuth) SetToken(token string) {
+	a.token.Store(&token)
+}
+
+// Handler - letting through requests with "Authorization: Bearer <token>", others are answered with 401.
+// While token is empty every request is answered with 403.
+func (a *AdminAuth) Handler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token := *a.token.Load()
+		if token == "" {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{models.ErrMsgKey: "admin api is disabled"})
+			return
+		}
+
+		scheme, credentials, _ := strings.Cut(ctx.GetHeader("Authorization"), " ")
+		if !strings.EqualFold(scheme, "Bearer") ||
+			subtle.ConstantTimeCompare([]byte(strings.TrimSpace(credentials)), []byte(token)) != 1 {
+			ctx.Header("WWW-Authenticate", `Bearer realm="admin"`)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{models.ErrMsgKey: "invalid admin token"})
+			return
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sync/atomic"
+)
+
+// CacheControl - Cache-Control values of routes, values can be changed while serving.
+type CacheControl struct {
+	policies atomic.Pointer[map[string]string]
+}
+
+// NewCacheControl - creating Cache-Control values of routes, routes without value get no header.
+func NewCacheControl(policies map[string]string) *CacheControl {
+	cc := &CacheControl{}
+	cc.SetPolicies(policies)
+	return cc
+}
+
+// SetPolicies - applying new values to subsequent requests.
+func (cc *CacheControl) SetPolicies(policies map[string]string) {
+	cc.policies.Store(&policies)
+}
+
+// Handler - setting Cache-Control of route on successful and not modified responses,
+// errors are not cached. Route is a key of policies, not the path of the request.
+func (cc *CacheControl) Handler(route string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		policy := (*cc.policies.Load())[route]
+		if policy == "" {
+			ctx.Next()
+			return
+		}
+
+		ctx.Writer = &cacheControlWriter{ResponseWriter: ctx.Writer, policy: policy}
+		ctx.Next()
+	}
+}
+
+type cacheControlWriter struct {
+	gin.ResponseWriter
+	policy string
+}
+
+func (w *cacheControlWriter) WriteHeader(code int) {
+	if code < http.StatusBadRequest && w.Header().Get("Cache-Control") == "" {
+		w.Header().Set("Cache-Control", w.policy)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"net/http"
+	"sync/atomic"
+)
+
+// Features - feature toggles of routes, toggles can be changed while serving.
+type Features struct {
+	disabled atomic.Pointer[map[string]bool]
+}
+
+// NewFeatures - creating toggles with every feature enabled except disabled ones.
+func NewFeatures(disabled map[string]bool) *Features {
+	f := &Features{}
+	f.SetDisabled(disabled)
+	return f
+}
+
+// SetDisabled - applying new set of disabled features to subsequent requests.
+func (f *Features) SetDisabled(disabled map[string]bool) {
+	f.disabled.Store(&disabled)
+}
+
+// Require - answering 404 while feature is disabled, as if the route did not exist.
+func (f *Features) Require(feature string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if (*f.disabled.Load())[feature] {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{models.ErrMsgKey: "feature " + feature + " is disabled"})
+			return
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,278 @@
+package middleware
+
+import (
+	"bytes"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/gravitum_test_task/internal/config"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"github.com/sonikq/gravitum_test_task/pkg/logger"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// OpenAPIValidator - checking requests and responses of routes described in doc against it,
+// mode is one of config.Validation*. In log mode violations are only logged, in strict mode invalid
+// request is answered with 400 and invalid response is replaced with 500. Routes missing in doc are passed as is.
+// Request bodies of operations without JSON media types, such as uploads, are left to handlers,
+// which read them with their own limits. Response bodies of media types without a decoder, such as
+// images and archives, are not checked and not held in memory: they are streamed once status and
+// headers are checked.
+func OpenAPIValidator(doc *openapi3.T, mode string, l *logger.Logger) (gin.HandlerFunc, error) {
+	if mode == config.ValidationOff {
+		return func(ctx *gin.Context) { ctx.Next() }, nil
+	}
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	registerJSONDecoders(doc)
+
+	options := &openapi3filter.Options{
+		IncludeResponseStatus: true,
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+	}
+	// schema errors are reported without the schema and the value, they are echoed to clients
+	options.WithCustomSchemaErrorFunc(func(err *openapi3.SchemaError) string {
+		if pointer := err.JSONPointer(); len(pointer) > 0 {
+			return strings.Join(pointer, ".") + ": " + err.Reason
+		}
+		return err.Reason
+	})
+	// parameters and headers are still checked when bodies are not
+	bodiless := *options
+	bodiless.ExcludeRequestBody = true
+	bodiless.ExcludeResponseBody = true
+	strict := mode == config.ValidationStrict
+
+	return func(ctx *gin.Context) {
+		const source = "middleware.OpenAPIValidator"
+
+		route, pathParams, err := router.FindRoute(ctx.Request)
+		if err != nil {
+			// unknown routes are answered by gin itself
+			ctx.Next()
+			return
+		}
+
+		requestOptions := options
+		if !jsonRequestBody(route.Operation) {
+			requestOptions = &bodiless
+		}
+		requestInput := &openapi3filter.RequestValidationInput{
+			Request:    ctx.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    requestOptions,
+		}
+		if err = openapi3filter.ValidateRequest(ctx.Request.Context(), requestInput); err != nil {
+			l.Warn().
+				Err(err).
+				Str("method", ctx.Request.Method).
+				Str("uri", ctx.Request.RequestURI).
+				Str("source", source).
+				Msg("request does not match openapi spec")
+			if strict {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{models.ErrMsgKey: err.Error()})
+				return
+			}
+		}
+
+		// checking response with body, nil body is not checked; false means that the response is replaced
+		validate := func(status int, body []byte) bool {
+			responseOptions := options
+			if body == nil {
+				responseOptions = &bodiless
+			}
+			responseInput := &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: requestInput,
+				Status:                 status,
+				Header:                 ctx.Writer.Header(),
+				Body:                   io.NopCloser(bytes.NewReader(body)),
+				Options:                responseOptions,
+			}
+			if err := openapi3filter.ValidateResponse(ctx.Request.Context(), responseInput); err != nil {
+				l.Error().
+					Err(err).
+					Str("method", ctx.Request.Method).
+					Str("uri", ctx.Request.RequestURI).
+					Int("status", status).
+					Str("source", source).
+					Msg("response does not match openapi spec")
+				return !strict
+			}
+			return true
+		}
+
+		// response is held back until it is checked, so strict mode can replace it
+		writer := &validatingWriter{ResponseWriter: ctx.Writer, status: http.StatusOK}
+		writer.stream = func(status int) bool {
+			if validate(status, nil) {
+				return true
+			}
+			replaceWithError(writer.ResponseWriter)
+			return false
+		}
+		ctx.Writer = writer
+		ctx.Next()
+		ctx.Writer = writer.ResponseWriter
+
+		if writer.streaming {
+			return
+		}
+
+		body := writer.body.Bytes()
+		if writer.status == http.StatusNoContent || writer.status == http.StatusNotModified {
+			body = nil
+		} else if body == nil {
+			// empty body is still checked against the schema
+			body = []byte{}
+		}
+		if !validate(writer.status, body) {
+			replaceWithError(ctx.Writer)
+			ctx.Abort()
+			return
+		}
+
+		ctx.Writer.WriteHeader(writer.status)
+		if len(body) > 0 {
+			_, _ = ctx.Writer.Write(body)
+		} else {
+			ctx.Writer.WriteHeaderNow()
+		}
+	}, nil
+}
+
+// registerJSONDecoders - decoding bodies of structured +json media types from doc, e.g. vendor ones, as JSON.
+func registerJSONDecoders(doc *openapi3.T) {
+	register := func(content openapi3.Content) {
+		for mediaType := range content {
+			if strings.HasSuffix(mediaType, "+json") && openapi3filter.RegisteredBodyDecoder(mediaType) == nil {
+				openapi3filter.RegisterBodyDecoder(mediaType, openapi3filter.JSONBodyDecoder)
+			}
+		}
+	}
+
+	for _, item := range doc.Paths.Map() {
+		for _, op := range item.Operations() {
+			if op.RequestBody != nil && op.RequestBody.Value != nil {
+				register(op.RequestBody.Value.Content)
+			}
+			for _, resp := range op.Responses.Map() {
+				if resp.Value != nil {
+					register(resp.Value.Content)
+				}
+			}
+		}
+	}
+}
+
+// jsonRequestBody - whether operation has no request body or accepts it in a JSON media type.
+func jsonRequestBody(op *openapi3.Operation) bool {
+	if op.RequestBody == nil || op.RequestBody.Value == nil {
+		return true
+	}
+	for mediaType := range op.RequestBody.Value.Content {
+		if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+			return true
+		}
+	}
+	return false
+}
+
+// decodable - whether body of content type can be decoded for validation.
+func decodable(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err != nil || openapi3filter.RegisteredBodyDecoder(mediaType) != nil
+}
+
+// replaceWithError - answering 500 instead of response which does not match the spec.
+func replaceWithError(w gin.ResponseWriter) {
+	w.Header().Del("Content-Length")
+	w.Header().Del("Content-Disposition")
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusInternalServerError)
+	_, _ = w.WriteString(`{"` + models.ErrMsgKey + `":"internal server error, something went wrong"}`)
+}
+
+// validatingWriter - holding status and body of a response instead of sending them, so they can be checked.
+// Body which can not be checked is not held: on the first write stream is called with the status and,
+// if it allows, status, headers and body are passed through.
+type validatingWriter struct {
+	gin.ResponseWriter
+	status  int
+	written bool
+	body    bytes.Buffer
+	// stream - checking status and headers of streamed response, false means that it is replaced
+	stream    func(status int) bool
+	streaming bool
+	discard   bool
+}
+
+// start - choosing between holding and streaming of the body once its content type is known.
+func (w *validatingWriter) start() {
+	if w.written {
+		return
+	}
+	w.written = true
+	if w.status == http.StatusNoContent || w.status == http.StatusNotModified || decodable(w.Header().Get("Content-Type")) {
+		return
+	}
+
+	w.streaming = true
+	if !w.stream(w.status) {
+		w.discard = true
+		return
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *validatingWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *validatingWriter) WriteHeaderNow() {
+	w.start()
+}
+
+func (w *validatingWriter) Write(data []byte) (int, error) {
+	w.start()
+	switch {
+	case w.discard:
+		return len(data), nil
+	case w.streaming:
+		return w.ResponseWriter.Write(data)
+	}
+	return w.body.Write(data)
+}
+
+func (w *validatingWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *validatingWriter) Status() int {
+	return w.status
+}
+
+func (w *validatingWriter) Size() int {
+	if w.streaming {
+		return w.ResponseWriter.Size()
+	}
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *validatingWriter) Written() bool {
+	return w.written
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/gravitum_test_task/internal/config"
+	"github.com/sonikq/gravitum_test_task/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDoc = `{
+  "openapi": "3.0.3",
+  "info": {"title": "test", "version": "1.0.0"},
+  "paths": {
+    "/image": {"get": {"responses": {"200": {"description": "image", "content": {"image/png": {
+      "schema": {"type": "string", "format": "binary"}}}}}}},
+    "/json": {"get": {"responses": {"200": {"description": "object", "content": {"application/json": {
+      "schema": {"type": "object", "required": ["id"], "properties": {"id": {"type": "integer"}}}}}}}}}
+  }
+}`
+
+// TestOpenAPIValidator_Streaming tests that bodies which are not checked are streamed instead of held
+func TestOpenAPIValidator_Streaming(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(testDoc))
+	require.NoError(t, err)
+	lg, err := logger.NewLogger("test", logger.Option{Level: "disabled"})
+	require.NoError(t, err)
+	validator, err := OpenAPIValidator(doc, config.ValidationStrict, lg)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(validator)
+
+	var streamed int
+	rec := httptest.NewRecorder()
+	router.GET("/image", func(ctx *gin.Context) {
+		status := http.StatusOK
+		if ctx.Query("status") != "" {
+			status = http.StatusCreated
+		}
+		ctx.Data(status, "image/png", []byte("first"))
+		streamed = rec.Body.Len()
+		_, _ = ctx.Writer.Write([]byte("second"))
+	})
+	router.GET("/json", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{"id": ctx.Query("id")})
+	})
+
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/image", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "firstsecond", rec.Body.String())
+	assert.Equal(t, len("first"), streamed, "image is written before the handler returns")
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/image?status=created", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code, "status missing in the document is replaced")
+	assert.JSONEq(t, `{"error_description":"internal server error, something went wrong"}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/json?id=x", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code, "JSON body is held and checked")
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"golang.org/x/time/rate"
+	"net/http"
+)
+
+// RateLimiter - global limit of requests per second, limit can be changed while serving.
+type RateLimiter struct {
+	limiter *rate.Limiter
+}
+
+// NewRateLimiter - creating limiter, rps equal to 0 disables limiting.
+func NewRateLimiter(rps, burst int) *RateLimiter {
+	rl := &RateLimiter{limiter: rate.NewLimiter(rate.Inf, 0)}
+	rl.SetLimit(rps, burst)
+	return rl
+}
+
+// SetLimit - applying new limit to subsequent requests.
+func (rl *RateLimiter) SetLimit(rps, burst int) {
+	if rps <= 0 {
+		rl.limiter.SetLimit(rate.Inf)
+		return
+	}
+	rl.limiter.SetBurst(burst)
+	rl.limiter.SetLimit(rate.Limit(rps))
+}
+
+func (rl *RateLimiter) Handler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !rl.limiter.Allow() {
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{models.ErrMsgKey: "too many requests"})
+			return
+		}
+		ctx.Next()
+	}
+}
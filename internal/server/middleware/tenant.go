@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"github.com/sonikq/gravitum_test_task/pkg/tenant"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// Tenant - resolving tenant of requests into their context, rules can be changed while serving.
+type Tenant struct {
+	resolver atomic.Pointer[tenant.Resolver]
+}
+
+// NewTenant - creating middleware resolving tenant by resolver.
+func NewTenant(resolver tenant.Resolver) *Tenant {
+	t := &Tenant{}
+	t.SetResolver(resolver)
+	return t
+}
+
+// SetResolver - applying new rules to subsequent requests.
+func (t *Tenant) SetResolver(resolver tenant.Resolver) {
+	t.resolver.Store(&resolver)
+}
+
+// Handler - putting tenant of request into context of the request, requests with invalid tenant are rejected.
+// Handlers see it through gin.Context only if the engine has ContextWithFallback set.
+func (t *Tenant) Handler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		resolver := t.resolver.Load()
+		// responses depend on tenant, so caches must not share them between tenants
+		if resolver.Header != "" {
+			ctx.Writer.Header().Add("Vary", resolver.Header)
+		}
+		if len(resolver.Key) > 0 {
+			ctx.Writer.Header().Add("Vary", "Authorization")
+		}
+
+		id, err := resolver.Resolve(ctx.GetHeader(resolver.Header), ctx.GetHeader("Authorization"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(tenantStatus(err), gin.H{models.ErrMsgKey: err.Error()})
+			return
+		}
+
+		ctx.Request = ctx.Request.WithContext(tenant.WithID(ctx.Request.Context(), id))
+		ctx.Next()
+	}
+}
+
+// UnaryInterceptor - putting tenant of call from its metadata into context of the call,
+// standard grpc.* services, e.g. health, are not scoped by tenant.
+func (t *Tenant) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if strings.HasPrefix(info.FullMethod, "/grpc.") {
+			return handler(ctx, req)
+		}
+
+		resolver := t.resolver.Load()
+		md, _ := metadata.FromIncomingContext(ctx)
+		first := func(key string) string {
+			if values := md.Get(key); len(values) > 0 {
+				return values[0]
+			}
+			return ""
+		}
+
+		header := ""
+		if resolver.Header != "" {
+			header = first(resolver.Header)
+		}
+		id, err := resolver.Resolve(header, first("authorization"))
+		if err != nil {
+			return nil, status.Error(tenantCode(err), err.Error())
+		}
+		return handler(tenant.WithID(ctx, id), req)
+	}
+}
+
+func tenantStatus(err error) int {
+	switch {
+	case errors.Is(err, tenant.ErrInvalidToken):
+		return http.StatusUnauthorized
+	case errors.Is(err, tenant.ErrConflict):
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func tenantCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, tenant.ErrInvalidToken):
+		return codes.Unauthenticated
+	case errors.Is(err, tenant.ErrConflict):
+		return codes.PermissionDenied
+	default:
+		return codes.InvalidArgument
+	}
+}
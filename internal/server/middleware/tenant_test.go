@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sonikq/gravitum_test_task/pkg/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TestTenant_UnaryInterceptor tests resolving of tenant from metadata of gRPC calls
+func TestTenant_UnaryInterceptor(t *testing.T) {
+	resolver := NewTenant(tenant.Resolver{Header: "X-Tenant-ID", Required: true})
+	interceptor := resolver.UnaryInterceptor()
+
+	var resolved string
+	handler := func(ctx context.Context, req any) (any, error) {
+		resolved = tenant.FromContext(ctx)
+		return nil, nil
+	}
+	call := func(method string, md metadata.MD) error {
+		resolved = ""
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	const getUser = "/user_management.v1.UserService/GetUser"
+
+	require.NoError(t, call(getUser, metadata.Pairs("x-tenant-id", "acme")))
+	assert.Equal(t, "acme", resolved)
+
+	err := call(getUser, metadata.MD{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Empty(t, resolved)
+
+	// with key header alone is not trusted
+	resolver.SetResolver(tenant.Resolver{Header: "X-Tenant-ID", Key: []byte("key"), Required: true})
+	err = call(getUser, metadata.Pairs("x-tenant-id", "acme"))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Empty(t, resolved)
+
+	err = call(getUser, metadata.Pairs("authorization", "Bearer garbage"))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	require.NoError(t, call("/grpc.health.v1.Health/Check", metadata.MD{}), "health is not scoped by tenant")
+	assert.Equal(t, tenant.Default, resolved)
+}
@@ -5,6 +5,9 @@ import (
 	"github.com/sonikq/gravitum_test_task/internal/models"
 	"github.com/sonikq/gravitum_test_task/internal/repository"
 	"github.com/sonikq/gravitum_test_task/internal/service/user_management"
+	"github.com/sonikq/gravitum_test_task/pkg/blob"
+	"github.com/sonikq/gravitum_test_task/pkg/validator"
+	"io"
 )
 
 type IUserManagementService interface {
@@ -12,14 +15,36 @@ type IUserManagementService interface {
 	GetUser(ctx context.Context, id int64) (*models.UserInfo, error)
 	UpdateUser(ctx context.Context, request models.UserInfo) error
 	DeleteUser(ctx context.Context, id int64) error
+	RestoreUser(ctx context.Context, id int64) error
+	GetUsers(ctx context.Context, ids []int64) (map[int64]*models.UserInfo, error)
+	ListUsers(ctx context.Context, filter models.UserFilter) ([]models.UserInfo, error)
+	CheckUsername(ctx context.Context, username string) (models.UsernameAvailability, error)
+	SearchUsers(ctx context.Context, search models.UserSearch) ([]models.UserSearchHit, error)
+	ListAttributeSchemas(ctx context.Context) ([]models.AttributeSchema, error)
+	PutAttributeSchema(ctx context.Context, schema models.AttributeSchema) (bool, error)
+	DeleteAttributeSchema(ctx context.Context, name string) error
+	CreateGroup(ctx context.Context, group models.Group) (*models.Group, error)
+	GetGroup(ctx context.Context, id int64) (*models.Group, error)
+	ListGroups(ctx context.Context, page models.Page) ([]models.Group, error)
+	UpdateGroup(ctx context.Context, group models.Group) error
+	DeleteGroup(ctx context.Context, id int64) error
+	PutGroupMember(ctx context.Context, membership models.Membership) (bool, error)
+	DeleteGroupMember(ctx context.Context, groupID, userID int64) error
+	ListGroupMembers(ctx context.Context, groupID int64, page models.Page) ([]models.GroupMember, error)
+	ListUserGroups(ctx context.Context, userID int64, page models.Page) ([]models.UserGroup, error)
+	PutAvatar(ctx context.Context, userID int64, image []byte) (string, error)
+	GetAvatar(ctx context.Context, userID int64, size models.AvatarSize) (io.ReadCloser, models.Avatar, error)
+	DeleteAvatar(ctx context.Context, userID int64) error
+	ExportUser(ctx context.Context, id int64) (*models.UserExport, error)
+	EraseUser(ctx context.Context, id int64) error
 }
 
 type Service struct {
 	IUserManagementService
 }
 
-func New(repo repository.IRepository) *Service {
+func New(repo repository.IRepository, email *validator.EmailValidator, blobs blob.Store) *Service {
 	return &Service{
-		IUserManagementService: user_management.NewService(repo),
+		IUserManagementService: user_management.NewService(repo, email, blobs),
 	}
 }
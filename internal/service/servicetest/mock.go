@@ -0,0 +1,177 @@
+// Package servicetest - test doubles of the service layer shared by handler tests.
+package servicetest
+
+import (
+	"context"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"github.com/sonikq/gravitum_test_task/internal/service"
+	"github.com/stretchr/testify/mock"
+	"io"
+)
+
+// MockService - mock of service.IUserManagementService, expectations are set on arguments without context.
+type MockService struct {
+	mock.Mock
+}
+
+var _ service.IUserManagementService = (*MockService)(nil)
+
+func (m *MockService) CreateUser(ctx context.Context, request models.UserInfo) (string, error) {
+	args := m.Called(request)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockService) GetUser(ctx context.Context, id int64) (*models.UserInfo, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserInfo), args.Error(1)
+}
+
+func (m *MockService) UpdateUser(ctx context.Context, request models.UserInfo) error {
+	return m.Called(request).Error(0)
+}
+
+func (m *MockService) DeleteUser(ctx context.Context, id int64) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockService) RestoreUser(ctx context.Context, id int64) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockService) GetUsers(ctx context.Context, ids []int64) (map[int64]*models.UserInfo, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]*models.UserInfo), args.Error(1)
+}
+
+func (m *MockService) ListUsers(ctx context.Context, filter models.UserFilter) ([]models.UserInfo, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.UserInfo), args.Error(1)
+}
+
+func (m *MockService) CheckUsername(ctx context.Context, username string) (models.UsernameAvailability, error) {
+	args := m.Called(username)
+	return args.Get(0).(models.UsernameAvailability), args.Error(1)
+}
+
+func (m *MockService) SearchUsers(ctx context.Context, search models.UserSearch) ([]models.UserSearchHit, error) {
+	args := m.Called(search)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.UserSearchHit), args.Error(1)
+}
+
+func (m *MockService) ListAttributeSchemas(ctx context.Context) ([]models.AttributeSchema, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AttributeSchema), args.Error(1)
+}
+
+func (m *MockService) PutAttributeSchema(ctx context.Context, schema models.AttributeSchema) (bool, error) {
+	args := m.Called(schema)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockService) DeleteAttributeSchema(ctx context.Context, name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func (m *MockService) CreateGroup(ctx context.Context, group models.Group) (*models.Group, error) {
+	args := m.Called(group)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Group), args.Error(1)
+}
+
+func (m *MockService) GetGroup(ctx context.Context, id int64) (*models.Group, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Group), args.Error(1)
+}
+
+func (m *MockService) ListGroups(ctx context.Context, page models.Page) ([]models.Group, error) {
+	args := m.Called(page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Group), args.Error(1)
+}
+
+func (m *MockService) UpdateGroup(ctx context.Context, group models.Group) error {
+	return m.Called(group).Error(0)
+}
+
+func (m *MockService) DeleteGroup(ctx context.Context, id int64) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockService) PutGroupMember(ctx context.Context, membership models.Membership) (bool, error) {
+	args := m.Called(membership)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockService) DeleteGroupMember(ctx context.Context, groupID, userID int64) error {
+	return m.Called(groupID, userID).Error(0)
+}
+
+func (m *MockService) ListGroupMembers(ctx context.Context, groupID int64, page models.Page) ([]models.GroupMember, error) {
+	args := m.Called(groupID, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.GroupMember), args.Error(1)
+}
+
+func (m *MockService) ListUserGroups(ctx context.Context, userID int64, page models.Page) ([]models.UserGroup, error) {
+	args := m.Called(userID, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.UserGroup), args.Error(1)
+}
+
+func (m *MockService) PutAvatar(ctx context.Context, userID int64, image []byte) (string, error) {
+	args := m.Called(userID, image)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockService) GetAvatar(ctx context.Context, userID int64, size models.AvatarSize) (io.ReadCloser, models.Avatar, error) {
+	args := m.Called(userID, size)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(models.Avatar), args.Error(2)
+	}
+	return args.Get(0).(io.ReadCloser), args.Get(1).(models.Avatar), args.Error(2)
+}
+
+func (m *MockService) DeleteAvatar(ctx context.Context, userID int64) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockService) ExportUser(ctx context.Context, id int64) (*models.UserExport, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserExport), args.Error(1)
+}
+
+func (m *MockService) EraseUser(ctx context.Context, id int64) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
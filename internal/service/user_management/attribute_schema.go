@@ -0,0 +1,25 @@
+package user_management
+
+import (
+	"context"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+)
+
+// ListAttributeSchemas - getting all registered attribute schemas.
+func (s *Service) ListAttributeSchemas(ctx context.Context) ([]models.AttributeSchema, error) {
+	return s.repository.ListAttributeSchemas(ctx)
+}
+
+// PutAttributeSchema - validating and registering attribute schema, the one with the same name is replaced.
+// Values users already have are checked against new schema when they are changed.
+func (s *Service) PutAttributeSchema(ctx context.Context, schema models.AttributeSchema) (bool, error) {
+	if err := schema.Validate(); err != nil {
+		return false, err
+	}
+	return s.repository.PutAttributeSchema(ctx, schema)
+}
+
+// DeleteAttributeSchema - deleting attribute schema by name, schema of attribute some user has is kept.
+func (s *Service) DeleteAttributeSchema(ctx context.Context, name string) error {
+	return s.repository.DeleteAttributeSchema(ctx, name)
+}
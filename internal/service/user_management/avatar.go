@@ -0,0 +1,148 @@
+package user_management
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"github.com/sonikq/gravitum_test_task/pkg/blob"
+	"github.com/sonikq/gravitum_test_task/pkg/tenant"
+	"github.com/sonikq/gravitum_test_task/pkg/thumbnail"
+	"io"
+)
+
+// avatarEdges - edge in pixels of square thumbnail of every avatar size.
+var avatarEdges = map[models.AvatarSize]int{
+	models.AvatarSmall:  64,
+	models.AvatarMedium: 128,
+	models.AvatarLarge:  256,
+}
+
+// PutAvatar - storing thumbnails of image as avatar of active user, new version of avatar is returned.
+// Type and pixel count of image are checked by caller, see thumbnail.Check.
+// Images of every upload are stored under a new version, so readers never get a mix of old and new ones,
+// images of the previous version are removed after the user is switched to the new one.
+func (s *Service) PutAvatar(ctx context.Context, userID int64, image []byte) (string, error) {
+	if _, err := s.GetUser(ctx, userID); err != nil {
+		return "", err
+	}
+
+	edges := make([]int, 0, len(avatarEdges))
+	for _, edge := range avatarEdges {
+		edges = append(edges, edge)
+	}
+	thumbnails, err := thumbnail.Make(image, edges...)
+	if err != nil {
+		if errors.Is(err, thumbnail.ErrInvalidImage) {
+			return "", fmt.Errorf("%w: %v", models.ErrInvalidAvatar, err)
+		}
+		return "", err
+	}
+
+	version, err := newAvatarVersion()
+	if err != nil {
+		return "", err
+	}
+
+	for _, size := range models.AvatarSizes {
+		data := thumbnails[avatarEdges[size]]
+		if err = s.blobs.Put(ctx, avatarKey(ctx, userID, version, size), bytes.NewReader(data), thumbnail.ContentType); err != nil {
+			s.removeAvatar(ctx, userID, version)
+			return "", err
+		}
+	}
+
+	previous, err := s.repository.SetAvatarVersion(ctx, userID, version)
+	if err != nil {
+		s.removeAvatar(ctx, userID, version)
+		return "", err
+	}
+	s.removeAvatar(ctx, userID, previous)
+
+	return version, nil
+}
+
+// GetAvatar - image of avatar of active user in size, reader must be closed.
+func (s *Service) GetAvatar(ctx context.Context, userID int64, size models.AvatarSize) (io.ReadCloser, models.Avatar, error) {
+	if !size.Valid() {
+		return nil, models.Avatar{}, models.ErrInvalidAvatarSize
+	}
+
+	userInfo, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, models.Avatar{}, err
+	}
+	if userInfo.AvatarVersion == "" {
+		return nil, models.Avatar{}, models.ErrAvatarDoesNotExist
+	}
+
+	r, info, err := s.blobs.Get(ctx, avatarKey(ctx, userID, userInfo.AvatarVersion, size))
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			// avatar was replaced or deleted after the user was read
+			return nil, models.Avatar{}, models.ErrAvatarDoesNotExist
+		}
+		return nil, models.Avatar{}, err
+	}
+
+	return r, models.Avatar{
+		Version:     userInfo.AvatarVersion,
+		Size:        size,
+		ContentType: info.ContentType,
+		Length:      info.Size,
+		ModTime:     info.ModTime,
+	}, nil
+}
+
+// DeleteAvatar - removing avatar of active user.
+func (s *Service) DeleteAvatar(ctx context.Context, userID int64) error {
+	userInfo, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if userInfo.AvatarVersion == "" {
+		return models.ErrAvatarDoesNotExist
+	}
+
+	previous, err := s.repository.SetAvatarVersion(ctx, userID, "")
+	if err != nil {
+		return err
+	}
+	if previous == "" {
+		// deleted concurrently
+		return models.ErrAvatarDoesNotExist
+	}
+	s.removeAvatar(ctx, userID, previous)
+
+	return nil
+}
+
+// removeAvatar - removing images of avatar version, failures leave unreachable images in storage
+// and are not reported, since the user is consistent anyway.
+func (s *Service) removeAvatar(ctx context.Context, userID int64, version string) {
+	if version == "" {
+		return
+	}
+	// images are removed even if request is canceled meanwhile
+	ctx = context.WithoutCancel(ctx)
+	for _, size := range models.AvatarSizes {
+		_ = s.blobs.Delete(ctx, avatarKey(ctx, userID, version, size))
+	}
+}
+
+// avatarKey - key of avatar image in blob storage, tenant is taken from ctx.
+func avatarKey(ctx context.Context, userID int64, version string, size models.AvatarSize) string {
+	return fmt.Sprintf("avatars/%s/%d/%s/%s.png", tenant.FromContext(ctx), userID, version, size)
+}
+
+// newAvatarVersion - random version of avatar.
+func newAvatarVersion() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,92 @@
+package user_management
+
+import (
+	"context"
+	"errors"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+	"github.com/sonikq/gravitum_test_task/pkg/blob"
+	"github.com/sonikq/gravitum_test_task/pkg/tenant"
+	"io"
+	"time"
+)
+
+// ExportUser - everything stored about user for data subject access request, deleted user included.
+// Personal data of erased user is gone, so it is ErrUserIsErased.
+func (s *Service) ExportUser(ctx context.Context, id int64) (*models.UserExport, error) {
+	userInfo, err := s.repository.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if userInfo.ErasedAt != nil {
+		return nil, models.ErrUserIsErased
+	}
+
+	// memberships of deleted user are hidden from listings but still stored
+	groups, err := s.repository.ListUserGroups(ctx, id, models.Page{})
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.repository.ListUserEvents(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	avatar := make(map[models.AvatarSize][]byte)
+	if userInfo.AvatarVersion != "" {
+		for _, size := range models.AvatarSizes {
+			data, err := s.readBlob(ctx, avatarKey(ctx, id, userInfo.AvatarVersion, size))
+			if err != nil {
+				if errors.Is(err, blob.ErrNotFound) {
+					// avatar was replaced or deleted after the user was read
+					continue
+				}
+				return nil, err
+			}
+			avatar[size] = data
+		}
+	}
+
+	return &models.UserExport{
+		Tenant:     tenant.FromContext(ctx),
+		User:       *userInfo,
+		History:    history,
+		Groups:     groups,
+		Avatar:     avatar,
+		ExportedAt: time.Now().UTC(),
+	}, nil
+}
+
+// EraseUser - erasing personal data of active or deleted user, unlike DeleteUser it can not be undone.
+// Names, email, gender, age and attributes are cleared, username is replaced with "erased-<id>",
+// so the original one is free, memberships and avatar images are removed and tombstone is recorded.
+// The row of the user is kept deleted, so its id is never reused and it can not be restored.
+func (s *Service) EraseUser(ctx context.Context, id int64) error {
+	userInfo, err := s.repository.GetUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if userInfo.ErasedAt != nil {
+		return models.ErrUserIsErased
+	}
+
+	avatarVersion, err := s.repository.EraseUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	s.removeAvatar(ctx, id, avatarVersion)
+
+	return nil
+}
+
+// readBlob - whole object from blob storage.
+func (s *Service) readBlob(ctx context.Context, key string) ([]byte, error) {
+	r, _, err := s.blobs.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+	return io.ReadAll(r)
+}
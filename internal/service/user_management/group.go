@@ -0,0 +1,80 @@
+package user_management
+
+import (
+	"context"
+	"github.com/sonikq/gravitum_test_task/internal/models"
+)
+
+// CreateGroup - validating and creating group, name is unique case-insensitively.
+func (s *Service) CreateGroup(ctx context.Context, group models.Group) (*models.Group, error) {
+	group.Normalize()
+	if err := group.Validate(); err != nil {
+		return nil, err
+	}
+	return s.repository.CreateGroup(ctx, group)
+}
+
+// GetGroup - getting group by id.
+func (s *Service) GetGroup(ctx context.Context, id int64) (*models.Group, error) {
+	return s.repository.GetGroup(ctx, id)
+}
+
+// ListGroups - getting page of groups ordered by name.
+func (s *Service) ListGroups(ctx context.Context, page models.Page) ([]models.Group, error) {
+	if err := page.Validate(); err != nil {
+		return nil, err
+	}
+	return s.repository.ListGroups(ctx, page)
+}
+
+// UpdateGroup - validating and replacing name and description of group.
+func (s *Service) UpdateGroup(ctx context.Context, group models.Group) error {
+	group.Normalize()
+	if err := group.Validate(); err != nil {
+		return err
+	}
+	return s.repository.UpdateGroup(ctx, group)
+}
+
+// DeleteGroup - deleting group with its memberships, users are kept.
+func (s *Service) DeleteGroup(ctx context.Context, id int64) error {
+	return s.repository.DeleteGroup(ctx, id)
+}
+
+// PutGroupMember - adding active user to group with role, member by default, or changing role of member.
+func (s *Service) PutGroupMember(ctx context.Context, membership models.Membership) (bool, error) {
+	if membership.Role == "" {
+		membership.Role = models.GroupRoleMember
+	}
+	if err := membership.Validate(); err != nil {
+		return false, err
+	}
+	return s.repository.PutGroupMember(ctx, membership)
+}
+
+// DeleteGroupMember - removing user from group.
+func (s *Service) DeleteGroupMember(ctx context.Context, groupID, userID int64) error {
+	return s.repository.DeleteGroupMember(ctx, groupID, userID)
+}
+
+// ListGroupMembers - getting page of members of group, deleted users are hidden until they are restored.
+func (s *Service) ListGroupMembers(ctx context.Context, groupID int64, page models.Page) ([]models.GroupMember, error) {
+	if err := page.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := s.repository.GetGroup(ctx, groupID); err != nil {
+		return nil, err
+	}
+	return s.repository.ListGroupMembers(ctx, groupID, page)
+}
+
+// ListUserGroups - getting page of groups of active user, deleted one is gone with its memberships.
+func (s *Service) ListUserGroups(ctx context.Context, userID int64, page models.Page) ([]models.UserGroup, error) {
+	if err := page.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := s.GetUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.repository.ListUserGroups(ctx, userID, page)
+}
@@ -1,13 +1,26 @@
 package user_management
 
-import "github.com/sonikq/gravitum_test_task/internal/repository"
+import (
+	"github.com/sonikq/gravitum_test_task/internal/repository"
+	"github.com/sonikq/gravitum_test_task/pkg/attributes"
+	"github.com/sonikq/gravitum_test_task/pkg/blob"
+	"github.com/sonikq/gravitum_test_task/pkg/validator"
+)
 
 type Service struct {
 	repository repository.IRepository
+	email      *validator.EmailValidator
+	attributes *attributes.Validator
+	blobs      blob.Store
 }
 
-func NewService(repo repository.IRepository) *Service {
+// NewService - creating service, domains of new emails are checked by email validator,
+// avatar images are kept in blobs.
+func NewService(repo repository.IRepository, email *validator.EmailValidator, blobs blob.Store) *Service {
 	return &Service{
 		repository: repo,
+		email:      email,
+		attributes: attributes.NewValidator(),
+		blobs:      blobs,
 	}
 }
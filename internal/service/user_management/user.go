@@ -2,12 +2,22 @@ package user_management
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"github.com/sonikq/gravitum_test_task/internal/models"
+	"github.com/sonikq/gravitum_test_task/pkg/highlight"
+	"reflect"
+	"strconv"
 )
 
-// CreateUser - validating request body and creating user in DB.
+// CreateUser - normalizing and validating request body and creating user in DB.
 func (s *Service) CreateUser(ctx context.Context, request models.UserInfo) (string, error) {
-	if err := request.Validate(); err != nil {
+	request.Normalize()
+	if err := request.Validate(ctx, s.email); err != nil {
+		return "", err
+	}
+
+	if err := s.validateAttributes(ctx, request.Attributes); err != nil {
 		return "", err
 	}
 
@@ -18,6 +28,39 @@ func (s *Service) CreateUser(ctx context.Context, request models.UserInfo) (stri
 	return id, nil
 }
 
+// validateAttributes - checking attributes against registered schemas.
+func (s *Service) validateAttributes(ctx context.Context, attrs map[string]any) error {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	registered, err := s.repository.ListAttributeSchemas(ctx)
+	if err != nil {
+		return err
+	}
+	schemas := make(map[string]json.RawMessage, len(registered))
+	for _, schema := range registered {
+		schemas[schema.Name] = schema.Schema
+	}
+
+	if err = s.attributes.Validate(schemas, attrs); err != nil {
+		return fmt.Errorf("%w: %w", models.ErrInvalidAttributes, err)
+	}
+	return nil
+}
+
+// changedAttributes - attributes which are new or have other values than current ones,
+// kept values are not checked again, their schema could change since.
+func changedAttributes(current, attrs map[string]any) map[string]any {
+	changed := make(map[string]any, len(attrs))
+	for name, value := range attrs {
+		if currentValue, ok := current[name]; !ok || !reflect.DeepEqual(currentValue, value) {
+			changed[name] = value
+		}
+	}
+	return changed
+}
+
 // GetUser - getting info about user by id.
 func (s *Service) GetUser(ctx context.Context, id int64) (*models.UserInfo, error) {
 	userInfo, err := s.repository.GetUser(ctx, id)
@@ -25,6 +68,9 @@ func (s *Service) GetUser(ctx context.Context, id int64) (*models.UserInfo, erro
 		return nil, err
 	}
 
+	if userInfo.ErasedAt != nil {
+		return nil, models.ErrUserIsErased
+	}
 	if userInfo.EndDate != nil {
 		return nil, models.ErrUserIsGone
 	}
@@ -32,21 +78,28 @@ func (s *Service) GetUser(ctx context.Context, id int64) (*models.UserInfo, erro
 	return userInfo, nil
 }
 
-// UpdateUser - updating user info by id.
+// UpdateUser - updating user info by id, username and email are normalized.
 func (s *Service) UpdateUser(ctx context.Context, request models.UserInfo) error {
-	_, err := s.GetUser(ctx, request.ID)
+	current, err := s.GetUser(ctx, request.ID)
 	if err != nil {
 		return err
 	}
 
-	if err = request.Validate(); err != nil {
+	request.Normalize()
+	if err = request.ValidateUpdate(ctx, s.email, current); err != nil {
+		return err
+	}
+
+	if request.Attributes == nil {
+		request.Attributes = current.Attributes
+	} else if err = s.validateAttributes(ctx, changedAttributes(current.Attributes, request.Attributes)); err != nil {
 		return err
 	}
 
 	return s.repository.UpdateUser(ctx, request, request.ID)
 }
 
-// DeleteUser - deleting user by id.
+// DeleteUser - deleting user by id, memberships of deleted user in groups are hidden until it is restored.
 func (s *Service) DeleteUser(ctx context.Context, id int64) error {
 	userInfo, err := s.repository.GetUser(ctx, id)
 	if err != nil {
@@ -59,3 +112,114 @@ func (s *Service) DeleteUser(ctx context.Context, id int64) error {
 
 	return s.repository.DeleteUser(ctx, id)
 }
+
+// RestoreUser - restoring deleted user by id.
+func (s *Service) RestoreUser(ctx context.Context, id int64) error {
+	userInfo, err := s.repository.GetUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if userInfo.EndDate == nil {
+		return models.ErrRestoreActiveUser
+	}
+	if userInfo.ErasedAt != nil {
+		return models.ErrUserIsErased
+	}
+
+	return s.repository.RestoreUser(ctx, id)
+}
+
+// GetUsers - getting users by ids at once, missing ids are absent in the result and deleted users have EndDate.
+func (s *Service) GetUsers(ctx context.Context, ids []int64) (map[int64]*models.UserInfo, error) {
+	if len(ids) == 0 {
+		return map[int64]*models.UserInfo{}, nil
+	}
+	return s.repository.GetUsers(ctx, ids)
+}
+
+// ListUsers - getting active users matching filter.
+func (s *Service) ListUsers(ctx context.Context, filter models.UserFilter) ([]models.UserInfo, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return s.repository.ListUsers(ctx, filter)
+}
+
+const (
+	// usernameSuggestions - number of alternatives proposed for a taken username.
+	usernameSuggestions = 5
+	// usernameCandidates - number of alternatives checked at once, with margin for taken ones.
+	usernameCandidates = 20
+)
+
+// CheckUsername - checking whether normalized username is free among active users, usernames of deleted users are free.
+// Taken username gets available alternatives with numeric suffixes.
+func (s *Service) CheckUsername(ctx context.Context, username string) (models.UsernameAvailability, error) {
+	availability := models.UsernameAvailability{Username: models.NormalizeUsername(username), Suggestions: []string{}}
+	if !models.ValidUsername(availability.Username) {
+		return availability, models.ErrInvalidUsername
+	}
+
+	candidates := make([]string, 0, usernameCandidates+1)
+	candidates = append(candidates, availability.Username)
+	for i := 1; i <= usernameCandidates; i++ {
+		candidates = append(candidates, availability.Username+strconv.Itoa(i))
+	}
+
+	taken, err := s.repository.TakenUsernames(ctx, candidates)
+	if err != nil {
+		return availability, err
+	}
+
+	availability.Available = !taken[availability.Username]
+	if availability.Available {
+		return availability, nil
+	}
+	for _, candidate := range candidates[1:] {
+		if len(availability.Suggestions) == usernameSuggestions {
+			break
+		}
+		if !taken[candidate] {
+			availability.Suggestions = append(availability.Suggestions, candidate)
+		}
+	}
+	return availability, nil
+}
+
+// SearchUsers - searching active users by names, username and email, occurrences of terms are highlighted.
+func (s *Service) SearchUsers(ctx context.Context, search models.UserSearch) ([]models.UserSearchHit, error) {
+	if err := search.Validate(); err != nil {
+		return nil, err
+	}
+
+	hits, err := s.repository.SearchUsers(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := search.Terms()
+	for i := range hits {
+		hits[i].Highlights = highlights(&hits[i].User, terms)
+	}
+	return hits, nil
+}
+
+// highlights - searched fields of user with occurrences of terms, fuzzy matches without them are not highlighted.
+func highlights(user *models.UserInfo, terms []string) map[models.SearchField]string {
+	fields := map[models.SearchField]string{
+		models.SearchFieldUsername:   user.Username,
+		models.SearchFieldFirstName:  user.FirstName,
+		models.SearchFieldMiddleName: user.MiddleName,
+		models.SearchFieldLastName:   user.LastName,
+		models.SearchFieldEmail:      user.Email,
+	}
+
+	result := make(map[models.SearchField]string, len(fields))
+	for field, value := range fields {
+		if marked, ok := highlight.Mark(value, terms, "<mark>", "</mark>"); ok {
+			result[field] = marked
+		}
+	}
+	return result
+}
@@ -1,12 +1,21 @@
 package user_management
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"image"
+	"image/png"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/sonikq/gravitum_test_task/internal/models"
+	"github.com/sonikq/gravitum_test_task/pkg/blob"
+	"github.com/sonikq/gravitum_test_task/pkg/emailpolicy"
+	"github.com/sonikq/gravitum_test_task/pkg/tenant"
+	"github.com/sonikq/gravitum_test_task/pkg/validator"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -21,6 +30,14 @@ func (m *MockRepository) Close() {
 	return
 }
 
+func (m *MockRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (m *MockRepository) CheckMigrations(ctx context.Context) error {
+	return nil
+}
+
 func (m *MockRepository) CreateUser(ctx context.Context, user models.UserInfo) (string, error) {
 	args := m.Called(ctx, user)
 	return args.String(0), args.Error(1)
@@ -44,10 +61,141 @@ func (m *MockRepository) DeleteUser(ctx context.Context, id int64) error {
 	return args.Error(0)
 }
 
+func (m *MockRepository) RestoreUser(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRepository) GetUsers(ctx context.Context, ids []int64) (map[int64]*models.UserInfo, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]*models.UserInfo), args.Error(1)
+}
+
+func (m *MockRepository) ListUsers(ctx context.Context, filter models.UserFilter) ([]models.UserInfo, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.UserInfo), args.Error(1)
+}
+
+func (m *MockRepository) TakenUsernames(ctx context.Context, usernames []string) (map[string]bool, error) {
+	args := m.Called(ctx, usernames)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
+func (m *MockRepository) SearchUsers(ctx context.Context, search models.UserSearch) ([]models.UserSearchHit, error) {
+	args := m.Called(ctx, search)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.UserSearchHit), args.Error(1)
+}
+
+func (m *MockRepository) ListAttributeSchemas(ctx context.Context) ([]models.AttributeSchema, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AttributeSchema), args.Error(1)
+}
+
+func (m *MockRepository) PutAttributeSchema(ctx context.Context, schema models.AttributeSchema) (bool, error) {
+	args := m.Called(ctx, schema)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) DeleteAttributeSchema(ctx context.Context, name string) error {
+	args := m.Called(ctx, name)
+	return args.Error(0)
+}
+
+func (m *MockRepository) CreateGroup(ctx context.Context, group models.Group) (*models.Group, error) {
+	args := m.Called(ctx, group)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Group), args.Error(1)
+}
+
+func (m *MockRepository) GetGroup(ctx context.Context, id int64) (*models.Group, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Group), args.Error(1)
+}
+
+func (m *MockRepository) ListGroups(ctx context.Context, page models.Page) ([]models.Group, error) {
+	args := m.Called(ctx, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Group), args.Error(1)
+}
+
+func (m *MockRepository) UpdateGroup(ctx context.Context, group models.Group) error {
+	return m.Called(ctx, group).Error(0)
+}
+
+func (m *MockRepository) DeleteGroup(ctx context.Context, id int64) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockRepository) PutGroupMember(ctx context.Context, membership models.Membership) (bool, error) {
+	args := m.Called(ctx, membership)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) DeleteGroupMember(ctx context.Context, groupID, userID int64) error {
+	return m.Called(ctx, groupID, userID).Error(0)
+}
+
+func (m *MockRepository) ListGroupMembers(ctx context.Context, groupID int64, page models.Page) ([]models.GroupMember, error) {
+	args := m.Called(ctx, groupID, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.GroupMember), args.Error(1)
+}
+
+func (m *MockRepository) ListUserGroups(ctx context.Context, userID int64, page models.Page) ([]models.UserGroup, error) {
+	args := m.Called(ctx, userID, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.UserGroup), args.Error(1)
+}
+
+func (m *MockRepository) SetAvatarVersion(ctx context.Context, id int64, version string) (string, error) {
+	args := m.Called(ctx, id, version)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockRepository) EraseUser(ctx context.Context, id int64) (string, error) {
+	args := m.Called(ctx, id)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockRepository) ListUserEvents(ctx context.Context, userID int64) ([]models.UserEvent, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.UserEvent), args.Error(1)
+}
+
 // Helper function to create a valid user for testing
 func createValidUser() models.UserInfo {
 	return models.UserInfo{
 		ID:        1,
+		Username:  "user1",
 		FirstName: "John",
 		LastName:  "Doe",
 		Email:     "john.doe@example.com",
@@ -111,10 +259,102 @@ func TestCreateUser(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("Success - Username and email are normalized", func(t *testing.T) {
+		// Arrange
+		user := createValidUser()
+		user.Username, user.Email = " Ａlice ", "John.Doe@Example.COM"
+		normalized := createValidUser()
+		normalized.Username = "alice"
+		mockRepo.On("CreateUser", ctx, normalized).Return("user789", nil).Once()
+
+		// Act
+		id, err := service.CreateUser(ctx, user)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "user789", id)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Blank username", func(t *testing.T) {
+		// Arrange
+		user := createValidUser()
+		user.Username = "   "
+
+		// Act
+		_, err := service.CreateUser(ctx, user)
+
+		// Assert
+		assert.ErrorIs(t, err, models.ErrInvalidUsername)
+	})
+
+	t.Run("Failure - Invalid name", func(t *testing.T) {
+		// Arrange
+		user := createValidUser()
+		user.FirstName = "J0hn"
+
+		// Act
+		_, err := service.CreateUser(ctx, user)
+
+		// Assert
+		assert.ErrorIs(t, err, models.ErrInvalidName)
+	})
+
+	t.Run("Failure - Rejected email domain", func(t *testing.T) {
+		// Arrange
+		denyList, err := validator.NewDenyList("example.com")
+		require.NoError(t, err)
+		strict := &Service{repository: mockRepo, email: validator.NewEmailValidator(denyList)}
+
+		// Act
+		_, err = strict.CreateUser(ctx, createValidUser())
+
+		// Assert
+		assert.ErrorIs(t, err, models.ErrInvalidEmail)
+		assert.ErrorIs(t, err, validator.ErrEmailDomainRejected)
+	})
+
+	t.Run("Failure - Email domain is not allowed by policy", func(t *testing.T) {
+		// Arrange
+		policy := emailpolicy.New(nil)
+		require.NoError(t, policy.SetRules(emailpolicy.Rules{Deny: []string{"*.com"}}))
+		strict := &Service{repository: mockRepo, email: validator.NewEmailValidator(policy)}
+
+		// Act
+		_, err := strict.CreateUser(ctx, createValidUser())
+
+		// Assert
+		assert.ErrorIs(t, err, models.ErrEmailDomainNotAllowed)
+		assert.NotErrorIs(t, err, models.ErrInvalidEmail)
+	})
+
+	t.Run("Failure - Email domain is not in allow-list of tenant", func(t *testing.T) {
+		// Arrange
+		policy := emailpolicy.New(tenant.FromContext)
+		require.NoError(t, policy.SetRules(emailpolicy.Rules{Allow: map[string][]string{"acme": {"acme.example"}}}))
+		strict := &Service{repository: mockRepo, email: validator.NewEmailValidator(policy)}
+		acme := tenant.WithID(ctx, "acme")
+
+		// Act
+		_, err := strict.CreateUser(acme, createValidUser())
+
+		// Assert
+		assert.ErrorIs(t, err, models.ErrEmailDomainNotAllowed)
+
+		// email of allowed domain is accepted
+		user := createValidUser()
+		user.Email = "john.doe@acme.example"
+		mockRepo.On("CreateUser", acme, user).Return("1", nil).Once()
+		_, err = strict.CreateUser(acme, user)
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("Edge case - Empty but valid user", func(t *testing.T) {
 		// This test depends on what Validate() considers valid
 		// For this example, we'll assume minimal valid data
 		minimalUser := models.UserInfo{
+			Username:  "jane",
 			FirstName: "Jane",
 			LastName:  "Doe",
 			Email:     "jane.doe@example.com",
@@ -247,6 +487,54 @@ func TestUpdateUser(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("Success - Kept email is not checked again", func(t *testing.T) {
+		// Arrange
+		denyList, err := validator.NewDenyList("example.com")
+		require.NoError(t, err)
+		strict := &Service{repository: mockRepo, email: validator.NewEmailValidator(denyList)}
+		user := createValidUser()
+		mockRepo.On("GetUser", ctx, user.ID).Return(&user, nil).Once()
+		mockRepo.On("UpdateUser", ctx, user, user.ID).Return(nil).Once()
+
+		// Act
+		err = strict.UpdateUser(ctx, user)
+
+		// Assert
+		require.NoError(t, err)
+
+		// changed email is checked
+		changed := user
+		changed.Email = "john@mail.example.com"
+		mockRepo.On("GetUser", ctx, user.ID).Return(&user, nil).Once()
+		assert.ErrorIs(t, strict.UpdateUser(ctx, changed), models.ErrInvalidEmail)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success - Kept names are not checked again", func(t *testing.T) {
+		// Arrange
+		stored := createValidUser()
+		stored.LastName = "Doe Jr."
+		stored.MiddleName = "2nd"
+		mockRepo.On("GetUser", ctx, stored.ID).Return(&stored, nil).Once()
+
+		updated := stored
+		updated.Age = 31
+		mockRepo.On("UpdateUser", ctx, updated, updated.ID).Return(nil).Once()
+
+		// Act
+		err := service.UpdateUser(ctx, updated)
+
+		// Assert
+		require.NoError(t, err)
+
+		// changed name is checked
+		renamed := stored
+		renamed.FirstName = "J0hn"
+		mockRepo.On("GetUser", ctx, stored.ID).Return(&stored, nil).Once()
+		assert.ErrorIs(t, service.UpdateUser(ctx, renamed), models.ErrInvalidName)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("Failure - User not found", func(t *testing.T) {
 		// Arrange
 		user := createValidUser()
@@ -423,6 +711,528 @@ func TestDeleteUser(t *testing.T) {
 	})
 }
 
+// TestListUsers tests the ListUsers method
+func TestListUsers(t *testing.T) {
+	// Setup
+	mockRepo := new(MockRepository)
+	service := &Service{repository: mockRepo}
+	ctx := context.Background()
+
+	t.Run("Success - Page of users", func(t *testing.T) {
+		// Arrange
+		users := []models.UserInfo{createValidUser()}
+		filter := models.UserFilter{Limit: 10, Gender: "M"}
+		mockRepo.On("ListUsers", ctx, filter).Return(users, nil).Once()
+
+		// Act
+		result, err := service.ListUsers(ctx, filter)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, users, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Repository error", func(t *testing.T) {
+		// Arrange
+		expectedError := errors.New("database error")
+		filter := models.UserFilter{AfterID: 5, Limit: 10}
+		mockRepo.On("ListUsers", ctx, filter).Return(nil, expectedError).Once()
+
+		// Act
+		result, err := service.ListUsers(ctx, filter)
+
+		// Assert
+		require.Error(t, err)
+		assert.Nil(t, result)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+// TestListUsers_InvalidFilter tests that invalid filters do not reach repository
+func TestListUsers_InvalidFilter(t *testing.T) {
+	// Setup
+	mockRepo := new(MockRepository)
+	service := &Service{repository: mockRepo}
+	ctx := context.Background()
+
+	_, err := service.ListUsers(ctx, models.UserFilter{Gender: "X"})
+	assert.ErrorIs(t, err, models.ErrInvalidGender)
+
+	_, err = service.ListUsers(ctx, models.UserFilter{MinAge: 30, MaxAge: 20})
+	assert.ErrorIs(t, err, models.ErrInvalidAge)
+
+	_, err = service.ListUsers(ctx, models.UserFilter{Attributes: map[string]any{"Bad-Name": "x"}})
+	assert.ErrorIs(t, err, models.ErrInvalidAttributes)
+
+	mockRepo.AssertNotCalled(t, "ListUsers")
+}
+
+// TestSearchUsers tests the SearchUsers method
+func TestSearchUsers(t *testing.T) {
+	// Setup
+	mockRepo := new(MockRepository)
+	service := &Service{repository: mockRepo}
+	ctx := context.Background()
+
+	t.Run("Success - Matches are highlighted", func(t *testing.T) {
+		// Arrange
+		search := models.UserSearch{Query: "Doe, jo", Limit: 10}
+		hits := []models.UserSearchHit{{User: createValidUser(), Rank: 0.5}}
+		mockRepo.On("SearchUsers", ctx, search).Return(hits, nil).Once()
+
+		// Act
+		result, err := service.SearchUsers(ctx, search)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, map[models.SearchField]string{
+			models.SearchFieldFirstName: "<mark>Jo</mark>hn",
+			models.SearchFieldLastName:  "<mark>Doe</mark>",
+			models.SearchFieldEmail:     "<mark>jo</mark>hn.<mark>doe</mark>@example.com",
+		}, result[0].Highlights)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Invalid query", func(t *testing.T) {
+		// Act
+		_, err := service.SearchUsers(ctx, models.UserSearch{Query: " .,- "})
+
+		// Assert
+		assert.ErrorIs(t, err, models.ErrInvalidSearchQuery)
+		mockRepo.AssertNotCalled(t, "SearchUsers", ctx, models.UserSearch{Query: " .,- "})
+	})
+}
+
+// TestCheckUsername tests the CheckUsername method
+func TestCheckUsername(t *testing.T) {
+	// Setup
+	mockRepo := new(MockRepository)
+	service := &Service{repository: mockRepo}
+	ctx := context.Background()
+
+	candidates := []string{"alice"}
+	for i := 1; i <= usernameCandidates; i++ {
+		candidates = append(candidates, "alice"+strconv.Itoa(i))
+	}
+
+	t.Run("Success - Free username", func(t *testing.T) {
+		// Arrange
+		mockRepo.On("TakenUsernames", ctx, candidates).Return(map[string]bool{"alice1": true}, nil).Once()
+
+		// Act
+		availability, err := service.CheckUsername(ctx, " Alice ")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, models.UsernameAvailability{Username: "alice", Available: true, Suggestions: []string{}}, availability)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success - Taken username gets free alternatives", func(t *testing.T) {
+		// Arrange
+		taken := map[string]bool{"alice": true, "alice1": true, "alice3": true}
+		mockRepo.On("TakenUsernames", ctx, candidates).Return(taken, nil).Once()
+
+		// Act
+		availability, err := service.CheckUsername(ctx, "ALICE")
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, availability.Available)
+		assert.Equal(t, []string{"alice2", "alice4", "alice5", "alice6", "alice7"}, availability.Suggestions)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Empty username", func(t *testing.T) {
+		// Act
+		_, err := service.CheckUsername(ctx, "   ")
+
+		// Assert
+		assert.ErrorIs(t, err, models.ErrInvalidUsername)
+	})
+}
+
+// TestUserAttributes tests validation of attributes on create and update
+func TestUserAttributes(t *testing.T) {
+	// Setup
+	mockRepo := new(MockRepository)
+	service := &Service{repository: mockRepo}
+	ctx := context.Background()
+	schemas := []models.AttributeSchema{
+		{Name: "locale", Schema: []byte(`{"enum": ["en", "ru"]}`)},
+		{Name: "floor", Schema: []byte(`{"type": "integer"}`)},
+	}
+
+	t.Run("Success - Registered attributes are created", func(t *testing.T) {
+		// Arrange
+		user := createValidUser()
+		user.Attributes = map[string]any{"locale": "ru", "floor": 3}
+		mockRepo.On("ListAttributeSchemas", ctx).Return(schemas, nil).Once()
+		mockRepo.On("CreateUser", ctx, user).Return("1", nil).Once()
+
+		// Act
+		_, err := service.CreateUser(ctx, user)
+
+		// Assert
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Unknown and invalid attributes", func(t *testing.T) {
+		// Arrange
+		user := createValidUser()
+		mockRepo.On("ListAttributeSchemas", ctx).Return(schemas, nil).Twice()
+
+		// Act
+		user.Attributes = map[string]any{"nickname": "jd"}
+		_, unknownErr := service.CreateUser(ctx, user)
+		user.Attributes = map[string]any{"locale": "de"}
+		_, invalidErr := service.CreateUser(ctx, user)
+
+		// Assert
+		assert.ErrorIs(t, unknownErr, models.ErrInvalidAttributes)
+		assert.ErrorContains(t, unknownErr, "unknown attribute nickname")
+		assert.ErrorIs(t, invalidErr, models.ErrInvalidAttributes)
+		mockRepo.AssertNotCalled(t, "CreateUser", ctx, user)
+	})
+
+	t.Run("Success - Missing attributes in update keep current ones", func(t *testing.T) {
+		// Arrange
+		current := createValidUser()
+		current.Attributes = map[string]any{"locale": "ru"}
+		request := current
+		request.Attributes = nil
+		mockRepo.On("GetUser", ctx, current.ID).Return(&current, nil).Once()
+		mockRepo.On("UpdateUser", ctx, current, current.ID).Return(nil).Once()
+
+		// Act
+		err := service.UpdateUser(ctx, request)
+
+		// Assert
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success - Kept values are not checked again", func(t *testing.T) {
+		// Arrange
+		current := createValidUser()
+		// value stored before schema of locale was narrowed
+		current.Attributes = map[string]any{"locale": "de"}
+		request := current
+		request.Attributes = map[string]any{"locale": "de", "floor": 2}
+		mockRepo.On("GetUser", ctx, current.ID).Return(&current, nil).Once()
+		mockRepo.On("ListAttributeSchemas", ctx).Return(schemas, nil).Once()
+		mockRepo.On("UpdateUser", ctx, request, request.ID).Return(nil).Once()
+
+		// Act
+		err := service.UpdateUser(ctx, request)
+
+		// Assert
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+// TestPutAttributeSchema tests validation of attribute schemas
+func TestPutAttributeSchema(t *testing.T) {
+	// Setup
+	mockRepo := new(MockRepository)
+	service := &Service{repository: mockRepo}
+	ctx := context.Background()
+
+	schema := models.AttributeSchema{Name: "locale", Schema: []byte(`{"enum": ["en", "ru"]}`)}
+	mockRepo.On("PutAttributeSchema", ctx, schema).Return(true, nil).Once()
+	created, err := service.PutAttributeSchema(ctx, schema)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	_, err = service.PutAttributeSchema(ctx, models.AttributeSchema{Name: "Locale", Schema: schema.Schema})
+	assert.ErrorIs(t, err, models.ErrInvalidAttributeSchema)
+
+	_, err = service.PutAttributeSchema(ctx, models.AttributeSchema{Name: "locale", Schema: []byte(`{"type": "text"}`)})
+	assert.ErrorIs(t, err, models.ErrInvalidAttributeSchema)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGroups tests validation of groups and memberships and groups of deleted users
+func TestGroups(t *testing.T) {
+	// Setup
+	mockRepo := new(MockRepository)
+	service := &Service{repository: mockRepo}
+	ctx := context.Background()
+
+	t.Run("Success - Group is created with trimmed name", func(t *testing.T) {
+		group := models.Group{Name: "Platform", Description: "platform team"}
+		mockRepo.On("CreateGroup", ctx, group).Return(&models.Group{ID: 1, Name: group.Name}, nil).Once()
+
+		created, err := service.CreateGroup(ctx, models.Group{Name: "  Platform ", Description: "platform team "})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), created.ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Invalid group", func(t *testing.T) {
+		for _, group := range []models.Group{
+			{Name: "  "},
+			{Name: strings.Repeat("a", 101)},
+			{Name: "a\nb"},
+			{Name: "Platform", Description: strings.Repeat("a", 1001)},
+		} {
+			_, err := service.CreateGroup(ctx, group)
+			assert.ErrorIs(t, err, models.ErrInvalidGroup, group.Name)
+			assert.ErrorIs(t, service.UpdateGroup(ctx, group), models.ErrInvalidGroup, group.Name)
+		}
+	})
+
+	t.Run("Success - Member role is the default", func(t *testing.T) {
+		membership := models.Membership{GroupID: 1, UserID: 2, Role: models.GroupRoleMember}
+		mockRepo.On("PutGroupMember", ctx, membership).Return(true, nil).Once()
+
+		created, err := service.PutGroupMember(ctx, models.Membership{GroupID: 1, UserID: 2})
+
+		require.NoError(t, err)
+		assert.True(t, created)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Unknown role", func(t *testing.T) {
+		_, err := service.PutGroupMember(ctx, models.Membership{GroupID: 1, UserID: 2, Role: "boss"})
+		assert.ErrorIs(t, err, models.ErrInvalidGroupRole)
+	})
+
+	t.Run("Failure - Members of missing group", func(t *testing.T) {
+		mockRepo.On("GetGroup", ctx, int64(3)).Return(nil, models.ErrGroupDoesNotExist).Once()
+
+		_, err := service.ListGroupMembers(ctx, 3, models.Page{Limit: 10})
+
+		assert.ErrorIs(t, err, models.ErrGroupDoesNotExist)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Groups of deleted user", func(t *testing.T) {
+		endDate := time.Now()
+		user := createValidUser()
+		user.EndDate = &endDate
+		mockRepo.On("GetUser", ctx, int64(1)).Return(&user, nil).Once()
+
+		_, err := service.ListUserGroups(ctx, 1, models.Page{Limit: 10})
+
+		assert.ErrorIs(t, err, models.ErrUserIsGone)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Invalid pagination", func(t *testing.T) {
+		_, err := service.ListGroups(ctx, models.Page{Offset: -1})
+		assert.ErrorIs(t, err, models.ErrInvalidPagination)
+	})
+}
+
+func TestAvatar(t *testing.T) {
+	// Setup
+	mockRepo := new(MockRepository)
+	blobs, err := blob.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	service := &Service{repository: mockRepo, blobs: blobs}
+	ctx := context.Background()
+
+	var img bytes.Buffer
+	require.NoError(t, png.Encode(&img, image.NewGray(image.Rect(0, 0, 300, 200))))
+
+	t.Run("Success - Thumbnails replace the previous version", func(t *testing.T) {
+		for _, size := range models.AvatarSizes {
+			require.NoError(t, blobs.Put(ctx, avatarKey(ctx, 1, "old", size), strings.NewReader("old"), "image/png"))
+		}
+		mockRepo.On("GetUser", ctx, int64(1)).Return(&models.UserInfo{ID: 1, AvatarVersion: "old"}, nil).Once()
+		mockRepo.On("SetAvatarVersion", ctx, int64(1), mock.AnythingOfType("string")).Return("old", nil).Once()
+
+		version, err := service.PutAvatar(ctx, 1, img.Bytes())
+
+		require.NoError(t, err)
+		assert.NotEqual(t, "old", version)
+		for size, edge := range avatarEdges {
+			_, _, err = blobs.Get(ctx, avatarKey(ctx, 1, "old", size))
+			assert.ErrorIs(t, err, blob.ErrNotFound, "previous version is removed")
+
+			r, _, err := blobs.Get(ctx, avatarKey(ctx, 1, version, size))
+			require.NoError(t, err)
+			thumb, err := png.Decode(r)
+			_ = r.Close()
+			require.NoError(t, err)
+			assert.Equal(t, image.Rect(0, 0, edge, edge), thumb.Bounds())
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Images of failed upload are removed", func(t *testing.T) {
+		var version string
+		mockRepo.On("GetUser", ctx, int64(2)).Return(&models.UserInfo{ID: 2}, nil).Once()
+		mockRepo.On("SetAvatarVersion", ctx, int64(2), mock.AnythingOfType("string")).
+			Run(func(args mock.Arguments) { version = args.String(2) }).
+			Return("", models.ErrUserDoesNotExist).Once()
+
+		_, err := service.PutAvatar(ctx, 2, img.Bytes())
+
+		assert.ErrorIs(t, err, models.ErrUserDoesNotExist)
+		_, _, err = blobs.Get(ctx, avatarKey(ctx, 2, version, models.AvatarLarge))
+		assert.ErrorIs(t, err, blob.ErrNotFound)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Broken image", func(t *testing.T) {
+		mockRepo.On("GetUser", ctx, int64(1)).Return(&models.UserInfo{ID: 1}, nil).Once()
+
+		_, err := service.PutAvatar(ctx, 1, img.Bytes()[:40])
+
+		assert.ErrorIs(t, err, models.ErrInvalidAvatar)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure - User without avatar", func(t *testing.T) {
+		mockRepo.On("GetUser", ctx, int64(3)).Return(&models.UserInfo{ID: 3}, nil).Twice()
+
+		_, _, err := service.GetAvatar(ctx, 3, models.AvatarSmall)
+		assert.ErrorIs(t, err, models.ErrAvatarDoesNotExist)
+		assert.ErrorIs(t, service.DeleteAvatar(ctx, 3), models.ErrAvatarDoesNotExist)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Deleted user", func(t *testing.T) {
+		deleted := time.Now()
+		mockRepo.On("GetUser", ctx, int64(4)).
+			Return(&models.UserInfo{ID: 4, AvatarVersion: "v", EndDate: &deleted}, nil).Once()
+
+		_, _, err := service.GetAvatar(ctx, 4, models.AvatarSmall)
+
+		assert.ErrorIs(t, err, models.ErrUserIsGone)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Unknown size", func(t *testing.T) {
+		_, _, err := service.GetAvatar(ctx, 1, "huge")
+		assert.ErrorIs(t, err, models.ErrInvalidAvatarSize)
+	})
+}
+
+func TestExportAndEraseUser(t *testing.T) {
+	// Setup
+	mockRepo := new(MockRepository)
+	blobs, err := blob.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	service := &Service{repository: mockRepo, blobs: blobs}
+	ctx := context.Background()
+	deleted, erased := time.Now(), time.Now()
+
+	for _, size := range models.AvatarSizes {
+		require.NoError(t, blobs.Put(ctx, avatarKey(ctx, 1, "v1", size), strings.NewReader(string(size)), "image/png"))
+	}
+
+	t.Run("Success - Deleted user is exported with hidden memberships and avatar", func(t *testing.T) {
+		user := &models.UserInfo{ID: 1, Username: "jdoe", AvatarVersion: "v1", EndDate: &deleted}
+		groups := []models.UserGroup{{Group: models.Group{ID: 7, Name: "Platform"}, Role: models.GroupRoleOwner}}
+		mockRepo.On("GetUser", ctx, int64(1)).Return(user, nil).Once()
+		history := []models.UserEvent{{Type: models.UserCreated}, {Type: models.UserDeleted, OccurredAt: deleted}}
+		mockRepo.On("ListUserGroups", ctx, int64(1), models.Page{}).Return(groups, nil).Once()
+		mockRepo.On("ListUserEvents", ctx, int64(1)).Return(history, nil).Once()
+
+		export, err := service.ExportUser(ctx, 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, *user, export.User)
+		assert.Equal(t, history, export.History)
+		assert.Equal(t, groups, export.Groups)
+		assert.Equal(t, "default", export.Tenant)
+		assert.Equal(t, []byte("large"), export.Avatar[models.AvatarLarge])
+		assert.Len(t, export.Avatar, len(models.AvatarSizes))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Success - Erasure removes avatar images", func(t *testing.T) {
+		mockRepo.On("GetUser", ctx, int64(1)).Return(&models.UserInfo{ID: 1, AvatarVersion: "v1"}, nil).Once()
+		mockRepo.On("EraseUser", ctx, int64(1)).Return("v1", nil).Once()
+
+		require.NoError(t, service.EraseUser(ctx, 1))
+
+		for _, size := range models.AvatarSizes {
+			_, _, err := blobs.Get(ctx, avatarKey(ctx, 1, "v1", size))
+			assert.ErrorIs(t, err, blob.ErrNotFound)
+		}
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure - Erased user", func(t *testing.T) {
+		user := &models.UserInfo{ID: 2, Username: "erased-2", EndDate: &deleted, ErasedAt: &erased}
+		mockRepo.On("GetUser", ctx, int64(2)).Return(user, nil).Times(4)
+
+		_, err := service.ExportUser(ctx, 2)
+		assert.ErrorIs(t, err, models.ErrUserIsErased)
+		assert.ErrorIs(t, service.EraseUser(ctx, 2), models.ErrUserIsErased)
+		assert.ErrorIs(t, service.RestoreUser(ctx, 2), models.ErrUserIsErased)
+		_, err = service.GetUser(ctx, 2)
+		assert.ErrorIs(t, err, models.ErrUserIsGone, "erased user is gone as a deleted one")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure - User does not exist", func(t *testing.T) {
+		mockRepo.On("GetUser", ctx, int64(3)).Return(nil, models.ErrUserDoesNotExist).Once()
+
+		assert.ErrorIs(t, service.EraseUser(ctx, 3), models.ErrUserDoesNotExist)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+// TestRestoreUser tests the RestoreUser method
+func TestRestoreUser(t *testing.T) {
+	// Setup
+	mockRepo := new(MockRepository)
+	service := &Service{repository: mockRepo}
+	ctx := context.Background()
+
+	t.Run("Success - Restore deleted user", func(t *testing.T) {
+		// Arrange
+		endDate := time.Now()
+		user := createValidUser()
+		user.EndDate = &endDate
+		mockRepo.On("GetUser", ctx, int64(1)).Return(&user, nil).Once()
+		mockRepo.On("RestoreUser", ctx, int64(1)).Return(nil).Once()
+
+		// Act
+		err := service.RestoreUser(ctx, 1)
+
+		// Assert
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure - User is not deleted", func(t *testing.T) {
+		// Arrange
+		user := createValidUser()
+		mockRepo.On("GetUser", ctx, int64(2)).Return(&user, nil).Once()
+
+		// Act
+		err := service.RestoreUser(ctx, 2)
+
+		// Assert
+		assert.ErrorIs(t, err, models.ErrRestoreActiveUser)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failure - User not found", func(t *testing.T) {
+		// Arrange
+		mockRepo.On("GetUser", ctx, int64(3)).Return(nil, models.ErrUserDoesNotExist).Once()
+
+		// Act
+		err := service.RestoreUser(ctx, 3)
+
+		// Assert
+		assert.ErrorIs(t, err, models.ErrUserDoesNotExist)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
 // TestWithCanceledContext tests behavior with canceled context
 func TestWithCanceledContext(t *testing.T) {
 	// Setup
@@ -0,0 +1,4 @@
+// Package user_management_v1 - gRPC API of user management service generated from api/proto.
+package user_management_v1
+
+//go:generate go run github.com/sonikq/gravitum_test_task/cmd/protogen -I ../../../../api/proto -out ../.. -plugin google.golang.org/protobuf/cmd/protoc-gen-go=paths=source_relative -plugin google.golang.org/grpc/cmd/protoc-gen-go-grpc=paths=source_relative user_management/v1/user_management.proto
@@ -0,0 +1,158 @@
+package attributes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	maxNameLen = 63
+	// maxCached - number of compiled schemas kept by validator, cache is dropped when it is exceeded.
+	maxCached = 1000
+)
+
+var (
+	ErrInvalidName      = errors.New("invalid attribute name, lowercase latin letters, digits and underscores are allowed")
+	ErrInvalidSchema    = errors.New("invalid attribute schema")
+	ErrUnknownAttribute = errors.New("unknown attribute")
+	ErrInvalidValue     = errors.New("invalid attribute value")
+)
+
+// ValidName - validating attribute name: lowercase latin letter followed by letters, digits or underscores, up to 63 chars.
+func ValidName(name string) bool {
+	if name == "" || len(name) > maxNameLen || name[0] < 'a' || name[0] > 'z' {
+		return false
+	}
+	for _, c := range []byte(name) {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// Compile - compiling JSON Schema of attribute value, draft 2020-12 is used if $schema is not set.
+// References to other documents are not resolved, so schema can not make the service read files or URLs.
+func Compile(name string, schema []byte) (*jsonschema.Schema, error) {
+	url := "attribute://" + name
+	c := jsonschema.NewCompiler()
+	c.AssertFormat = true
+	c.LoadURL = func(s string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("external reference %s is not allowed", s)
+	}
+	if err := c.AddResource(url, bytes.NewReader(schema)); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+	}
+
+	compiled, err := c.Compile(url)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSchema, schemaErrorMessage(err))
+	}
+	return compiled, nil
+}
+
+// Validator - checking attributes against registered schemas, compiled schemas are cached by their documents.
+// Nil validator compiles schemas on every check.
+type Validator struct {
+	mu       sync.Mutex
+	compiled map[string]*jsonschema.Schema
+}
+
+func NewValidator() *Validator {
+	return &Validator{compiled: make(map[string]*jsonschema.Schema)}
+}
+
+// Validate - checking that every attribute has a schema among schemas by name and its value is valid against it.
+func (v *Validator) Validate(schemas map[string]json.RawMessage, attrs map[string]any) error {
+	// values are brought to the form JSON decoder gives, which schemas are checked on
+	raw, err := json.Marshal(attrs)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidValue, err)
+	}
+	var values map[string]any
+	if err = json.Unmarshal(raw, &values); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidValue, err)
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		schema, ok := schemas[name]
+		if !ok {
+			return fmt.Errorf("%w %s", ErrUnknownAttribute, name)
+		}
+
+		compiled, err := v.compile(name, schema)
+		if err != nil {
+			return err
+		}
+		if err = compiled.Validate(values[name]); err != nil {
+			return fmt.Errorf("%w %s: %s", ErrInvalidValue, name, validationMessage(err))
+		}
+	}
+	return nil
+}
+
+func (v *Validator) compile(name string, schema json.RawMessage) (*jsonschema.Schema, error) {
+	if v == nil {
+		return Compile(name, schema)
+	}
+
+	key := name + "\x00" + string(schema)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if compiled, ok := v.compiled[key]; ok {
+		return compiled, nil
+	}
+
+	compiled, err := Compile(name, schema)
+	if err != nil {
+		return nil, err
+	}
+	if len(v.compiled) >= maxCached {
+		v.compiled = make(map[string]*jsonschema.Schema)
+	}
+	v.compiled[key] = compiled
+	return compiled, nil
+}
+
+// validationMessage - description of the first failed keyword with location of the value part it failed on.
+func validationMessage(err error) string {
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return err.Error()
+	}
+
+	leaf := ve
+	for len(leaf.Causes) > 0 {
+		leaf = leaf.Causes[0]
+	}
+	if leaf.InstanceLocation != "" {
+		return leaf.InstanceLocation + ": " + leaf.Message
+	}
+	return leaf.Message
+}
+
+// schemaErrorMessage - compilation error without URL of the in-memory document.
+func schemaErrorMessage(err error) string {
+	var se *jsonschema.SchemaError
+	if errors.As(err, &se) && se.Err != nil {
+		err = se.Err
+	}
+	if msg := validationMessage(err); msg != "" {
+		return strings.TrimPrefix(msg, "jsonschema: ")
+	}
+	return err.Error()
+}
@@ -0,0 +1,86 @@
+package attributes
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidName(t *testing.T) {
+	for _, name := range []string{"phone", "locale", "cost_center", "x1"} {
+		assert.True(t, ValidName(name), name)
+	}
+	for _, name := range []string{"", "Phone", "1st", "_x", "cost-center", "имя", string(make([]byte, 64))} {
+		assert.False(t, ValidName(name), name)
+	}
+}
+
+func TestCompile(t *testing.T) {
+	_, err := Compile("phone", []byte(`{"type": "string", "pattern": "^\\+[0-9]{7,15}$"}`))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		schema string
+	}{
+		{name: "Not JSON", schema: `{"type":`},
+		{name: "Unknown type", schema: `{"type": "text"}`},
+		{name: "Invalid pattern", schema: `{"type": "string", "pattern": "("}`},
+		{name: "External reference", schema: `{"$ref": "file:///etc/passwd"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Compile("phone", []byte(tc.schema))
+			assert.ErrorIs(t, err, ErrInvalidSchema)
+		})
+	}
+}
+
+func TestValidator_Validate(t *testing.T) {
+	schemas := map[string]json.RawMessage{
+		"phone":  json.RawMessage(`{"type": "string", "pattern": "^\\+[0-9]{7,15}$"}`),
+		"locale": json.RawMessage(`{"enum": ["en", "ru"]}`),
+		"office": json.RawMessage(`{"type": "object", "properties": {"floor": {"type": "integer", "minimum": 0}}, "required": ["floor"]}`),
+	}
+
+	testCases := []struct {
+		name     string
+		attrs    map[string]any
+		expected error
+		message  string
+	}{
+		{name: "Valid", attrs: map[string]any{"phone": "+79990001122", "locale": "ru", "office": map[string]any{"floor": 3}}},
+		{name: "Empty", attrs: map[string]any{}},
+		{name: "Unknown attribute", attrs: map[string]any{"nickname": "jd"}, expected: ErrUnknownAttribute, message: "unknown attribute nickname"},
+		{name: "Wrong type", attrs: map[string]any{"phone": 79990001122}, expected: ErrInvalidValue, message: "invalid attribute value phone: expected string, but got number"},
+		{name: "Not in enum", attrs: map[string]any{"locale": "de"}, expected: ErrInvalidValue},
+		{name: "Nested value", attrs: map[string]any{"office": map[string]any{"floor": -1}}, expected: ErrInvalidValue, message: "invalid attribute value office: /floor: must be >= 0 but found -1"},
+	}
+
+	for _, v := range []*Validator{NewValidator(), nil} {
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				err := v.Validate(schemas, tc.attrs)
+
+				if tc.expected == nil {
+					assert.NoError(t, err)
+					return
+				}
+				assert.ErrorIs(t, err, tc.expected)
+				if tc.message != "" {
+					assert.EqualError(t, err, tc.message)
+				}
+			})
+		}
+	}
+}
+
+func TestValidator_InvalidRegisteredSchema(t *testing.T) {
+	err := NewValidator().Validate(map[string]json.RawMessage{"phone": json.RawMessage(`{"type": 1}`)},
+		map[string]any{"phone": "+79990001122"})
+
+	assert.ErrorIs(t, err, ErrInvalidSchema)
+}
@@ -0,0 +1,34 @@
+// Package blob - storage of binary objects by key, such as avatar images.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound - there is no object with the key.
+var ErrNotFound = errors.New("blob not found")
+
+// ErrInvalidKey - key is not a slash separated relative path without "." and ".." elements.
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// Info - metadata of stored object.
+type Info struct {
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Store - storage of objects by keys like "avatars/default/1/large.png".
+// Implementations are local filesystem now and may be S3 compatible storages later,
+// so keys are plain slash separated paths and objects are written as a whole.
+type Store interface {
+	// Put - writing object under key, existing one is replaced atomically.
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	// Get - reading object, ErrNotFound if there is none. Reader must be closed.
+	Get(ctx context.Context, key string) (io.ReadCloser, Info, error)
+	// Delete - removing object, missing one is not an error.
+	Delete(ctx context.Context, key string) error
+}
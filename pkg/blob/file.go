@@ -0,0 +1,132 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FileStore - Store in directory of local filesystem, key is path of file relative to the root.
+// Content type is not stored, it is taken from extension of key.
+type FileStore struct {
+	root string
+}
+
+// NewFileStore - creating store in root directory, it is created if it is missing.
+func NewFileStore(root string) (*FileStore, error) {
+	if root == "" {
+		return nil, errors.New("blob: root directory is empty")
+	}
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("blob: %w", err)
+	}
+	return &FileStore{root: root}, nil
+}
+
+// Put - writing object to temporary file which is renamed to the key,
+// so readers see either the old object or the new one.
+func (s *FileStore) Put(ctx context.Context, key string, r io.Reader, _ string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		return fmt.Errorf("blob: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".tmp-"+filepath.Base(name)+"-*")
+	if err != nil {
+		return fmt.Errorf("blob: %w", err)
+	}
+	defer func() {
+		// no-op after successful rename
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("blob: write %s: %w", key, err)
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("blob: write %s: %w", key, err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("blob: write %s: %w", key, err)
+	}
+
+	if err = os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("blob: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) Get(ctx context.Context, key string) (io.ReadCloser, Info, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, Info{}, err
+	}
+	if err = ctx.Err(); err != nil {
+		return nil, Info{}, err
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, Info{}, ErrNotFound
+		}
+		return nil, Info{}, fmt.Errorf("blob: %w", err)
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, Info{}, fmt.Errorf("blob: %w", err)
+	}
+	if stat.IsDir() {
+		_ = f.Close()
+		return nil, Info{}, ErrNotFound
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return f, Info{Size: stat.Size(), ContentType: contentType, ModTime: stat.ModTime()}, nil
+}
+
+// Delete - removing file of object, directories left empty are kept.
+func (s *FileStore) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+
+	if err = os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("blob: %w", err)
+	}
+	return nil
+}
+
+// path - file of key, keys escaping the root are rejected.
+func (s *FileStore) path(key string) (string, error) {
+	if !fs.ValidPath(key) || key == "." || strings.Contains(key, `\`) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
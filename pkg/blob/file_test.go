@@ -0,0 +1,85 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	root := filepath.Join(t.TempDir(), "blobs")
+	s, err := NewFileStore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const key = "avatars/default/1/large.png"
+	if _, _, err = s.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("get missing: got %v, want ErrNotFound", err)
+	}
+
+	for _, content := range []string{"first", "second"} {
+		if err = s.Put(ctx, key, strings.NewReader(content), "image/png"); err != nil {
+			t.Fatal(err)
+		}
+
+		r, info, err := s.Get(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(r)
+		_ = r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Errorf("content: got %q, want %q", data, content)
+		}
+		if info.Size != int64(len(content)) || info.ContentType != "image/png" || info.ModTime.IsZero() {
+			t.Errorf("unexpected info %+v", info)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(root, "avatars", "default", "1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("temporary files are left: %v", entries)
+	}
+
+	if err = s.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = s.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("get deleted: got %v, want ErrNotFound", err)
+	}
+	if err = s.Delete(ctx, key); err != nil {
+		t.Errorf("delete missing: %v", err)
+	}
+}
+
+func TestFileStore_InvalidKey(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"", ".", "../escape", "a/../../escape", "/abs", "a//b", `a\b`, "a/"} {
+		if err = s.Put(ctx, key, strings.NewReader("x"), ""); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("put %q: got %v, want ErrInvalidKey", key, err)
+		}
+		if _, _, err = s.Get(ctx, key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("get %q: got %v, want ErrInvalidKey", key, err)
+		}
+		if err = s.Delete(ctx, key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("delete %q: got %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWait     = time.Millisecond
+	defaultMaxBatch = 100
+)
+
+var errBatchLength = errors.New("dataloader: batch function returned fewer results than keys")
+
+// BatchFunc - loading values of all keys at once, both results must be of the keys length.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) ([]V, []error)
+
+// Option - batching settings, zero values give one millisecond wait and batches of 100 keys.
+type Option struct {
+	// Wait - time to collect keys before the batch is loaded.
+	Wait time.Duration
+	// MaxBatch - batch is loaded immediately when it has this many keys.
+	MaxBatch int
+}
+
+// Loader - collecting keys requested concurrently into batches and caching results.
+// Loader is meant to live for one request, so the cache never becomes stale.
+type Loader[K comparable, V any] struct {
+	fetch    BatchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	cache   map[K]*result[V]
+	pending *batch[K, V]
+}
+
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+type batch[K comparable, V any] struct {
+	keys    []K
+	results []*result[V]
+}
+
+func New[K comparable, V any](fetch BatchFunc[K, V], option Option) *Loader[K, V] {
+	if option.Wait <= 0 {
+		option.Wait = defaultWait
+	}
+	if option.MaxBatch <= 0 {
+		option.MaxBatch = defaultMaxBatch
+	}
+
+	return &Loader[K, V]{
+		fetch:    fetch,
+		wait:     option.Wait,
+		maxBatch: option.MaxBatch,
+		cache:    make(map[K]*result[V]),
+	}
+}
+
+// Load - getting value of key, waiting until the batch it got into is loaded or ctx is done.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	res := l.enqueue(ctx, key)
+
+	select {
+	case <-res.done:
+		return res.value, res.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+func (l *Loader[K, V]) enqueue(ctx context.Context, key K) *result[V] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if res, ok := l.cache[key]; ok {
+		return res
+	}
+
+	res := &result[V]{done: make(chan struct{})}
+	l.cache[key] = res
+
+	if l.pending == nil {
+		b := &batch[K, V]{}
+		l.pending = b
+		time.AfterFunc(l.wait, func() {
+			l.dispatch(ctx, b)
+		})
+	}
+
+	l.pending.keys = append(l.pending.keys, key)
+	l.pending.results = append(l.pending.results, res)
+
+	if len(l.pending.keys) >= l.maxBatch {
+		b := l.pending
+		l.pending = nil
+		go l.run(ctx, b)
+	}
+
+	return res
+}
+
+// dispatch - loading batch by timer unless it was already loaded as full.
+func (l *Loader[K, V]) dispatch(ctx context.Context, b *batch[K, V]) {
+	l.mu.Lock()
+	if l.pending != b {
+		l.mu.Unlock()
+		return
+	}
+	l.pending = nil
+	l.mu.Unlock()
+
+	l.run(ctx, b)
+}
+
+// run - calling batch function detached from cancellation of the caller whose key started the batch,
+// so it does not fail keys of other callers. BatchFunc is expected to bound its own time.
+func (l *Loader[K, V]) run(ctx context.Context, b *batch[K, V]) {
+	values, errs := l.fetch(context.WithoutCancel(ctx), b.keys)
+
+	for i, res := range b.results {
+		switch {
+		case i < len(errs) && errs[i] != nil:
+			res.err = errs[i]
+		case i < len(values):
+			res.value = values[i]
+		default:
+			res.err = errBatchLength
+		}
+		close(res.done)
+	}
+
+	// failed keys are not cached, so they can be retried
+	l.mu.Lock()
+	for i, key := range b.keys {
+		if b.results[i].err != nil && l.cache[key] == b.results[i] {
+			delete(l.cache, key)
+		}
+	}
+	l.mu.Unlock()
+}
@@ -0,0 +1,136 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// recordingFetch returns batch function which formats keys and records batches
+func recordingFetch(batches *[][]int, mu *sync.Mutex) BatchFunc[int, string] {
+	return func(ctx context.Context, keys []int) ([]string, []error) {
+		mu.Lock()
+		*batches = append(*batches, append([]int(nil), keys...))
+		mu.Unlock()
+
+		values := make([]string, len(keys))
+		errs := make([]error, len(keys))
+		for i, key := range keys {
+			if key < 0 {
+				errs[i] = errors.New("negative key")
+				continue
+			}
+			values[i] = strconv.Itoa(key)
+		}
+		return values, errs
+	}
+}
+
+func TestLoader_BatchesConcurrentLoads(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		batches [][]int
+	)
+	loader := New(recordingFetch(&batches, &mu), Option{Wait: 10 * time.Millisecond})
+
+	var wg sync.WaitGroup
+	for _, key := range []int{1, 2, 3, 2, -1} {
+		wg.Add(1)
+		go func(key int) {
+			defer wg.Done()
+			value, err := loader.Load(context.Background(), key)
+			if key < 0 {
+				if err == nil {
+					t.Errorf("Expected error for key %d", key)
+				}
+				return
+			}
+			if err != nil || value != strconv.Itoa(key) {
+				t.Errorf("Expected %d, got %q, %v", key, value, err)
+			}
+		}(key)
+	}
+	wg.Wait()
+
+	if len(batches) != 1 {
+		t.Fatalf("Expected one batch, got %v", batches)
+	}
+	if len(batches[0]) != 4 {
+		t.Errorf("Expected duplicate keys to be loaded once, got %v", batches[0])
+	}
+}
+
+func TestLoader_CachesResults(t *testing.T) {
+	var calls atomic.Int32
+	loader := New(func(ctx context.Context, keys []int) ([]string, []error) {
+		calls.Add(1)
+		return make([]string, len(keys)), nil
+	}, Option{})
+
+	for i := 0; i < 3; i++ {
+		if _, err := loader.Load(context.Background(), 1); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected one fetch, got %d", calls.Load())
+	}
+}
+
+func TestLoader_DoesNotCacheErrors(t *testing.T) {
+	var calls atomic.Int32
+	loader := New(func(ctx context.Context, keys []int) ([]string, []error) {
+		calls.Add(1)
+		return nil, nil
+	}, Option{})
+
+	for i := 0; i < 2; i++ {
+		if _, err := loader.Load(context.Background(), 1); !errors.Is(err, errBatchLength) {
+			t.Fatalf("Expected batch length error, got: %v", err)
+		}
+	}
+	if calls.Load() != 2 {
+		t.Errorf("Expected failed key to be fetched again, got %d fetches", calls.Load())
+	}
+}
+
+func TestLoader_MaxBatch(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		batches [][]int
+	)
+	loader := New(recordingFetch(&batches, &mu), Option{Wait: time.Hour, MaxBatch: 2})
+
+	var wg sync.WaitGroup
+	for key := 0; key < 4; key++ {
+		wg.Add(1)
+		go func(key int) {
+			defer wg.Done()
+			if _, err := loader.Load(context.Background(), key); err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+		}(key)
+	}
+	wg.Wait()
+
+	if len(batches) != 2 {
+		t.Errorf("Expected two full batches without waiting, got %v", batches)
+	}
+}
+
+func TestLoader_ContextDone(t *testing.T) {
+	loader := New(func(ctx context.Context, keys []int) ([]string, []error) {
+		return make([]string, len(keys)), nil
+	}, Option{Wait: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := loader.Load(ctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline error, got: %v", err)
+	}
+}
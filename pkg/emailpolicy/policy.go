@@ -0,0 +1,161 @@
+package emailpolicy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/net/idna"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// DefaultTenant - tenant of requests without one.
+const DefaultTenant = "default"
+
+// ErrDomainNotAllowed - email domain is denied or is not in allow-list of the tenant.
+var ErrDomainNotAllowed = errors.New("email domain is not allowed")
+
+// Rules - patterns of email domains, "example.com" matches the domain only,
+// "*.example.com" matches its subdomains at any depth but not the domain itself.
+type Rules struct {
+	// Deny - domains not accepted from any tenant.
+	Deny []string
+	// Allow - domains accepted from tenant, tenant without allow-list accepts every domain which is not denied.
+	Allow map[string][]string
+}
+
+// Validate - checking that every pattern is a domain name, optionally with "*." prefix.
+func (r Rules) Validate() error {
+	_, err := r.compile()
+	return err
+}
+
+// Policy - allow and deny rules of email domains, rules can be replaced while serving.
+// It is a validator.DomainChecker.
+type Policy struct {
+	rules  atomic.Pointer[compiled]
+	tenant func(ctx context.Context) string
+}
+
+// New - creating policy without rules, tenant of request is taken from ctx by tenant func, nil means DefaultTenant.
+func New(tenant func(ctx context.Context) string) *Policy {
+	p := &Policy{tenant: tenant}
+	p.rules.Store(&compiled{})
+	return p
+}
+
+// SetRules - applying new rules to subsequent checks, invalid rules are rejected and current ones are kept.
+func (p *Policy) SetRules(rules Rules) error {
+	c, err := rules.compile()
+	if err != nil {
+		return err
+	}
+	p.rules.Store(c)
+	return nil
+}
+
+// CheckDomain - checking domain in ASCII form against deny-list and allow-list of tenant of the request.
+func (p *Policy) CheckDomain(ctx context.Context, domain string) error {
+	tenant := DefaultTenant
+	if p.tenant != nil {
+		if t := p.tenant(ctx); t != "" {
+			tenant = t
+		}
+	}
+
+	rules := p.rules.Load()
+	if rules.deny.match(domain) {
+		return fmt.Errorf("%w: %s is denied", ErrDomainNotAllowed, domain)
+	}
+	if allow, ok := rules.allow[tenant]; ok && !allow.match(domain) {
+		return fmt.Errorf("%w: %s is not allowed for tenant %s", ErrDomainNotAllowed, domain, tenant)
+	}
+	return nil
+}
+
+// ReadPatterns - reading file with one domain pattern per line, blank lines and lines starting with # are skipped.
+func ReadPatterns(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return patterns, nil
+}
+
+// compiled - rules with patterns in ASCII lowercase form.
+type compiled struct {
+	deny  patterns
+	allow map[string]patterns
+}
+
+func (r Rules) compile() (*compiled, error) {
+	c := &compiled{allow: make(map[string]patterns, len(r.Allow))}
+
+	var err error
+	if c.deny, err = newPatterns(r.Deny); err != nil {
+		return nil, err
+	}
+	for tenant, list := range r.Allow {
+		if c.allow[tenant], err = newPatterns(list); err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", tenant, err)
+		}
+	}
+	return c, nil
+}
+
+// patterns - exact domains and parents of wildcard ones.
+type patterns struct {
+	exact     map[string]struct{}
+	wildcards map[string]struct{}
+}
+
+func newPatterns(list []string) (patterns, error) {
+	p := patterns{exact: make(map[string]struct{}), wildcards: make(map[string]struct{})}
+	for _, pattern := range list {
+		domain, wildcard := strings.CutPrefix(strings.TrimSpace(pattern), "*.")
+		ascii, err := idna.Lookup.ToASCII(domain)
+		if err != nil || ascii == "" || strings.Contains(ascii, "*") {
+			return patterns{}, fmt.Errorf("invalid domain pattern %q", pattern)
+		}
+
+		ascii = strings.ToLower(ascii)
+		if wildcard {
+			p.wildcards[ascii] = struct{}{}
+		} else {
+			p.exact[ascii] = struct{}{}
+		}
+	}
+	return p, nil
+}
+
+func (p patterns) match(domain string) bool {
+	if _, ok := p.exact[domain]; ok {
+		return true
+	}
+	// wildcard matches any domain below its parent
+	for parent := domain; ; {
+		var found bool
+		if _, parent, found = strings.Cut(parent, "."); !found {
+			return false
+		}
+		if _, ok := p.wildcards[parent]; ok {
+			return true
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package emailpolicy
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type tenantKey struct{}
+
+func TestPolicy_CheckDomain(t *testing.T) {
+	p := New(func(ctx context.Context) string {
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		return tenant
+	})
+	err := p.SetRules(Rules{
+		Deny: []string{"mailinator.com", "*.throwaway.example", "*.пример.рф"},
+		Allow: map[string][]string{
+			"acme": {"acme.com", "*.acme.com"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acme := context.WithValue(context.Background(), tenantKey{}, "acme")
+	tests := []struct {
+		ctx     context.Context
+		domain  string
+		allowed bool
+	}{
+		{context.Background(), "example.com", true},
+		{context.Background(), "mailinator.com", false},
+		{context.Background(), "sub.mailinator.com", true},
+		{context.Background(), "throwaway.example", true},
+		{context.Background(), "a.throwaway.example", false},
+		{context.Background(), "a.b.throwaway.example", false},
+		{context.Background(), "mail.xn--e1afmkfd.xn--p1ai", false},
+		{acme, "acme.com", true},
+		{acme, "eu.acme.com", true},
+		{acme, "example.com", false},
+		{acme, "mailinator.com", false},
+	}
+
+	for _, test := range tests {
+		err := p.CheckDomain(test.ctx, test.domain)
+		if allowed := err == nil; allowed != test.allowed {
+			t.Errorf("Expected CheckDomain(%q) allowed = %v, got %v", test.domain, test.allowed, err)
+		}
+		if err != nil && !errors.Is(err, ErrDomainNotAllowed) {
+			t.Errorf("Expected ErrDomainNotAllowed, got %v", err)
+		}
+	}
+
+	// invalid rules are rejected and current ones are kept
+	if err = p.SetRules(Rules{Deny: []string{"bad domain!"}}); err == nil {
+		t.Error("Expected invalid pattern to be rejected")
+	}
+	if err = p.CheckDomain(context.Background(), "mailinator.com"); err == nil {
+		t.Error("Expected current rules to be kept")
+	}
+}
+
+func TestReadPatterns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deny.txt")
+	content := "# disposable\nmailinator.com\n\n  *.throwaway.example  \n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	patterns, err := ReadPatterns(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patterns) != 2 || patterns[0] != "mailinator.com" || patterns[1] != "*.throwaway.example" {
+		t.Errorf("Unexpected patterns %q", patterns)
+	}
+
+	if _, err = ReadPatterns(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("Expected error of missing file")
+	}
+}
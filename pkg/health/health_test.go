@@ -0,0 +1,79 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegistry_Run(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("ok", time.Second, func(ctx context.Context) error { return nil })
+
+	report := registry.Run(context.Background())
+	if !report.OK() {
+		t.Fatalf("Expected ok report, got %+v", report)
+	}
+
+	registry.Register("broken", time.Second, func(ctx context.Context) error { return errors.New("connection refused") })
+
+	report = registry.Run(context.Background())
+	if report.Status != StatusFail {
+		t.Errorf("Expected status %q, got %q", StatusFail, report.Status)
+	}
+	if len(report.Checks) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(report.Checks))
+	}
+	if report.Checks[0].Status != StatusOK || report.Checks[1].Status != StatusFail {
+		t.Errorf("Unexpected results order or statuses: %+v", report.Checks)
+	}
+	if report.Checks[1].Error != "connection refused" {
+		t.Errorf("Expected error of failed check, got %q", report.Checks[1].Error)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "connection refused") {
+		t.Errorf("Expected error of check to be hidden from serialized report, got %s", data)
+	}
+}
+
+func TestRegistry_RunTimeout(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("hanging", 10*time.Millisecond, func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	start := time.Now()
+	report := registry.Run(context.Background())
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected check to be cut by timeout, took %v", elapsed)
+	}
+	if report.Status != StatusFail {
+		t.Errorf("Expected status %q, got %q", StatusFail, report.Status)
+	}
+	if report.Checks[0].Error != context.DeadlineExceeded.Error() {
+		t.Errorf("Expected deadline error, got %q", report.Checks[0].Error)
+	}
+}
+
+func TestRegistry_SetShuttingDown(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("ok", 0, func(ctx context.Context) error { return nil })
+	registry.SetShuttingDown()
+
+	report := registry.Run(context.Background())
+	if report.OK() {
+		t.Fatal("Expected not ready report during shutdown")
+	}
+	if report.Status != StatusShuttingDown {
+		t.Errorf("Expected status %q, got %q", StatusShuttingDown, report.Status)
+	}
+}
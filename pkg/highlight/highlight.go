@@ -0,0 +1,65 @@
+package highlight
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Mark - wrapping case-insensitive occurrences of terms in value with pre and post,
+// the rest of value is HTML-escaped. Overlapping and adjacent occurrences are wrapped once.
+// Reports whether any term occurs in value.
+func Mark(value string, terms []string, pre, post string) (string, bool) {
+	runes := []rune(value)
+	// runes are lowered one by one, so positions in lowered value match positions in value
+	lowered := make([]rune, len(runes))
+	for i, r := range runes {
+		lowered[i] = unicode.ToLower(r)
+	}
+
+	marked := make([]bool, len(runes))
+	found := false
+	for _, term := range terms {
+		needle := []rune(strings.ToLower(term))
+		if len(needle) == 0 {
+			continue
+		}
+		for i := 0; i+len(needle) <= len(lowered); i++ {
+			if equalRunes(lowered[i:i+len(needle)], needle) {
+				for j := i; j < i+len(needle); j++ {
+					marked[j] = true
+				}
+				found = true
+			}
+		}
+	}
+	if !found {
+		return html.EscapeString(value), false
+	}
+
+	var sb strings.Builder
+	for start := 0; start < len(runes); {
+		end := start
+		for end < len(runes) && marked[end] == marked[start] {
+			end++
+		}
+		if marked[start] {
+			sb.WriteString(pre)
+		}
+		sb.WriteString(html.EscapeString(string(runes[start:end])))
+		if marked[start] {
+			sb.WriteString(post)
+		}
+		start = end
+	}
+	return sb.String(), true
+}
+
+func equalRunes(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
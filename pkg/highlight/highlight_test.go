@@ -0,0 +1,29 @@
+package highlight
+
+import "testing"
+
+func TestMark(t *testing.T) {
+	tests := []struct {
+		value  string
+		terms  []string
+		want   string
+		marked bool
+	}{
+		{"Ivan", []string{"iv"}, "<mark>Iv</mark>an", true},
+		{"ivan.petrov@example.com", []string{"petrov", "example"}, "ivan.<mark>petrov</mark>@<mark>example</mark>.com", true},
+		{"Anna", []string{"n"}, "A<mark>nn</mark>a", true},
+		{"annabel", []string{"ann", "nab"}, "<mark>annab</mark>el", true},
+		{"Пётр", []string{"пёт"}, "<mark>Пёт</mark>р", true},
+		{"<b>", []string{"b"}, "&lt;<mark>b</mark>&gt;", true},
+		{"<b>", []string{"x"}, "&lt;b&gt;", false},
+		{"", []string{"a"}, "", false},
+		{"abc", []string{""}, "abc", false},
+	}
+
+	for _, test := range tests {
+		got, marked := Mark(test.value, test.terms, "<mark>", "</mark>")
+		if got != test.want || marked != test.marked {
+			t.Errorf("Mark(%q, %q) = %q, %v, expected %q, %v", test.value, test.terms, got, marked, test.want, test.marked)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const defaultStopTimeout = 10 * time.Second
+
+// Hook - start or stop function of a component.
+type Hook func(ctx context.Context) error
+
+// Component - part of the application with its own start and stop.
+type Component struct {
+	// Name - unique name used in dependencies and errors.
+	Name string
+	// DependsOn - names of components which must be started before this one and stopped after it.
+	DependsOn []string
+	// Start - must not block after the component is ready, long-running work goes to goroutines
+	// which report unexpected failures with Manager.Fail.
+	Start Hook
+	// Stop - releasing resources, must finish within StopTimeout.
+	Stop Hook
+	// StopTimeout - limit of Stop, zero means manager default.
+	StopTimeout time.Duration
+}
+
+// Manager - starting components in dependency order and stopping them in reverse order.
+type Manager struct {
+	mu          sync.Mutex
+	components  []Component
+	started     []Component
+	stopTimeout time.Duration
+
+	failOnce sync.Once
+	failed   chan error
+}
+
+// New - manager with default stop timeout for components without their own, zero means ten seconds.
+func New(stopTimeout time.Duration) *Manager {
+	if stopTimeout <= 0 {
+		stopTimeout = defaultStopTimeout
+	}
+	return &Manager{
+		stopTimeout: stopTimeout,
+		failed:      make(chan error, 1),
+	}
+}
+
+// Add - registering component, must be called before Start.
+func (m *Manager) Add(c Component) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.components = append(m.components, c)
+}
+
+// Fail - reporting failure of a running component, first failure makes Wait return.
+func (m *Manager) Fail(err error) {
+	m.failOnce.Do(func() {
+		m.failed <- err
+	})
+}
+
+// Start - starting components in dependency order. If one of them fails,
+// already started ones are stopped and the start error is returned.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	order, err := sortByDependencies(m.components)
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, c := range order {
+		if c.Start != nil {
+			if err = c.Start(ctx); err != nil {
+				err = fmt.Errorf("start %s: %w", c.Name, err)
+				return errors.Join(err, m.Stop(context.Background()))
+			}
+		}
+
+		m.mu.Lock()
+		m.started = append(m.started, c)
+		m.mu.Unlock()
+	}
+
+	return nil
+}
+
+// Wait - blocking until ctx is done or a running component fails, returns the failure.
+func (m *Manager) Wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-m.failed:
+		return err
+	}
+}
+
+// Stop - stopping started components in reverse order, each one bounded by its timeout.
+// Cancelling ctx abandons components which have not stopped yet, e.g. on a second signal.
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	started := m.started
+	m.started = nil
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		c := started[i]
+		if c.Stop == nil {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("stop %s: %w", c.Name, err))
+			continue
+		}
+
+		if err := m.stop(ctx, c); err != nil {
+			errs = append(errs, fmt.Errorf("stop %s: %w", c.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (m *Manager) stop(ctx context.Context, c Component) error {
+	timeout := c.StopTimeout
+	if timeout <= 0 {
+		timeout = m.stopTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// hook may ignore ctx, so it is not waited for longer than the timeout
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- c.Stop(ctx)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sortByDependencies - topological order of components, registration order is kept where possible.
+func sortByDependencies(components []Component) ([]Component, error) {
+	index := make(map[string]int, len(components))
+	for i, c := range components {
+		if _, ok := index[c.Name]; ok {
+			return nil, fmt.Errorf("component %q is registered twice", c.Name)
+		}
+		index[c.Name] = i
+	}
+
+	for _, c := range components {
+		for _, dep := range c.DependsOn {
+			if _, ok := index[dep]; !ok {
+				return nil, fmt.Errorf("component %q depends on unknown component %q", c.Name, dep)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(components))
+	order := make([]Component, 0, len(components))
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle through component %q", components[i].Name)
+		}
+
+		state[i] = visiting
+		for _, dep := range components[i].DependsOn {
+			if err := visit(index[dep]); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+		order = append(order, components[i])
+		return nil
+	}
+
+	for i := range components {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
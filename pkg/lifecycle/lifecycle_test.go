@@ -0,0 +1,164 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recorder - component factory which records calls of hooks
+type recorder struct {
+	calls []string
+}
+
+func (r *recorder) component(name string, deps ...string) Component {
+	return Component{
+		Name:      name,
+		DependsOn: deps,
+		Start: func(ctx context.Context) error {
+			r.calls = append(r.calls, "start "+name)
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			r.calls = append(r.calls, "stop "+name)
+			return nil
+		},
+	}
+}
+
+func TestManager_Order(t *testing.T) {
+	rec := &recorder{}
+	manager := New(time.Second)
+	manager.Add(rec.component("http", "repository", "cache"))
+	manager.Add(rec.component("repository"))
+	manager.Add(rec.component("cache", "repository"))
+
+	if err := manager.Start(context.Background()); err != nil {
+		t.Fatalf("Expected no start error, got: %v", err)
+	}
+	if err := manager.Stop(context.Background()); err != nil {
+		t.Fatalf("Expected no stop error, got: %v", err)
+	}
+
+	expected := []string{
+		"start repository", "start cache", "start http",
+		"stop http", "stop cache", "stop repository",
+	}
+	if !reflect.DeepEqual(rec.calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, rec.calls)
+	}
+}
+
+func TestManager_StartFailureStopsStarted(t *testing.T) {
+	rec := &recorder{}
+	broken := rec.component("http", "repository")
+	broken.Start = func(ctx context.Context) error { return errors.New("address already in use") }
+
+	manager := New(time.Second)
+	manager.Add(rec.component("repository"))
+	manager.Add(broken)
+
+	err := manager.Start(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "start http: address already in use") {
+		t.Fatalf("Expected start error of http, got: %v", err)
+	}
+
+	expected := []string{"start repository", "stop repository"}
+	if !reflect.DeepEqual(rec.calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, rec.calls)
+	}
+}
+
+func TestManager_InvalidDependencies(t *testing.T) {
+	rec := &recorder{}
+
+	manager := New(time.Second)
+	manager.Add(rec.component("a", "b"))
+	manager.Add(rec.component("b", "a"))
+	if err := manager.Start(context.Background()); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("Expected dependency cycle error, got: %v", err)
+	}
+
+	manager = New(time.Second)
+	manager.Add(rec.component("a", "missing"))
+	if err := manager.Start(context.Background()); err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Errorf("Expected unknown dependency error, got: %v", err)
+	}
+
+	if len(rec.calls) != 0 {
+		t.Errorf("Expected nothing to be started, got %v", rec.calls)
+	}
+}
+
+func TestManager_StopTimeout(t *testing.T) {
+	rec := &recorder{}
+	hanging := rec.component("worker", "repository")
+	hanging.StopTimeout = 20 * time.Millisecond
+	hanging.Stop = func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}
+
+	manager := New(time.Second)
+	manager.Add(rec.component("repository"))
+	manager.Add(hanging)
+
+	if err := manager.Start(context.Background()); err != nil {
+		t.Fatalf("Expected no start error, got: %v", err)
+	}
+
+	start := time.Now()
+	err := manager.Stop(context.Background())
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Expected hanging component to be abandoned after its timeout")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline error, got: %v", err)
+	}
+	if rec.calls[len(rec.calls)-1] != "stop repository" {
+		t.Errorf("Expected repository to be stopped after timed out worker, got %v", rec.calls)
+	}
+}
+
+func TestManager_StopCanceled(t *testing.T) {
+	rec := &recorder{}
+	manager := New(time.Second)
+	manager.Add(rec.component("repository"))
+
+	if err := manager.Start(context.Background()); err != nil {
+		t.Fatalf("Expected no start error, got: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := manager.Stop(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected canceled error, got: %v", err)
+	}
+	if len(rec.calls) != 1 {
+		t.Errorf("Expected stop to be skipped, got %v", rec.calls)
+	}
+}
+
+func TestManager_Wait(t *testing.T) {
+	manager := New(time.Second)
+
+	failure := errors.New("listener closed")
+	go func() {
+		manager.Fail(failure)
+		manager.Fail(errors.New("ignored"))
+	}()
+
+	if err := manager.Wait(context.Background()); !errors.Is(err, failure) {
+		t.Errorf("Expected first failure, got: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := manager.Wait(ctx); err != nil {
+		t.Errorf("Expected no error on context done, got: %v", err)
+	}
+}
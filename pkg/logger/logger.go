@@ -1,21 +1,160 @@
 package logger
 
 import (
+	"fmt"
 	"github.com/rs/zerolog"
+	"io"
 	"os"
+	"runtime"
+	"strings"
+	"time"
 )
 
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+// callerSkipFrames - frames of errorHook.Run, Event.msg and Event.Msg above the code which sent the event.
+const callerSkipFrames = 3
+
 type Logger struct {
 	zerolog.Logger
+	closer io.Closer
+}
+
+// Option - logger settings, zero value gives info level JSON logs to stdout.
+type Option struct {
+	// Level - minimal level, can be changed later with SetLevel.
+	Level string
+	// Format - FormatJSON or FormatConsole for human-readable stdout.
+	Format string
+	// File - optional file sink, logs are written there in JSON in addition to stdout.
+	File FileOption
+	// SampleInfo - keep only every N-th info message, 0 and 1 disable sampling.
+	SampleInfo uint32
+	// Caller - add caller file and line to error logs.
+	Caller bool
+	// Stack - add stack trace to error logs.
+	Stack bool
 }
 
-func NewLogger(serviceName string) *Logger {
-	lg := zerolog.New(os.Stdout).
-		Level(zerolog.InfoLevel).
+// FileOption - file sink with rotation by size or age, empty Path disables the sink.
+type FileOption struct {
+	Path       string
+	MaxSizeMB  int
+	MaxAge     time.Duration
+	MaxBackups int
+}
+
+func NewLogger(serviceName string, option Option) (*Logger, error) {
+	if option.Level == "" {
+		option.Level = zerolog.InfoLevel.String()
+	}
+	if err := SetLevel(option.Level); err != nil {
+		return nil, err
+	}
+
+	var out io.Writer
+	switch option.Format {
+	case "", FormatJSON:
+		out = os.Stdout
+	case FormatConsole:
+		out = zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
+	default:
+		return nil, fmt.Errorf("unknown log format %q, available is: json/console", option.Format)
+	}
+
+	var closer io.Closer
+	if option.File.Path != "" {
+		file, err := newRotatingFile(option.File)
+		if err != nil {
+			return nil, err
+		}
+		out = zerolog.MultiLevelWriter(out, file)
+		closer = file
+	}
+
+	lg := zerolog.New(out).
 		With().Str("service", serviceName).
 		Timestamp().Logger()
 
+	if option.SampleInfo > 1 {
+		lg = lg.Sample(&zerolog.LevelSampler{
+			InfoSampler: &zerolog.BasicSampler{N: option.SampleInfo},
+		})
+	}
+
+	if option.Caller || option.Stack {
+		lg = lg.Hook(errorHook{caller: option.Caller, stack: option.Stack})
+	}
+
 	return &Logger{
 		Logger: lg,
+		closer: closer,
+	}, nil
+}
+
+// Close - flushing and closing file sink if there is one.
+func (l *Logger) Close() error {
+	if l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
+
+// ParseLevel - parsing level name, "release" is an alias of info.
+func ParseLevel(level string) (zerolog.Level, error) {
+	if strings.EqualFold(level, "release") {
+		return zerolog.InfoLevel, nil
+	}
+	return zerolog.ParseLevel(strings.ToLower(level))
+}
+
+// SetLevel - changing minimal level of all loggers at runtime.
+func SetLevel(level string) error {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	zerolog.SetGlobalLevel(lvl)
+	return nil
+}
+
+// errorHook - adding caller and stack trace to error and more severe events.
+type errorHook struct {
+	caller bool
+	stack  bool
+}
+
+func (h errorHook) Run(e *zerolog.Event, level zerolog.Level, _ string) {
+	if level < zerolog.ErrorLevel || level == zerolog.NoLevel {
+		return
+	}
+
+	if h.caller {
+		if _, file, line, ok := runtime.Caller(callerSkipFrames); ok {
+			e.Str(zerolog.CallerFieldName, zerolog.CallerMarshalFunc(0, file, line))
+		}
+	}
+
+	if h.stack {
+		e.Str(zerolog.ErrorStackFieldName, stackTrace(callerSkipFrames+1))
+	}
+}
+
+func stackTrace(skip int) string {
+	pc := make([]uintptr, 32)
+	n := runtime.Callers(skip+1, pc)
+	frames := runtime.CallersFrames(pc[:n])
+
+	var sb strings.Builder
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&sb, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
 	}
+	return sb.String()
 }
@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readEntries reads JSON log lines written to the file sink
+func readEntries(t *testing.T, path string) []map[string]any {
+	t.Helper()
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var entries []map[string]any
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry := make(map[string]any)
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	require.NoError(t, scanner.Err())
+	return entries
+}
+
+func TestNewLogger_CallerAndStackOnErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	lg, err := NewLogger("test", Option{File: FileOption{Path: path}, Caller: true, Stack: true})
+	require.NoError(t, err)
+
+	lg.Info().Msg("info message")
+	lg.Error().Msg("error message")
+	require.NoError(t, lg.Close())
+
+	entries := readEntries(t, path)
+	require.Len(t, entries, 2)
+
+	assert.NotContains(t, entries[0], "caller")
+	assert.NotContains(t, entries[0], "stack")
+
+	caller, ok := entries[1]["caller"].(string)
+	require.True(t, ok)
+	assert.Contains(t, caller, "logger_test.go:")
+
+	stack, ok := entries[1]["stack"].(string)
+	require.True(t, ok)
+	assert.True(t, strings.HasPrefix(stack, "github.com/sonikq/gravitum_test_task/pkg/logger.TestNewLogger_CallerAndStackOnErrors"))
+}
+
+func TestNewLogger_Level(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	lg, err := NewLogger("test", Option{Level: "warn", File: FileOption{Path: path}})
+	require.NoError(t, err)
+	defer SetLevel("info")
+
+	lg.Info().Msg("skipped")
+	lg.Warn().Msg("written")
+
+	require.NoError(t, SetLevel("debug"))
+	lg.Debug().Msg("written after level change")
+	require.NoError(t, lg.Close())
+
+	entries := readEntries(t, path)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "written", entries[0]["message"])
+	assert.Equal(t, "written after level change", entries[1]["message"])
+}
+
+func TestNewLogger_SampleInfo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	lg, err := NewLogger("test", Option{File: FileOption{Path: path}, SampleInfo: 10})
+	require.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		lg.Info().Msg("sampled")
+	}
+	lg.Error().Msg("not sampled")
+	require.NoError(t, lg.Close())
+
+	entries := readEntries(t, path)
+	assert.Len(t, entries, 11)
+}
+
+func TestNewLogger_InvalidOptions(t *testing.T) {
+	_, err := NewLogger("test", Option{Level: "loud"})
+	assert.Error(t, err)
+
+	_, err = NewLogger("test", Option{Format: "xml"})
+	assert.Error(t, err)
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		level    string
+		expected string
+		wantErr  bool
+	}{
+		{"release", "info", false},
+		{"DEBUG", "debug", false},
+		{"warn", "warn", false},
+		{"loud", "", true},
+	}
+
+	for _, test := range tests {
+		level, err := ParseLevel(test.level)
+		if test.wantErr {
+			assert.Error(t, err, test.level)
+			continue
+		}
+		require.NoError(t, err, test.level)
+		assert.Equal(t, test.expected, level.String())
+	}
+}
+
+func TestRotatingFile_RotateBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	rf, err := newRotatingFile(FileOption{Path: path, MaxSizeMB: 1, MaxBackups: 2})
+	require.NoError(t, err)
+
+	now := time.Date(2025, 3, 23, 15, 0, 0, 0, time.UTC)
+	rf.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	chunk := make([]byte, bytesInMegabyte/2+1)
+	for i := 0; i < 8; i++ {
+		_, err = rf.Write(chunk)
+		require.NoError(t, err)
+	}
+	require.NoError(t, rf.Close())
+
+	backups, err := filepath.Glob(path + ".*")
+	require.NoError(t, err)
+	assert.Len(t, backups, 2, "only MaxBackups newest files are kept")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(chunk)), info.Size())
+}
+
+func TestRotatingFile_RotateByAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	rf, err := newRotatingFile(FileOption{Path: path, MaxAge: time.Hour})
+	require.NoError(t, err)
+
+	now := time.Now()
+	rf.now = func() time.Time { return now }
+
+	_, err = rf.Write([]byte("first\n"))
+	require.NoError(t, err)
+
+	now = now.Add(2 * time.Hour)
+	_, err = rf.Write([]byte("second\n"))
+	require.NoError(t, err)
+	require.NoError(t, rf.Close())
+
+	backups, err := filepath.Glob(path + ".*")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second\n", string(data))
+}
@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxSizeMB = 100
+	backupTimeFormat = "20060102T150405.000"
+	defaultFileMode  = 0644
+	defaultDirMode   = 0755
+	bytesInMegabyte  = 1 << 20
+)
+
+// rotatingFile - file writer which moves the file aside when it grows above MaxSizeMB
+// or gets older than MaxAge, only MaxBackups newest rotated files are kept.
+type rotatingFile struct {
+	mu       sync.Mutex
+	option   FileOption
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	now      func() time.Time
+}
+
+func newRotatingFile(option FileOption) (*rotatingFile, error) {
+	if option.MaxSizeMB <= 0 {
+		option.MaxSizeMB = defaultMaxSizeMB
+	}
+
+	rf := &rotatingFile{option: option, now: time.Now}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (rf *rotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if rf.shouldRotate(int64(len(p))) {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+func (rf *rotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.file == nil {
+		return nil
+	}
+	err := rf.file.Close()
+	rf.file = nil
+	return err
+}
+
+func (rf *rotatingFile) shouldRotate(next int64) bool {
+	if rf.size > 0 && rf.size+next > int64(rf.option.MaxSizeMB)*bytesInMegabyte {
+		return true
+	}
+	return rf.option.MaxAge > 0 && rf.now().Sub(rf.openedAt) >= rf.option.MaxAge
+}
+
+func (rf *rotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(rf.option.Path), defaultDirMode); err != nil {
+		return fmt.Errorf("log file: %w", err)
+	}
+
+	file, err := os.OpenFile(rf.option.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, defaultFileMode)
+	if err != nil {
+		return fmt.Errorf("log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("log file: %w", err)
+	}
+
+	rf.file = file
+	rf.size = info.Size()
+	rf.openedAt = rf.now()
+	if info.Size() > 0 {
+		rf.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+func (rf *rotatingFile) rotate() error {
+	if err := rf.file.Close(); err != nil {
+		return fmt.Errorf("log file: %w", err)
+	}
+	rf.file = nil
+
+	backup := rf.option.Path + "." + rf.now().Format(backupTimeFormat)
+	if err := os.Rename(rf.option.Path, backup); err != nil {
+		return fmt.Errorf("log file: %w", err)
+	}
+
+	if err := rf.open(); err != nil {
+		return err
+	}
+	rf.openedAt = rf.now()
+
+	return rf.removeOldBackups()
+}
+
+func (rf *rotatingFile) removeOldBackups() error {
+	if rf.option.MaxBackups <= 0 {
+		return nil
+	}
+
+	backups, err := filepath.Glob(rf.option.Path + ".*")
+	if err != nil {
+		return fmt.Errorf("log file: %w", err)
+	}
+
+	// backup suffix is a timestamp, so lexical order is chronological
+	sort.Strings(backups)
+	for len(backups) > rf.option.MaxBackups {
+		if err = os.Remove(backups[0]); err != nil {
+			return fmt.Errorf("log file: %w", err)
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package retrier
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+)
+
+// Any - classifier reporting error as retryable if one of classifiers does.
+func Any(classifiers ...func(error) bool) func(error) bool {
+	return func(err error) bool {
+		for _, retryable := range classifiers {
+			if retryable(err) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// IsNetworkError - whether err is a transient network failure: timeout, refused or reset
+// connection, failed DNS lookup or connection closed in the middle of reading.
+func IsNetworkError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTemporary || dnsErr.IsTimeout || dnsErr.IsNotFound
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
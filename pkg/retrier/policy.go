@@ -0,0 +1,65 @@
+package retrier
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Policy - computes delay before the next attempt.
+type Policy interface {
+	// Next - delay after failed attempt, prev is the delay returned for the previous attempt.
+	Next(attempt int, prev time.Duration) time.Duration
+}
+
+// Constant - same delay between all attempts.
+type Constant struct {
+	Delay time.Duration
+}
+
+func (c Constant) Next(int, time.Duration) time.Duration {
+	return c.Delay
+}
+
+// Exponential - delay grows from Initial by Multiplier up to Max
+// and is randomly spread by Jitter fraction in both directions.
+type Exponential struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+	Jitter     float64
+}
+
+func (e Exponential) Next(attempt int, _ time.Duration) time.Duration {
+	delay := float64(e.Initial) * math.Pow(e.Multiplier, float64(attempt-1))
+	if e.Max > 0 && delay > float64(e.Max) {
+		delay = float64(e.Max)
+	}
+	if e.Jitter > 0 {
+		delay += delay * e.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(delay)
+}
+
+// DecorrelatedJitter - random delay between Base and three times the previous one, capped by Max.
+// Spreads retries of many clients better than exponential backoff.
+type DecorrelatedJitter struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+func (d DecorrelatedJitter) Next(_ int, prev time.Duration) time.Duration {
+	if prev < d.Base {
+		prev = d.Base
+	}
+
+	upper := 3 * prev
+	delay := d.Base
+	if upper > d.Base {
+		delay += time.Duration(rand.Int64N(int64(upper - d.Base)))
+	}
+	if d.Max > 0 && delay > d.Max {
+		delay = d.Max
+	}
+	return delay
+}
@@ -1,20 +1,101 @@
 package retrier
 
-import "time"
-
-var (
-	maxAttempts = 3
-	delays      = []time.Duration{1 * time.Second, 3 * time.Second, 5 * time.Second}
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
 )
 
-func DoWithRetries(fn func() error) error {
-	var err error
-	for i := 0; i < maxAttempts; i++ {
-		if err = fn(); err != nil {
-			time.Sleep(delays[i])
-			continue
+// Attempt - outcome of one failed call passed to Config.OnRetry.
+type Attempt struct {
+	// Number - attempt number starting from 1.
+	Number int
+	// Err - error returned by the call.
+	Err error
+	// Delay - wait before the next attempt, zero if this attempt is the last one.
+	Delay time.Duration
+	// Elapsed - time since the first attempt started.
+	Elapsed time.Duration
+	// Final - no more attempts are made after this one.
+	Final bool
+}
+
+// Config - retry settings.
+type Config struct {
+	// Policy - delays between attempts, Constant of one second if nil.
+	Policy Policy
+	// MaxAttempts - limit of calls, 0 means unlimited.
+	MaxAttempts int
+	// MaxElapsed - no attempt is started later than this since the first one, 0 means unlimited.
+	MaxElapsed time.Duration
+	// Retryable - classifier of errors worth retrying, every error is retried if nil.
+	Retryable func(error) bool
+	// OnRetry - hook called after every failed attempt, e.g. for logging or metrics.
+	OnRetry func(Attempt)
+}
+
+// Do - calling fn until it succeeds, returns non-retryable error or limits are reached.
+// Waits between attempts are interrupted when ctx is done.
+func Do(ctx context.Context, cfg Config, fn func(ctx context.Context) error) error {
+	policy := cfg.Policy
+	if policy == nil {
+		policy = Constant{Delay: time.Second}
+	}
+
+	start := time.Now()
+	var delay time.Duration
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		delay = policy.Next(attempt, delay)
+		elapsed := time.Since(start)
+
+		final := ctx.Err() != nil ||
+			(cfg.Retryable != nil && !cfg.Retryable(err)) ||
+			(cfg.MaxAttempts > 0 && attempt >= cfg.MaxAttempts) ||
+			(cfg.MaxElapsed > 0 && elapsed+delay > cfg.MaxElapsed)
+
+		if cfg.OnRetry != nil {
+			report := Attempt{Number: attempt, Err: err, Delay: delay, Elapsed: elapsed, Final: final}
+			if final {
+				report.Delay = 0
+			}
+			cfg.OnRetry(report)
 		}
+
+		if final {
+			if ctx.Err() != nil {
+				return errors.Join(err, ctx.Err())
+			}
+			return fmt.Errorf("after %d attempts: %w", attempt, err)
+		}
+
+		if waitErr := wait(ctx, delay); waitErr != nil {
+			return errors.Join(err, waitErr)
+		}
+	}
+}
+
+// DoWithRetries - calling fn up to three times with growing delays.
+func DoWithRetries(ctx context.Context, fn func(ctx context.Context) error) error {
+	return Do(ctx, Config{
+		Policy:      Exponential{Initial: time.Second, Multiplier: 3, Max: 5 * time.Second},
+		MaxAttempts: 3,
+	}, fn)
+}
+
+func wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
 		return nil
 	}
-	return err
 }
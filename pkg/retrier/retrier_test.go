@@ -0,0 +1,214 @@
+package retrier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+var errTransient = errors.New("transient")
+
+func TestDo_SucceedsAfterFailures(t *testing.T) {
+	var calls int
+	var attempts []Attempt
+	err := Do(context.Background(), Config{
+		Policy:  Constant{Delay: time.Millisecond},
+		OnRetry: func(a Attempt) { attempts = append(attempts, a) },
+	}, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return errTransient
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("Expected 2 hook calls, got %d", len(attempts))
+	}
+	for i, a := range attempts {
+		if a.Number != i+1 || a.Final || a.Delay != time.Millisecond || !errors.Is(a.Err, errTransient) {
+			t.Errorf("Unexpected attempt %+v", a)
+		}
+	}
+}
+
+func TestDo_MaxAttempts(t *testing.T) {
+	var calls int
+	var last Attempt
+	start := time.Now()
+	err := Do(context.Background(), Config{
+		Policy:      Constant{Delay: 50 * time.Millisecond},
+		MaxAttempts: 2,
+		OnRetry:     func(a Attempt) { last = a },
+	}, func(ctx context.Context) error {
+		calls++
+		return errTransient
+	})
+
+	if !errors.Is(err, errTransient) {
+		t.Fatalf("Expected last error, got: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+	if !last.Final || last.Delay != 0 {
+		t.Errorf("Expected final attempt without delay, got %+v", last)
+	}
+	if elapsed := time.Since(start); elapsed > 90*time.Millisecond {
+		t.Errorf("Expected no sleep after the last attempt, took %v", elapsed)
+	}
+}
+
+func TestDo_NonRetryableError(t *testing.T) {
+	permanent := errors.New("permanent")
+	var calls int
+	err := Do(context.Background(), Config{
+		Policy:    Constant{Delay: time.Millisecond},
+		Retryable: func(err error) bool { return errors.Is(err, errTransient) },
+	}, func(ctx context.Context) error {
+		calls++
+		return permanent
+	})
+
+	if !errors.Is(err, permanent) {
+		t.Fatalf("Expected permanent error, got: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+func TestDo_MaxElapsed(t *testing.T) {
+	var calls int
+	start := time.Now()
+	err := Do(context.Background(), Config{
+		Policy:     Constant{Delay: 20 * time.Millisecond},
+		MaxElapsed: 50 * time.Millisecond,
+	}, func(ctx context.Context) error {
+		calls++
+		return errTransient
+	})
+
+	if !errors.Is(err, errTransient) {
+		t.Fatalf("Expected last error, got: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+	if elapsed := time.Since(start); elapsed > 70*time.Millisecond {
+		t.Errorf("Expected to give up within max elapsed time, took %v", elapsed)
+	}
+}
+
+func TestDo_StopsOnContextDone(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := Do(ctx, Config{Policy: Constant{Delay: time.Hour}}, func(ctx context.Context) error {
+		return errTransient
+	})
+
+	if time.Since(start) > time.Second {
+		t.Errorf("Expected wait to be interrupted by context")
+	}
+	if !errors.Is(err, errTransient) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected last error joined with deadline error, got: %v", err)
+	}
+}
+
+func TestDoWithRetries(t *testing.T) {
+	var calls int
+	err := DoWithRetries(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			return errTransient
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+}
+
+func TestExponential_Next(t *testing.T) {
+	policy := Exponential{Initial: 100 * time.Millisecond, Max: time.Second, Multiplier: 2}
+
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{10, time.Second},
+	}
+
+	for _, test := range tests {
+		if result := policy.Next(test.attempt, 0); result != test.expected {
+			t.Errorf("Expected Next(%d) = %v, got %v", test.attempt, test.expected, result)
+		}
+	}
+}
+
+func TestExponential_Jitter(t *testing.T) {
+	policy := Exponential{Initial: 100 * time.Millisecond, Multiplier: 1, Jitter: 0.5}
+
+	for i := 0; i < 100; i++ {
+		result := policy.Next(1, 0)
+		if result < 50*time.Millisecond || result > 150*time.Millisecond {
+			t.Fatalf("Expected delay within jitter bounds, got %v", result)
+		}
+	}
+}
+
+func TestDecorrelatedJitter_Next(t *testing.T) {
+	policy := DecorrelatedJitter{Base: 10 * time.Millisecond, Max: 100 * time.Millisecond}
+
+	var prev time.Duration
+	for attempt := 1; attempt <= 100; attempt++ {
+		result := policy.Next(attempt, prev)
+		upper := 3 * max(prev, policy.Base)
+		if result < policy.Base || result > policy.Max || result > upper {
+			t.Fatalf("Expected delay in [%v, min(%v, %v)], got %v", policy.Base, upper, policy.Max, result)
+		}
+		prev = result
+	}
+}
+
+func TestIsNetworkError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain error", errors.New("boom"), false},
+		{"canceled", context.Canceled, false},
+		{"connection refused", fmt.Errorf("dial: %w", syscall.ECONNREFUSED), true},
+		{"connection reset", &net.OpError{Op: "read", Err: syscall.ECONNRESET}, true},
+		{"dns not found", &net.DNSError{Name: "db", IsNotFound: true}, true},
+	}
+
+	for _, test := range tests {
+		if result := IsNetworkError(test.err); result != test.want {
+			t.Errorf("Expected IsNetworkError(%s) = %v, got %v", test.name, test.want, result)
+		}
+	}
+}
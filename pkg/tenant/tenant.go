@@ -0,0 +1,116 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"regexp"
+	"strings"
+)
+
+// Default - tenant of requests without one.
+const Default = "default"
+
+var (
+	// ErrMissing - request has no tenant while it is required.
+	ErrMissing = errors.New("tenant is required")
+	// ErrInvalidID - tenant id is not a lowercase identifier.
+	ErrInvalidID = errors.New("invalid tenant id")
+	// ErrInvalidToken - bearer token is missing while tokens are enabled, is malformed, its signature is wrong,
+	// it is expired or has no tenant claim.
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrConflict - tenant of header differs from tenant of token.
+	ErrConflict = errors.New("tenant of header differs from tenant of token")
+)
+
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// ValidID - checking that id consists of lowercase letters, digits, hyphens and underscores,
+// starts with a letter or a digit and is not longer than 63 characters.
+func ValidID(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+type ctxKey struct{}
+
+// WithID - ctx carrying tenant id, repository queries and email policy are scoped by it.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext - tenant id carried by ctx, Default if there is none.
+func FromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(ctxKey{}).(string); ok && id != "" {
+		return id
+	}
+	return Default
+}
+
+// Resolver - rules of taking tenant of request from header and claim of bearer token.
+type Resolver struct {
+	// Header - name of header with tenant id, empty disables it.
+	Header string
+	// Claim - claim of bearer token with tenant id.
+	Claim string
+	// Key - HMAC key of bearer tokens, empty disables them and Authorization is ignored.
+	// With key every request must have valid token, header alone is not trusted.
+	Key []byte
+	// Required - rejecting requests without tenant instead of serving them as Default.
+	Required bool
+}
+
+// Resolve - tenant id of request with the given header value and Authorization.
+// If tokens are enabled, tenant is taken from the token and header may only repeat it.
+func (r Resolver) Resolve(header, authorization string) (string, error) {
+	id := ""
+	if r.Header != "" {
+		id = strings.TrimSpace(header)
+	}
+
+	if len(r.Key) > 0 {
+		if authorization == "" {
+			return "", fmt.Errorf("%w: bearer token is required", ErrInvalidToken)
+		}
+		claimed, err := r.claim(authorization)
+		if err != nil {
+			return "", err
+		}
+		if id != "" && id != claimed {
+			return "", ErrConflict
+		}
+		id = claimed
+	}
+
+	switch {
+	case id == "" && r.Required:
+		return "", ErrMissing
+	case id == "":
+		return Default, nil
+	case !ValidID(id):
+		return "", fmt.Errorf("%w %q", ErrInvalidID, id)
+	}
+	return id, nil
+}
+
+// claim - tenant claim of HMAC signed bearer token, expiration and not before times are checked if set.
+func (r Resolver) claim(authorization string) (string, error) {
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("%w: bearer token is expected", ErrInvalidToken)
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(strings.TrimSpace(token), claims, func(*jwt.Token) (any, error) {
+		return r.Key, nil
+	}, jwt.WithValidMethods([]string{"HS256", "HS384", "HS512"}))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	id, _ := claims[r.Claim].(string)
+	if id == "" {
+		return "", fmt.Errorf("%w: claim %s is not set", ErrInvalidToken, r.Claim)
+	}
+	return id, nil
+}
@@ -0,0 +1,101 @@
+// Package thumbnail - checking uploaded images and making square PNG thumbnails of them.
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // registering webp decoder
+	"image"
+	_ "image/gif"  // registering gif decoder
+	_ "image/jpeg" // registering jpeg decoder
+	"image/png"
+	"net/http"
+)
+
+// ContentType - media type of thumbnails.
+const ContentType = "image/png"
+
+var (
+	// ErrUnsupportedFormat - sniffed media type of image is not one of MediaTypes.
+	ErrUnsupportedFormat = errors.New("unsupported image format, allowed are jpeg, png, gif and webp")
+	// ErrInvalidImage - image can not be decoded.
+	ErrInvalidImage = errors.New("invalid image")
+	// ErrTooManyPixels - image is larger than the pixel limit.
+	ErrTooManyPixels = errors.New("image has too many pixels")
+)
+
+// MediaTypes - media types of accepted images.
+var MediaTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+// Sniff - media type of image detected by its content, ErrUnsupportedFormat if it is not one of MediaTypes.
+// Declared type of upload is not trusted.
+func Sniff(data []byte) (string, error) {
+	mediaType := http.DetectContentType(data)
+	for _, allowed := range MediaTypes {
+		if mediaType == allowed {
+			return mediaType, nil
+		}
+	}
+	return "", fmt.Errorf("%w: got %s", ErrUnsupportedFormat, mediaType)
+}
+
+// Check - checking that image is of accepted type and has at most maxPixels pixels.
+// Only header of image is decoded, so it is cheap to call before Make.
+func Check(data []byte, maxPixels int) error {
+	if _, err := Sniff(data); err != nil {
+		return err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return fmt.Errorf("%w: empty image", ErrInvalidImage)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > int64(maxPixels) {
+		return fmt.Errorf("%w: %dx%d is more than %d", ErrTooManyPixels, cfg.Width, cfg.Height, maxPixels)
+	}
+	return nil
+}
+
+// Make - PNG thumbnails of image by edge, center of image is cropped to a square
+// which is scaled to every edge. Orientation from EXIF is not applied.
+func Make(data []byte, edges ...int) (map[int][]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	square := centerSquare(src.Bounds())
+	if square.Empty() {
+		return nil, fmt.Errorf("%w: empty image", ErrInvalidImage)
+	}
+
+	thumbnails := make(map[int][]byte, len(edges))
+	for _, edge := range edges {
+		if edge <= 0 {
+			return nil, fmt.Errorf("thumbnail: invalid edge %d", edge)
+		}
+
+		dst := image.NewNRGBA(image.Rect(0, 0, edge, edge))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, square, draw.Src, nil)
+
+		var buf bytes.Buffer
+		if err = png.Encode(&buf, dst); err != nil {
+			return nil, fmt.Errorf("thumbnail: %w", err)
+		}
+		thumbnails[edge] = buf.Bytes()
+	}
+	return thumbnails, nil
+}
+
+// centerSquare - the largest square in the center of bounds.
+func centerSquare(bounds image.Rectangle) image.Rectangle {
+	edge := min(bounds.Dx(), bounds.Dy())
+	x := bounds.Min.X + (bounds.Dx()-edge)/2
+	y := bounds.Min.Y + (bounds.Dy()-edge)/2
+	return image.Rect(x, y, x+edge, y+edge)
+}
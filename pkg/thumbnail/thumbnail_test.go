@@ -0,0 +1,107 @@
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encode(t *testing.T, img image.Image, format string) []byte {
+	t.Helper()
+	var (
+		buf bytes.Buffer
+		err error
+	)
+	switch format {
+	case "png":
+		err = png.Encode(&buf, img)
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, nil)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// wide - image with red left, green center and blue right thirds.
+func wide(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		c := color.RGBA{G: 255, A: 255}
+		switch {
+		case x < width/3:
+			c = color.RGBA{R: 255, A: 255}
+		case x >= width*2/3:
+			c = color.RGBA{B: 255, A: 255}
+		}
+		for y := 0; y < height; y++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestCheck(t *testing.T) {
+	img := wide(30, 10)
+	tests := []struct {
+		name      string
+		data      []byte
+		maxPixels int
+		want      error
+	}{
+		{"png", encode(t, img, "png"), 300, nil},
+		{"jpeg", encode(t, img, "jpeg"), 300, nil},
+		{"gif", encode(t, img, "gif"), 300, nil},
+		{"too many pixels", encode(t, img, "png"), 299, ErrTooManyPixels},
+		{"text", []byte("hello, world"), 300, ErrUnsupportedFormat},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), 300, ErrUnsupportedFormat},
+		{"truncated png", encode(t, img, "png")[:20], 300, ErrInvalidImage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Check(tt.data, tt.maxPixels); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMake(t *testing.T) {
+	thumbnails, err := Make(encode(t, wide(300, 100), "png"), 16, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(thumbnails) != 2 {
+		t.Fatalf("got %d thumbnails, want 2", len(thumbnails))
+	}
+
+	for _, edge := range []int{16, 64} {
+		img, err := png.Decode(bytes.NewReader(thumbnails[edge]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := img.Bounds(); b.Dx() != edge || b.Dy() != edge {
+			t.Errorf("edge %d: got %v", edge, b)
+		}
+
+		// center third of wide image is cropped, so corners are green
+		for _, p := range []image.Point{{0, 0}, {edge - 1, edge - 1}} {
+			r, g, b, _ := img.At(p.X, p.Y).RGBA()
+			if r > 0x1000 || b > 0x1000 || g < 0xf000 {
+				t.Errorf("edge %d: pixel %v is not green: %d %d %d", edge, p, r, g, b)
+			}
+		}
+	}
+
+	if _, err = Make([]byte("not an image"), 16); !errors.Is(err, ErrInvalidImage) {
+		t.Errorf("got %v, want ErrInvalidImage", err)
+	}
+}
@@ -0,0 +1,180 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/net/idna"
+	"net"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// Limits of RFC 5321 in octets, domain is measured in ASCII form.
+const (
+	maxEmailLen  = 254
+	maxLocalLen  = 64
+	maxDomainLen = 253
+)
+
+var (
+	// ErrInvalidEmailSyntax - email does not follow syntax rules of ParseEmail.
+	ErrInvalidEmailSyntax = errors.New("invalid email syntax")
+	// ErrEmailDomainRejected - domain of email can not receive mail or is not accepted.
+	ErrEmailDomainRejected = errors.New("email domain is rejected")
+)
+
+var (
+	// atext of RFC 5322, non-ASCII letters and digits are allowed as in RFC 6531
+	localAtomRegex = regexp.MustCompile("^[a-zA-Z0-9!#$%&'*+/=?^_`{|}~\\-\\p{L}\\p{M}\\p{N}]+$")
+	labelRegex     = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?$`)
+)
+
+// ParseEmail - splitting email into local part and domain in ASCII (punycode) form.
+// Practical subset of RFC 5321/5322 is accepted: dot-atom local part without quoting and comments,
+// domain name of at least two labels with IDNA, no address literals, upper case is allowed.
+func ParseEmail(email string) (local, domain string, err error) {
+	at := strings.LastIndexByte(email, '@')
+	if at < 1 || at == len(email)-1 {
+		return "", "", ErrInvalidEmailSyntax
+	}
+	local, domain = email[:at], email[at+1:]
+
+	if len(local) > maxLocalLen {
+		return "", "", fmt.Errorf("%w: local part is longer than %d octets", ErrInvalidEmailSyntax, maxLocalLen)
+	}
+	for _, atom := range strings.Split(local, ".") {
+		if !localAtomRegex.MatchString(atom) {
+			return "", "", fmt.Errorf("%w: invalid local part", ErrInvalidEmailSyntax)
+		}
+	}
+
+	if domain, err = asciiDomain(domain); err != nil {
+		return "", "", err
+	}
+	if len(local)+1+len(domain) > maxEmailLen {
+		return "", "", fmt.Errorf("%w: email is longer than %d octets", ErrInvalidEmailSyntax, maxEmailLen)
+	}
+	return local, domain, nil
+}
+
+// asciiDomain - checked domain name converted by IDNA to lower case ASCII.
+func asciiDomain(domain string) (string, error) {
+	ascii, err := idna.Lookup.ToASCII(domain)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid domain: %v", ErrInvalidEmailSyntax, err)
+	}
+	ascii = strings.ToLower(ascii)
+	if len(ascii) > maxDomainLen {
+		return "", fmt.Errorf("%w: domain is longer than %d octets", ErrInvalidEmailSyntax, maxDomainLen)
+	}
+
+	labels := strings.Split(ascii, ".")
+	if len(labels) < 2 {
+		return "", fmt.Errorf("%w: domain must have at least two labels", ErrInvalidEmailSyntax)
+	}
+	for _, label := range labels {
+		if !labelRegex.MatchString(label) {
+			return "", fmt.Errorf("%w: invalid domain label %q", ErrInvalidEmailSyntax, label)
+		}
+	}
+	if strings.IndexFunc(labels[len(labels)-1], unicode.IsLetter) < 0 {
+		return "", fmt.Errorf("%w: top level domain must contain a letter", ErrInvalidEmailSyntax)
+	}
+	return ascii, nil
+}
+
+// DomainChecker - check of email domain beyond syntax, domain is given in ASCII form.
+// Errors wrapping ErrEmailDomainRejected reject the email, other errors mean the check itself failed.
+type DomainChecker interface {
+	CheckDomain(ctx context.Context, domain string) error
+}
+
+// EmailValidator - checking syntax of emails and their domains with checkers, which can be replaced while serving.
+type EmailValidator struct {
+	checkers atomic.Pointer[[]DomainChecker]
+}
+
+// NewEmailValidator - creating validator with checkers applied in order.
+func NewEmailValidator(checkers ...DomainChecker) *EmailValidator {
+	v := &EmailValidator{}
+	v.SetCheckers(checkers...)
+	return v
+}
+
+// SetCheckers - applying new checkers to subsequent validations.
+func (v *EmailValidator) SetCheckers(checkers ...DomainChecker) {
+	v.checkers.Store(&checkers)
+}
+
+// Validate - checking syntax of email and its domain, nil validator checks syntax only.
+func (v *EmailValidator) Validate(ctx context.Context, email string) error {
+	_, domain, err := ParseEmail(email)
+	if err != nil || v == nil {
+		return err
+	}
+
+	for _, checker := range *v.checkers.Load() {
+		if err = checker.CheckDomain(ctx, domain); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MXResolver - looking up mail exchangers of domain, *net.Resolver satisfies it.
+type MXResolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+}
+
+// MXChecker - rejecting domains without mail exchangers or with null MX of RFC 7505.
+// Implicit MX of RFC 5321 is not considered, domain must publish MX records.
+type MXChecker struct {
+	Resolver MXResolver
+}
+
+func (c MXChecker) CheckDomain(ctx context.Context, domain string) error {
+	records, err := c.Resolver.LookupMX(ctx, domain)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return fmt.Errorf("%w: %s has no MX records", ErrEmailDomainRejected, domain)
+	}
+	if err != nil {
+		return fmt.Errorf("lookup MX of %s: %w", domain, err)
+	}
+
+	if len(records) == 0 || len(records) == 1 && strings.Trim(records[0].Host, ".") == "" {
+		return fmt.Errorf("%w: %s does not accept mail", ErrEmailDomainRejected, domain)
+	}
+	return nil
+}
+
+// DenyList - rejecting listed domains and their subdomains.
+type DenyList struct {
+	domains map[string]struct{}
+}
+
+// NewDenyList - creating deny list of domains in any case, IDN domains may be given in Unicode.
+func NewDenyList(domains ...string) (DenyList, error) {
+	list := DenyList{domains: make(map[string]struct{}, len(domains))}
+	for _, domain := range domains {
+		ascii, err := idna.Lookup.ToASCII(strings.TrimSpace(domain))
+		if err != nil || ascii == "" {
+			return DenyList{}, fmt.Errorf("invalid domain %q", domain)
+		}
+		list.domains[strings.ToLower(ascii)] = struct{}{}
+	}
+	return list, nil
+}
+
+func (l DenyList) CheckDomain(_ context.Context, domain string) error {
+	for d := domain; d != ""; {
+		if _, ok := l.domains[d]; ok {
+			return fmt.Errorf("%w: %s is denied", ErrEmailDomainRejected, domain)
+		}
+		_, d, _ = strings.Cut(d, ".")
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+type fakeResolver map[string][]*net.MX
+
+func (r fakeResolver) LookupMX(_ context.Context, name string) ([]*net.MX, error) {
+	if name == "timeout.example" {
+		return nil, &net.DNSError{Err: "i/o timeout", Name: name, IsTimeout: true}
+	}
+	records, ok := r[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func TestEmailValidator(t *testing.T) {
+	resolver := fakeResolver{
+		"example.com":           {{Host: "mx.example.com.", Pref: 10}},
+		"xn--e1afmkfd.xn--p1ai": {{Host: "mx.xn--e1afmkfd.xn--p1ai.", Pref: 10}},
+		"null.example":          {{Host: ".", Pref: 0}},
+		"spam.example":          {{Host: "mx.spam.example.", Pref: 10}},
+	}
+	denyList, err := NewDenyList("spam.example", "Temp.Mail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewEmailValidator(denyList, MXChecker{Resolver: resolver})
+
+	tests := []struct {
+		email    string
+		expected error
+	}{
+		{"user@example.com", nil},
+		{"user@пример.рф", nil},
+		{"user@", ErrInvalidEmailSyntax},
+		{"user@missing.example", ErrEmailDomainRejected},
+		{"user@null.example", ErrEmailDomainRejected},
+		{"user@spam.example", ErrEmailDomainRejected},
+		{"user@box.temp.mail", ErrEmailDomainRejected},
+	}
+
+	for _, test := range tests {
+		if err := v.Validate(context.Background(), test.email); !errors.Is(err, test.expected) {
+			t.Errorf("Expected Validate(%q) = %v, got %v", test.email, test.expected, err)
+		}
+	}
+
+	// failed lookup is not a rejection
+	err = v.Validate(context.Background(), "user@timeout.example")
+	if err == nil || errors.Is(err, ErrEmailDomainRejected) {
+		t.Errorf("Expected lookup error, got %v", err)
+	}
+
+	// checkers are replaced, nil validator checks syntax only
+	v.SetCheckers()
+	if err = v.Validate(context.Background(), "user@missing.example"); err != nil {
+		t.Errorf("Expected no domain checks, got %v", err)
+	}
+	var none *EmailValidator
+	if err = none.Validate(context.Background(), "user@missing.example"); err != nil {
+		t.Errorf("Expected no domain checks, got %v", err)
+	}
+}
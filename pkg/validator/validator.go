@@ -1,14 +1,54 @@
 package validator
 
 import (
-	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
-// ValidEmail - validates email.
+const maxNameLen = 100
+
+// ValidEmail - validating email syntax, see ParseEmail.
 func ValidEmail(email string) bool {
-	emailRegex := regexp.MustCompile(`^[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,4}$`)
-	return emailRegex.MatchString(email)
+	_, _, err := ParseEmail(email)
+	return err == nil
+}
+
+// ValidName - validating part of a person name in any script: letters with combining marks,
+// single hyphens, apostrophes or spaces between them, e.g. "Jean-Luc", "O'Neil", "d’Artagnan", "Мария".
+func ValidName(name string) bool {
+	if name == "" || utf8.RuneCountInString(name) > maxNameLen {
+		return false
+	}
+
+	// previous rune: 0 - start, 'l' - letter or mark, 's' - separator
+	prev := byte(0)
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r):
+			prev = 'l'
+		case unicode.Is(unicode.M, r):
+			if prev != 'l' {
+				return false
+			}
+		case isNameSeparator(r):
+			if prev != 'l' {
+				return false
+			}
+			prev = 's'
+		default:
+			return false
+		}
+	}
+	return prev == 'l'
+}
+
+func isNameSeparator(r rune) bool {
+	switch r {
+	case '-', '\'', '’', ' ':
+		return true
+	}
+	return false
 }
 
 // ValidGender - validating gender. (F/M/O)
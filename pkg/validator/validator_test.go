@@ -1,6 +1,9 @@
 package validator
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 // Tests
 func TestValidEmail(t *testing.T) {
@@ -13,7 +16,23 @@ func TestValidEmail(t *testing.T) {
 		{"@example.com", false},
 		{"test@.com", false},
 		{"test@domain.co", true},
-		{"test@domain.toolongtld", false},
+		{"test@domain.company", true},
+		{"Test.User+tag@Example.COM", true},
+		{"user@пример.рф", true},
+		{"user@xn--e1afmkfd.xn--p1ai", true},
+		{"o'brien@example.com", true},
+		{"test@localhost", false},
+		{"test..user@example.com", false},
+		{".test@example.com", false},
+		{"test.@example.com", false},
+		{"test user@example.com", false},
+		{`"quoted"@example.com`, false},
+		{"test@[127.0.0.1]", false},
+		{"test@127.0.0.1", false},
+		{"test@-example.com", false},
+		{"test@example..com", false},
+		{strings.Repeat("a", 65) + "@example.com", false},
+		{"test@" + strings.Repeat("a", 64) + ".com", false},
 	}
 
 	for _, test := range tests {
@@ -24,6 +43,40 @@ func TestValidEmail(t *testing.T) {
 	}
 }
 
+func TestValidName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"John", true},
+		{"Jean-Luc", true},
+		{"O'Neil", true},
+		{"d’Artagnan", true},
+		{"Mary Ann", true},
+		{"Мария", true},
+		{"李小龍", true},
+		{"José", true},
+		{"Jose\u0301", true},
+		{"محمد", true},
+		{"", false},
+		{" John", false},
+		{"John ", false},
+		{"Jean--Luc", false},
+		{"-John", false},
+		{"John3", false},
+		{"John.", false},
+		{"\u0301John", false},
+		{strings.Repeat("a", 101), false},
+	}
+
+	for _, test := range tests {
+		result := ValidName(test.name)
+		if result != test.valid {
+			t.Errorf("Expected ValidName(%q) = %v, got %v", test.name, test.valid, result)
+		}
+	}
+}
+
 func TestValidGender(t *testing.T) {
 	tests := []struct {
 		gender string